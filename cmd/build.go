@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/serenitysz/serenity/internal/cmds/build"
+	"github.com/spf13/cobra"
+)
+
+func NewBuildCmd() *cobra.Command {
+	opts := &build.BuildOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: "Build a custom Serenity binary with compiled-in rule plugins",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return build.Run(opts)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&opts.With, "with", nil, "Plugin module to compile in, as module@version (repeatable)")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "serenity", "Path of the built binary")
+	cmd.Flags().StringVar(&opts.Version, "serenity-version", "", "Serenity version to build against (defaults to this binary's version)")
+
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(NewBuildCmd())
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"github.com/serenitysz/serenity/internal/cmds/cache"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clean the lint cache",
+}
+
+func NewCacheStatsCmd() *cobra.Command {
+	var configPath string
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show the size of the lint cache and the hit rate of the last run",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cache.Stats(configPath)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "", "Use a custom config")
+
+	return cmd
+}
+
+func NewCacheCleanCmd() *cobra.Command {
+	var configPath, olderThan string
+
+	cmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Remove cached results",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cache.Clean(configPath, olderThan)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "", "Use a custom config")
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Only remove entries no run used for this long, e.g. 72h or 30d")
+
+	return cmd
+}
+
+func init() {
+	cacheCmd.AddCommand(NewCacheStatsCmd())
+	cacheCmd.AddCommand(NewCacheCleanCmd())
+
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -19,8 +19,12 @@ func NewCheckCmd() *cobra.Command {
 	cmd.Flags().Int("max-issues", 0, "Maximum number of issues")
 	cmd.Flags().BoolVarP(&opts.Unsafe, "unsafe", "u", false, "Apply unsafe fixes (requires --write)")
 	cmd.Flags().BoolVarP(&opts.Write, "write", "w", false, "Write changes to files")
+	cmd.Flags().BoolVar(&opts.Backup, "backup", false, "Keep a backup journal of rewritten files for `serenity fix undo` (requires --write)")
 	cmd.Flags().StringVarP(&opts.ConfigPath, "config", "c", "", "Use a custom config")
 	cmd.Flags().Int64VarP(&opts.MaxFileSize, "max-file-size", "m", 0, "Maximum file size")
+	cmd.Flags().BoolVar(&opts.Stats, "stats", false, "Show the time spent in each phase and rule, and cache use")
+	cmd.Flags().StringVar(&opts.CPUProfile, "cpuprofile", "", "Write a CPU profile to this file")
+	cmd.Flags().StringVar(&opts.MemProfile, "memprofile", "", "Write a memory profile to this file")
 
 	return cmd
 }
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"github.com/serenitysz/serenity/internal/cmds/configcmd"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and check config files",
+}
+
+func NewConfigValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate [path...]",
+		Short: "Report unknown keys and invalid values in config files",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configcmd.Validate(args)
+		},
+	}
+}
+
+func NewConfigSchemaCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of config files",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configcmd.Schema(output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the schema to a file instead of stdout")
+
+	return cmd
+}
+
+func NewConfigPrintCmd() *cobra.Command {
+	opts := configcmd.PrintOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "print",
+		Short: "Print the effective config, where each value comes from, and the active rules",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("max-issues") {
+				n, _ := cmd.Flags().GetInt("max-issues")
+				opts.MaxIssues = &n
+			}
+
+			if cmd.Flags().Changed("max-file-size") {
+				n, _ := cmd.Flags().GetInt64("max-file-size")
+				opts.MaxFileSize = &n
+			}
+
+			return configcmd.Print(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Format, "format", "f", "json", "Output format: json, yaml or toml")
+	cmd.Flags().StringVar(&opts.For, "for", "", "Resolve the config of this file or directory")
+	cmd.Flags().StringVarP(&opts.ConfigPath, "config", "c", "", "Use a custom config")
+	cmd.Flags().Int("max-issues", 0, "Maximum number of issues, as given to check")
+	cmd.Flags().Int64P("max-file-size", "m", 0, "Maximum file size, as given to check")
+
+	return cmd
+}
+
+func NewConfigConvertCmd() *cobra.Command {
+	var (
+		to    string
+		force bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "convert <path>",
+		Short: "Convert a config file to JSON, YAML or TOML",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return configcmd.Convert(args[0], to, force)
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "Target format: json, yaml, yml or toml")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite the target file if it exists")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func init() {
+	configCmd.AddCommand(NewConfigValidateCmd())
+	configCmd.AddCommand(NewConfigSchemaCmd())
+	configCmd.AddCommand(NewConfigPrintCmd())
+	configCmd.AddCommand(NewConfigConvertCmd())
+
+	rootCmd.AddCommand(configCmd)
+}
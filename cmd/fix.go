@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/serenitysz/serenity/internal/cmds/fix"
+	"github.com/spf13/cobra"
+)
+
+var fixCmd = &cobra.Command{
+	Use:   "fix",
+	Short: "Manage changes applied by --write",
+}
+
+func NewFixUndoCmd() *cobra.Command {
+	var configPath string
+
+	cmd := &cobra.Command{
+		Use:   "undo",
+		Short: "Restore the files rewritten by the last `check --write --backup` run",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fix.Undo(configPath)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "", "Use a custom config")
+
+	return cmd
+}
+
+func init() {
+	fixCmd.AddCommand(NewFixUndoCmd())
+
+	rootCmd.AddCommand(fixCmd)
+}
@@ -12,13 +12,22 @@ import (
 )
 
 var (
-	interactive bool
+	interactive  bool
+	fromGolangci string
 )
 
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Create a new Serenity project by creating a serenity config file",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if fromGolangci != "" {
+			if interactive {
+				return exception.CommandError("--from-golangci cannot be combined with --interactive")
+			}
+
+			return createFromGolangci(fromGolangci)
+		}
+
 		if interactive {
 			noColor, err := cmd.Flags().GetBool("no-color")
 
@@ -35,6 +44,7 @@ var initCmd = &cobra.Command{
 
 func init() {
 	initCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Use an interactive mode to init Serenity project")
+	initCmd.Flags().StringVar(&fromGolangci, "from-golangci", "", "Translate an existing golangci-lint config")
 
 	rootCmd.AddCommand(initCmd)
 }
@@ -70,6 +80,40 @@ func createSerenity() error {
 	return createConfig(path, cfg)
 }
 
+func createFromGolangci(source string) error {
+	path := "serenity.json"
+
+	ok, err := config.Exists(path)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		return exception.CommandError("config file %q already exists", path)
+	}
+
+	imported, err := config.ImportGolangci(source)
+	if err != nil {
+		return err
+	}
+
+	if err := createConfig(path, imported.Config); err != nil {
+		return err
+	}
+
+	for _, line := range imported.Translated {
+		render.Infof("%s", line)
+	}
+
+	for _, line := range imported.Skipped {
+		render.Warnf("%s", line)
+	}
+
+	render.Infof("imported %s: %d translated, %d skipped", source, len(imported.Translated), len(imported.Skipped))
+
+	return nil
+}
+
 func createSerenityInteractive(noColor bool) error {
 	format, err := prompts.Input(
 		"Which config format do you want to use? (JSON, YAML, TOML)", "JSON", noColor)
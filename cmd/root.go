@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/serenitysz/serenity/internal/exception"
 	"github.com/serenitysz/serenity/internal/render"
@@ -21,7 +23,13 @@ var rootCmd = &cobra.Command{
 			return exception.InternalError("could not read --no-color: %w", err)
 		}
 
+		verbose, err := cmd.Flags().GetCount("verbose")
+		if err != nil {
+			return exception.InternalError("could not read --verbose: %w", err)
+		}
+
 		render.SetNoColor(noColor)
+		render.SetVerbosity(verbose)
 		return nil
 	},
 }
@@ -35,8 +43,45 @@ func Exec() {
 	os.Exit(exception.ExitCode(err))
 }
 
+// verbosity is the value of --verbose. It counts like a count flag, so -vv
+// is more verbose than -v, and still takes the true and false of the boolean
+// flag it used to be.
+type verbosity int
+
+func (v *verbosity) Set(value string) error {
+	switch value {
+	case "+1":
+		*v++
+	case "true":
+		*v = max(*v, 1)
+	case "false":
+		*v = 0
+	default:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%q is not true, false or a level", value)
+		}
+
+		*v = verbosity(n)
+	}
+
+	return nil
+}
+
+func (v *verbosity) String() string {
+	return strconv.Itoa(int(*v))
+}
+
+// Type is that of a count flag, so GetCount reads it.
+func (v *verbosity) Type() string {
+	return "count"
+}
+
 func init() {
 	rootCmd.PersistentFlags().Bool("no-color", false, "Remove color from the output")
-	rootCmd.PersistentFlags().Bool("verbose", false, "Print additional diagnostics and processed files")
+
+	verbose := rootCmd.PersistentFlags().VarPF(new(verbosity), "verbose", "v", "Print additional diagnostics and processed files; -vv also traces each file. --verbose=true and --verbose=false still work, and --verbose=N sets the level")
+	verbose.NoOptDefVal = "+1"
+
 	rootCmd.PersistentFlags().String("config", "", "Path to configuration file (Auto-discovered if omitted)")
 }
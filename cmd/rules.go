@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"github.com/serenitysz/serenity/internal/cmds/catalog"
+	"github.com/spf13/cobra"
+)
+
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Inspect the built-in rules",
+}
+
+func NewRulesListCmd() *cobra.Command {
+	var group string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List every built-in rule with its group, fix support and description",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return catalog.List(group)
+		},
+	}
+
+	cmd.Flags().StringVarP(&group, "group", "g", "", "Only list rules in this group")
+
+	return cmd
+}
+
+func NewRulesDocsCmd() *cobra.Command {
+	var out string
+
+	cmd := &cobra.Command{
+		Use:   "docs",
+		Short: "Generate the Markdown rules reference",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return catalog.Docs(out)
+		},
+	}
+
+	cmd.Flags().StringVarP(&out, "output", "o", "", "Write the reference to a file instead of stdout")
+
+	return cmd
+}
+
+func init() {
+	rulesCmd.AddCommand(NewRulesListCmd())
+	rulesCmd.AddCommand(NewRulesDocsCmd())
+
+	rootCmd.AddCommand(rulesCmd)
+}
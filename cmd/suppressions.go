@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/serenitysz/serenity/internal/cmds/suppressions"
+	"github.com/spf13/cobra"
+)
+
+var suppressionsCmd = &cobra.Command{
+	Use:   "suppressions",
+	Short: "Inspect suppression comments",
+}
+
+func NewSuppressionsListCmd() *cobra.Command {
+	var configPath string
+
+	cmd := &cobra.Command{
+		Use:   "list [path...]",
+		Short: "List every suppression with its rule, reason, location and age",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return suppressions.List(args, configPath)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "", "Use a custom config")
+
+	return cmd
+}
+
+func init() {
+	suppressionsCmd.AddCommand(NewSuppressionsListCmd())
+
+	rootCmd.AddCommand(suppressionsCmd)
+}
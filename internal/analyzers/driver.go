@@ -0,0 +1,381 @@
+package analyzers
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"os"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/serenitysz/serenity/internal/render"
+	"github.com/serenitysz/serenity/internal/rules"
+	"golang.org/x/tools/go/analysis"
+)
+
+// Driver runs go/analysis analyzers over the packages the linter parses.
+// Packages are type-checked from source. Facts go through the linter's fact
+// store, so those a package exports reach the packages importing it.
+type Driver struct {
+	roots      []*analysis.Analyzer
+	severities map[string]rules.Severity
+
+	// The source importer caches imported packages and is not safe for
+	// concurrent use, so type checking is serialized.
+	mu       sync.Mutex
+	fset     *token.FileSet
+	importer types.Importer
+	sizes    types.Sizes
+}
+
+// Finding is a diagnostic converted into an issue, along with the text
+// edits of its first suggested fix.
+type Finding struct {
+	Issue rules.Issue
+	Edits []analysis.TextEdit
+}
+
+func NewDriver(cfg map[string]*rules.LinterBaseRule) *Driver {
+	if len(cfg) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(cfg))
+	for name := range cfg {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	d := &Driver{
+		severities: make(map[string]rules.Severity, len(cfg)),
+		fset:       token.NewFileSet(),
+		sizes:      types.SizesFor("gc", runtime.GOARCH),
+	}
+	d.importer = importer.ForCompiler(d.fset, "source", nil)
+
+	for _, name := range names {
+		a, ok := Lookup(name)
+		if !ok {
+			render.Warnf("skipping unknown analyzer %q", name)
+			continue
+		}
+
+		sev := ""
+		if base := cfg[name]; base != nil {
+			sev = base.Severity
+		}
+
+		d.roots = append(d.roots, a)
+		d.severities[name] = rules.ParseSeverity(sev)
+	}
+
+	if len(d.roots) == 0 {
+		return nil
+	}
+
+	return d
+}
+
+// Names returns the analyzers d runs, sorted.
+func (d *Driver) Names() []string {
+	if d == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(d.roots))
+	for _, a := range d.roots {
+		names = append(names, a.Name)
+	}
+
+	return names
+}
+
+// CanFix reports whether an enabled analyzer can suggest fixes, making --write
+// runs with d mutating.
+func (d *Driver) CanFix() bool {
+	if d == nil {
+		return false
+	}
+
+	for _, a := range d.roots {
+		if fixers[a.Name] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Run analyzes files, which must belong to the directory of the package
+// imported as path, and returns the findings of the enabled analyzers. Files
+// are grouped by package clause so external test packages are checked on
+// their own. Facts are imported from and exported to facts, which may be nil.
+func (d *Driver) Run(fset *token.FileSet, path string, files []*ast.File, facts *rules.Facts) []Finding {
+	if d == nil || len(files) == 0 {
+		return nil
+	}
+
+	groups := make(map[string][]*ast.File, 2)
+	order := make([]string, 0, 2)
+
+	for _, file := range files {
+		name := file.Name.Name
+		if _, ok := groups[name]; !ok {
+			order = append(order, name)
+		}
+
+		groups[name] = append(groups[name], file)
+	}
+
+	var findings []Finding
+
+	for _, name := range order {
+		pkgPath := path
+		if len(order) > 1 && strings.HasSuffix(name, "_test") {
+			pkgPath += "_test"
+		}
+
+		findings = append(findings, d.runPackage(fset, pkgPath, groups[name], facts)...)
+	}
+
+	return findings
+}
+
+func (d *Driver) runPackage(fset *token.FileSet, path string, files []*ast.File, facts *rules.Facts) []Finding {
+	info := &types.Info{
+		Types:        make(map[ast.Expr]types.TypeAndValue),
+		Instances:    make(map[*ast.Ident]types.Instance),
+		Defs:         make(map[*ast.Ident]types.Object),
+		Uses:         make(map[*ast.Ident]types.Object),
+		Implicits:    make(map[ast.Node]types.Object),
+		Selections:   make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:       make(map[ast.Node]*types.Scope),
+		FileVersions: make(map[*ast.File]string),
+	}
+
+	var typeErrors []types.Error
+
+	conf := types.Config{
+		Importer: d.importer,
+		Sizes:    d.sizes,
+		Error: func(err error) {
+			if terr, ok := err.(types.Error); ok {
+				typeErrors = append(typeErrors, terr)
+			}
+		},
+	}
+
+	d.mu.Lock()
+	pkg, _ := conf.Check(path, fset, files, info)
+	d.mu.Unlock()
+
+	run := &packageRun{
+		path:       path,
+		fset:       fset,
+		files:      files,
+		pkg:        pkg,
+		info:       info,
+		sizes:      d.sizes,
+		typeErrors: typeErrors,
+		store:      facts,
+		results:    make(map[*analysis.Analyzer]any, 8),
+		reported:   make(map[*analysis.Analyzer][]analysis.Diagnostic, len(d.roots)),
+		done:       make(map[*analysis.Analyzer]bool, 8),
+		failed:     make(map[*analysis.Analyzer]bool, 2),
+		facts:      make(map[factKey]analysis.Fact, 8),
+	}
+
+	var findings []Finding
+
+	for _, root := range d.roots {
+		run.exec(root)
+
+		for _, diag := range run.reported[root] {
+			findings = append(findings, d.convert(fset, root, diag))
+		}
+	}
+
+	run.exportFacts()
+
+	return findings
+}
+
+func (d *Driver) convert(fset *token.FileSet, a *analysis.Analyzer, diag analysis.Diagnostic) Finding {
+	pos := fset.Position(diag.Pos)
+
+	finding := Finding{
+		Issue: rules.Issue{
+			Path:     pos.Filename,
+			Line:     uint32(pos.Line),
+			Column:   uint32(pos.Column),
+			ID:       rules.AnalyzerDiagnosticID,
+			Severity: d.severities[a.Name],
+			ArgStr1:  rules.PackContext2(a.Name, diag.Message),
+		},
+	}
+
+	if len(diag.SuggestedFixes) > 0 && len(diag.SuggestedFixes[0].TextEdits) > 0 {
+		finding.Edits = diag.SuggestedFixes[0].TextEdits
+		finding.Issue.Flags |= rules.IssueFixableFlag
+	}
+
+	return finding
+}
+
+type factKey struct {
+	obj types.Object
+	pkg *types.Package
+	typ reflect.Type
+}
+
+type packageRun struct {
+	path       string
+	fset       *token.FileSet
+	files      []*ast.File
+	pkg        *types.Package
+	info       *types.Info
+	sizes      types.Sizes
+	typeErrors []types.Error
+
+	// store holds the facts of the packages imported; facts holds the ones
+	// exported by this package until they are added to it.
+	store *rules.Facts
+	facts map[factKey]analysis.Fact
+
+	results  map[*analysis.Analyzer]any
+	reported map[*analysis.Analyzer][]analysis.Diagnostic
+	done     map[*analysis.Analyzer]bool
+	failed   map[*analysis.Analyzer]bool
+
+	// Set once the analyzers skipped for type errors were reported.
+	warned bool
+}
+
+// exec runs a and its requirements, each once per package however many
+// analyzers require it, and keeps the diagnostics of a in reported.
+func (r *packageRun) exec(a *analysis.Analyzer) {
+	if r.done[a] {
+		return
+	}
+
+	r.done[a] = true
+
+	for _, req := range a.Requires {
+		r.exec(req)
+
+		if r.failed[req] {
+			r.failed[a] = true
+			return
+		}
+	}
+
+	if r.pkg == nil || (len(r.typeErrors) > 0 && !a.RunDespiteErrors) {
+		r.failed[a] = true
+		r.warnTypeErrors()
+		return
+	}
+
+	var diagnostics []analysis.Diagnostic
+
+	pass := &analysis.Pass{
+		Analyzer:   a,
+		Fset:       r.fset,
+		Files:      r.files,
+		Pkg:        r.pkg,
+		TypesInfo:  r.info,
+		TypesSizes: r.sizes,
+		TypeErrors: r.typeErrors,
+		ResultOf:   make(map[*analysis.Analyzer]any, len(a.Requires)),
+		ReadFile:   os.ReadFile,
+		Report: func(diag analysis.Diagnostic) {
+			diagnostics = append(diagnostics, diag)
+		},
+		ImportObjectFact: func(obj types.Object, fact analysis.Fact) bool {
+			if obj.Pkg() != r.pkg {
+				return r.importStored(obj.Pkg(), obj, fact)
+			}
+
+			return r.importFact(factKey{obj: obj, typ: reflect.TypeOf(fact)}, fact)
+		},
+		ImportPackageFact: func(pkg *types.Package, fact analysis.Fact) bool {
+			if pkg != r.pkg {
+				return r.importStored(pkg, nil, fact)
+			}
+
+			return r.importFact(factKey{pkg: pkg, typ: reflect.TypeOf(fact)}, fact)
+		},
+		ExportObjectFact: func(obj types.Object, fact analysis.Fact) {
+			r.facts[factKey{obj: obj, typ: reflect.TypeOf(fact)}] = fact
+		},
+		ExportPackageFact: func(fact analysis.Fact) {
+			r.facts[factKey{pkg: r.pkg, typ: reflect.TypeOf(fact)}] = fact
+		},
+		AllPackageFacts: func() []analysis.PackageFact {
+			out := r.importedPackageFacts(a.FactTypes)
+			for key, fact := range r.facts {
+				if key.pkg != nil {
+					out = append(out, analysis.PackageFact{Package: key.pkg, Fact: fact})
+				}
+			}
+			return out
+		},
+		AllObjectFacts: func() []analysis.ObjectFact {
+			out := r.importedObjectFacts(a.FactTypes)
+			for key, fact := range r.facts {
+				if key.obj != nil {
+					out = append(out, analysis.ObjectFact{Object: key.obj, Fact: fact})
+				}
+			}
+			return out
+		},
+	}
+
+	for _, req := range a.Requires {
+		pass.ResultOf[req] = r.results[req]
+	}
+
+	result, err := a.Run(pass)
+	if err != nil {
+		r.failed[a] = true
+		render.Warnf("%s  analyzer %s failed: %v", r.path, a.Name, err)
+		return
+	}
+
+	r.results[a] = result
+	r.reported[a] = diagnostics
+}
+
+// warnTypeErrors reports, once per package, that analyzers were skipped
+// because it does not type-check.
+func (r *packageRun) warnTypeErrors() {
+	if r.warned {
+		return
+	}
+
+	r.warned = true
+
+	switch len(r.typeErrors) {
+	case 0:
+		render.Warnf("%s  skipping analyzers: the package could not be type-checked", r.path)
+	case 1:
+		render.Warnf("%s  skipping analyzers on a type error: %v", r.path, r.typeErrors[0])
+	default:
+		render.Warnf("%s  skipping analyzers on %d type errors, the first: %v", r.path, len(r.typeErrors), r.typeErrors[0])
+	}
+}
+
+func (r *packageRun) importFact(key factKey, fact analysis.Fact) bool {
+	stored, ok := r.facts[key]
+	if !ok {
+		return false
+	}
+
+	reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(stored).Elem())
+
+	return true
+}
@@ -0,0 +1,58 @@
+package analyzers
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/serenitysz/serenity/internal/rules"
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestRunExecutesSharedRequirementsOnce(t *testing.T) {
+	runs := map[string]int{}
+
+	base := &analysis.Analyzer{
+		Name: "base",
+		Doc:  "reports every file",
+		Run: func(pass *analysis.Pass) (any, error) {
+			runs["base"]++
+			for _, file := range pass.Files {
+				pass.Reportf(file.Package, "base")
+			}
+			return nil, nil
+		},
+	}
+
+	dependent := &analysis.Analyzer{
+		Name:     "dependent",
+		Doc:      "requires base",
+		Requires: []*analysis.Analyzer{base},
+		Run: func(pass *analysis.Pass) (any, error) {
+			runs["dependent"]++
+			return nil, nil
+		},
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "sample.go", "package sample\n", 0)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	d := &Driver{
+		roots:      []*analysis.Analyzer{dependent, base},
+		severities: map[string]rules.Severity{},
+	}
+
+	findings := d.Run(fset, "example.com/sample", []*ast.File{file}, nil)
+
+	if runs["base"] != 1 || runs["dependent"] != 1 {
+		t.Fatalf("runs = %v, want each analyzer to run once", runs)
+	}
+
+	if len(findings) != 1 {
+		t.Fatalf("findings = %+v, want the one diagnostic of base", findings)
+	}
+}
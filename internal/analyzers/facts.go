@@ -0,0 +1,206 @@
+package analyzers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"go/types"
+	"reflect"
+	"runtime/debug"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/objectpath"
+)
+
+// Facts are kept in the linter's fact store under a rule name of their own,
+// gob encoded as go/analysis requires of them, with objects named by their
+// objectpath so the importing package can find them in its own view of the
+// dependency.
+const (
+	factPrefix = "analysis:"
+	apiFact    = factPrefix + "api"
+)
+
+// Stamp identifies the enabled analyzers and the x/tools version they come
+// from, for the cache key.
+func (d *Driver) Stamp() string {
+	if d == nil {
+		return ""
+	}
+
+	return "analyzers:" + strings.Join(d.Names(), ",") + "@" + toolsVersion()
+}
+
+func toolsVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path == "golang.org/x/tools" {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+
+			return dep.Version
+		}
+	}
+
+	return "unknown"
+}
+
+func factRule(typ reflect.Type) string {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	return factPrefix + typ.PkgPath() + "." + typ.Name()
+}
+
+// importStored decodes into fact what was exported about obj, or about pkg
+// itself when obj is nil, by the run of another package.
+func (r *packageRun) importStored(pkg *types.Package, obj types.Object, fact analysis.Fact) bool {
+	if r.store == nil || pkg == nil {
+		return false
+	}
+
+	var path objectpath.Path
+	if obj != nil {
+		var err error
+		if path, err = objectpath.For(obj); err != nil {
+			return false
+		}
+	}
+
+	var data []byte
+	if !r.store.Import(pkg.Path(), factRule(reflect.TypeOf(fact)), string(path), &data) {
+		return false
+	}
+
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(fact) == nil
+}
+
+// importedPackageFacts returns the facts of the given types exported by the
+// packages imported, directly or not.
+func (r *packageRun) importedPackageFacts(factTypes []analysis.Fact) []analysis.PackageFact {
+	var out []analysis.PackageFact
+
+	r.eachImport(func(pkg *types.Package) {
+		for _, proto := range factTypes {
+			fact := newFact(proto)
+			if r.importStored(pkg, nil, fact) {
+				out = append(out, analysis.PackageFact{Package: pkg, Fact: fact})
+			}
+		}
+	})
+
+	return out
+}
+
+// importedObjectFacts returns the facts of the given types exported about
+// objects of the packages imported, directly or not.
+func (r *packageRun) importedObjectFacts(factTypes []analysis.Fact) []analysis.ObjectFact {
+	var out []analysis.ObjectFact
+
+	r.eachImport(func(pkg *types.Package) {
+		for _, proto := range factTypes {
+			for _, path := range r.store.Objects(pkg.Path(), factRule(reflect.TypeOf(proto))) {
+				if path == "" {
+					continue
+				}
+
+				obj, err := objectpath.Object(pkg, objectpath.Path(path))
+				if err != nil {
+					continue
+				}
+
+				fact := newFact(proto)
+				if r.importStored(pkg, obj, fact) {
+					out = append(out, analysis.ObjectFact{Object: obj, Fact: fact})
+				}
+			}
+		}
+	})
+
+	return out
+}
+
+func (r *packageRun) eachImport(fn func(*types.Package)) {
+	if r.store == nil || r.pkg == nil {
+		return
+	}
+
+	seen := make(map[*types.Package]bool, 16)
+
+	var visit func(*types.Package)
+	visit = func(pkg *types.Package) {
+		for _, imp := range pkg.Imports() {
+			if !seen[imp] {
+				seen[imp] = true
+				fn(imp)
+				visit(imp)
+			}
+		}
+	}
+
+	visit(r.pkg)
+}
+
+func newFact(proto analysis.Fact) analysis.Fact {
+	return reflect.New(reflect.TypeOf(proto).Elem()).Interface().(analysis.Fact)
+}
+
+// exportFacts adds the facts the package exported to the store, along with a
+// digest of its exported API. Importers' cache entries are keyed by the
+// facts of their dependencies, so the digest makes them miss when a type
+// they were checked against changes.
+func (r *packageRun) exportFacts() {
+	if r.store == nil || r.pkg == nil {
+		return
+	}
+
+	for key, fact := range r.facts {
+		var path objectpath.Path
+		if key.obj != nil {
+			var err error
+			if path, err = objectpath.For(key.obj); err != nil {
+				continue
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(fact); err != nil {
+			continue
+		}
+
+		r.store.Export(factRule(key.typ), string(path), buf.Bytes())
+	}
+
+	r.store.Export(apiFact, "", apiDigest(r.pkg))
+}
+
+func apiDigest(pkg *types.Package) string {
+	qualifier := types.RelativeTo(pkg)
+	sum := sha256.New()
+	scope := pkg.Scope()
+
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+
+		_, _ = sum.Write([]byte(types.ObjectString(obj, qualifier) + "\n"))
+
+		if named, ok := obj.Type().(*types.Named); ok {
+			for method := range named.Methods() {
+				_, _ = sum.Write([]byte(types.ObjectString(method, qualifier) + "\n"))
+			}
+		}
+	}
+
+	return hex.EncodeToString(sum.Sum(nil))
+}
@@ -0,0 +1,102 @@
+package analyzers
+
+import (
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/appends"
+	"golang.org/x/tools/go/analysis/passes/assign"
+	"golang.org/x/tools/go/analysis/passes/atomic"
+	"golang.org/x/tools/go/analysis/passes/bools"
+	"golang.org/x/tools/go/analysis/passes/copylock"
+	"golang.org/x/tools/go/analysis/passes/defers"
+	"golang.org/x/tools/go/analysis/passes/errorsas"
+	"golang.org/x/tools/go/analysis/passes/httpresponse"
+	"golang.org/x/tools/go/analysis/passes/ifaceassert"
+	"golang.org/x/tools/go/analysis/passes/lostcancel"
+	"golang.org/x/tools/go/analysis/passes/nilfunc"
+	"golang.org/x/tools/go/analysis/passes/nilness"
+	"golang.org/x/tools/go/analysis/passes/printf"
+	"golang.org/x/tools/go/analysis/passes/shadow"
+	"golang.org/x/tools/go/analysis/passes/shift"
+	"golang.org/x/tools/go/analysis/passes/sigchanyzer"
+	"golang.org/x/tools/go/analysis/passes/sortslice"
+	"golang.org/x/tools/go/analysis/passes/stdmethods"
+	"golang.org/x/tools/go/analysis/passes/stringintconv"
+	"golang.org/x/tools/go/analysis/passes/structtag"
+	"golang.org/x/tools/go/analysis/passes/timeformat"
+	"golang.org/x/tools/go/analysis/passes/unmarshal"
+	"golang.org/x/tools/go/analysis/passes/unreachable"
+	"golang.org/x/tools/go/analysis/passes/unsafeptr"
+	"golang.org/x/tools/go/analysis/passes/unusedresult"
+	"golang.org/x/tools/go/analysis/passes/unusedwrite"
+	"golang.org/x/tools/go/analysis/passes/waitgroup"
+)
+
+var known = func() map[string]*analysis.Analyzer {
+	list := []*analysis.Analyzer{
+		appends.Analyzer,
+		assign.Analyzer,
+		atomic.Analyzer,
+		bools.Analyzer,
+		copylock.Analyzer,
+		defers.Analyzer,
+		errorsas.Analyzer,
+		httpresponse.Analyzer,
+		ifaceassert.Analyzer,
+		lostcancel.Analyzer,
+		nilfunc.Analyzer,
+		nilness.Analyzer,
+		printf.Analyzer,
+		shadow.Analyzer,
+		shift.Analyzer,
+		sigchanyzer.Analyzer,
+		sortslice.Analyzer,
+		stdmethods.Analyzer,
+		stringintconv.Analyzer,
+		structtag.Analyzer,
+		timeformat.Analyzer,
+		unmarshal.Analyzer,
+		unreachable.Analyzer,
+		unsafeptr.Analyzer,
+		unusedresult.Analyzer,
+		unusedwrite.Analyzer,
+		waitgroup.Analyzer,
+	}
+
+	out := make(map[string]*analysis.Analyzer, len(list))
+	for _, a := range list {
+		out[a.Name] = a
+	}
+
+	return out
+}()
+
+// fixers are the analyzers that can suggest fixes, per the x/tools version
+// required in go.mod.
+var fixers = map[string]bool{
+	assign.Analyzer.Name:        true,
+	printf.Analyzer.Name:        true,
+	sigchanyzer.Analyzer.Name:   true,
+	sortslice.Analyzer.Name:     true,
+	stringintconv.Analyzer.Name: true,
+	timeformat.Analyzer.Name:    true,
+	unreachable.Analyzer.Name:   true,
+}
+
+func Lookup(name string) (*analysis.Analyzer, bool) {
+	a, ok := known[name]
+	return a, ok
+}
+
+// Names returns the analyzers that can be enabled under linter.analyzers.
+func Names() []string {
+	names := make([]string, 0, len(known))
+	for name := range known {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,147 @@
+package build
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/render"
+	"github.com/serenitysz/serenity/internal/version"
+)
+
+const serenityModule = "github.com/serenitysz/serenity"
+
+type BuildOptions struct {
+	With    []string
+	Output  string
+	Version string
+}
+
+type pluginModule struct {
+	Path    string
+	Version string
+}
+
+func Run(opts *BuildOptions) error {
+	modules, err := parseWith(opts.With)
+	if err != nil {
+		return err
+	}
+
+	output, err := filepath.Abs(opts.Output)
+	if err != nil {
+		return exception.InternalError("could not resolve output path %q: %w", opts.Output, err)
+	}
+
+	dir, err := os.MkdirTemp("", "serenity-build-*")
+	if err != nil {
+		return exception.InternalError("could not create a build directory: %w", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), mainSource(modules), 0o644); err != nil {
+		return exception.InternalError("could not write the build entrypoint: %w", err)
+	}
+
+	steps := [][]string{
+		{"mod", "init", "serenity-custom"},
+		{"get", serenityModule + "@" + serenityVersion(opts.Version)},
+	}
+
+	for _, mod := range modules {
+		steps = append(steps, []string{"get", mod.Path + "@" + mod.Version})
+	}
+
+	steps = append(steps,
+		[]string{"mod", "tidy"},
+		[]string{"build", "-o", output, "."},
+	)
+
+	for _, args := range steps {
+		if err := goCommand(dir, args...); err != nil {
+			return err
+		}
+	}
+
+	render.Successf("built %s with %d %s", output, len(modules), pluralPlugins(len(modules)))
+
+	return nil
+}
+
+func parseWith(with []string) ([]pluginModule, error) {
+	if len(with) == 0 {
+		return nil, exception.CommandError("pass at least one --with module@version")
+	}
+
+	modules := make([]pluginModule, 0, len(with))
+
+	for _, spec := range with {
+		path, ver, ok := strings.Cut(spec, "@")
+		if !ok || path == "" || ver == "" {
+			return nil, exception.CommandError("invalid --with %q: expected module@version", spec)
+		}
+
+		modules = append(modules, pluginModule{Path: path, Version: ver})
+	}
+
+	return modules, nil
+}
+
+func mainSource(modules []pluginModule) []byte {
+	var b strings.Builder
+
+	b.WriteString("// Code generated by serenity build; DO NOT EDIT.\n\n")
+	b.WriteString("package main\n\n")
+	b.WriteString("import (\n")
+	b.WriteString("\t" + strconv.Quote(serenityModule+"/cmd") + "\n\n")
+
+	for _, mod := range modules {
+		b.WriteString("\t_ " + strconv.Quote(mod.Path) + "\n")
+	}
+
+	b.WriteString(")\n\n")
+	b.WriteString("func main() {\n\tcmd.Exec()\n}\n")
+
+	return []byte(b.String())
+}
+
+func serenityVersion(requested string) string {
+	if requested != "" {
+		return requested
+	}
+
+	if version.Version == "" {
+		return "latest"
+	}
+
+	if !strings.HasPrefix(version.Version, "v") {
+		return "v" + version.Version
+	}
+
+	return version.Version
+}
+
+func goCommand(dir string, args ...string) error {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return exception.InternalError("`go %s` failed: %w", strings.Join(args, " "), err)
+	}
+
+	return nil
+}
+
+func pluralPlugins(count int) string {
+	if count == 1 {
+		return "plugin"
+	}
+
+	return "plugins"
+}
@@ -0,0 +1,55 @@
+package build
+
+import (
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+)
+
+func TestParseWithRejectsMissingVersion(t *testing.T) {
+	t.Parallel()
+
+	if _, err := parseWith([]string{"example.com/rules"}); err == nil {
+		t.Fatal("expected an error for a module without a version")
+	}
+
+	if _, err := parseWith(nil); err == nil {
+		t.Fatal("expected an error when no modules are given")
+	}
+}
+
+func TestMainSourceImportsEveryPlugin(t *testing.T) {
+	t.Parallel()
+
+	modules, err := parseWith([]string{"example.com/rules@v1.2.0", "example.com/more@latest"})
+	if err != nil {
+		t.Fatalf("parseWith failed: %v", err)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "main.go", mainSource(modules), parser.ImportsOnly)
+	if err != nil {
+		t.Fatalf("generated main does not parse: %v", err)
+	}
+
+	imports := make(map[string]string, len(file.Imports))
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := ""
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+
+		imports[path] = name
+	}
+
+	if _, ok := imports[serenityModule+"/cmd"]; !ok {
+		t.Fatalf("expected the serenity cmd package to be imported, got %v", imports)
+	}
+
+	for _, mod := range modules {
+		if imports[mod.Path] != "_" {
+			t.Fatalf("expected %q to be a blank import, got %v", mod.Path, imports)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/serenitysz/serenity/internal/config"
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/linter"
+	"github.com/serenitysz/serenity/internal/render"
+)
+
+// Stats writes the size of the lint cache and how the last run used it.
+func Stats(configPath string) error {
+	dir, err := cacheDir(configPath)
+
+	if err != nil {
+		return err
+	}
+
+	stats, err := linter.ReadCacheStats(dir)
+
+	if err != nil {
+		return exception.InternalError("could not read the lint cache: %w", err)
+	}
+
+	rows := [][2]string{
+		{"Directory", stats.Dir},
+		{"Entries", strconv.Itoa(stats.Entries)},
+		{"Size", formatBytes(stats.Bytes)},
+	}
+
+	if run := stats.LastRun; run != nil {
+		rows = append(rows,
+			[2]string{"Last run", run.Finished.Local().Format(time.DateTime)},
+			[2]string{"Hit rate", strconv.FormatFloat(run.HitRate()*100, 'f', 1, 64) + "% (" +
+				strconv.FormatInt(run.Hits, 10) + " hits, " + strconv.FormatInt(run.Misses, 10) + " misses)"},
+			[2]string{"Writes", strconv.FormatInt(run.Writes, 10)},
+			[2]string{"Evicted", strconv.Itoa(run.Evicted)},
+		)
+	} else {
+		rows = append(rows, [2]string{"Last run", "-"})
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	for _, row := range rows {
+		if _, err := w.Write([]byte(row[0] + "\t" + row[1] + "\n")); err != nil {
+			return exception.InternalError("could not write the cache stats: %w", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return exception.InternalError("could not write the cache stats: %w", err)
+	}
+
+	return nil
+}
+
+// Clean removes the entries no run used for olderThan, e.g. "72h" or "30d",
+// or the whole cache when it is empty.
+func Clean(configPath, olderThan string) error {
+	var age time.Duration
+
+	if olderThan != "" {
+		parsed, err := parseAge(olderThan)
+
+		if err != nil {
+			return err
+		}
+
+		age = parsed
+	}
+
+	dir, err := cacheDir(configPath)
+
+	if err != nil {
+		return err
+	}
+
+	cleanup, err := linter.CleanCache(dir, age)
+
+	if err != nil {
+		return exception.InternalError("could not clean the lint cache: %w", err)
+	}
+
+	if cleanup.Entries == 0 {
+		render.Infof("nothing to clean")
+
+		return nil
+	}
+
+	noun := "entries"
+
+	if cleanup.Entries == 1 {
+		noun = "entry"
+	}
+
+	render.Successf("removed %d cache %s (%s)", cleanup.Entries, noun, formatBytes(cleanup.Bytes))
+
+	return nil
+}
+
+// cacheDir returns the cache directory the config at configPath, or the one
+// of the working directory, uses.
+func cacheDir(configPath string) (string, error) {
+	cfg, err := config.Load(configPath)
+
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := linter.ResolveCacheDir(cfg)
+
+	if err != nil {
+		return "", exception.InternalError("could not resolve the cache directory: %w", err)
+	}
+
+	return dir, nil
+}
+
+// parseAge reads a Go duration, or a number of days like "30d".
+func parseAge(value string) (time.Duration, error) {
+	var (
+		age time.Duration
+		err error
+	)
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		var n float64
+
+		if n, err = strconv.ParseFloat(days, 64); err == nil {
+			age = time.Duration(n * float64(24*time.Hour))
+		}
+	} else {
+		age, err = time.ParseDuration(value)
+	}
+
+	if err != nil || age <= 0 {
+		return 0, exception.CommandError("invalid --older-than %q: expected a positive duration like 72h or 30d", value)
+	}
+
+	return age, nil
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+
+	if n < unit {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+
+	value, suffix := float64(n)/unit, "KiB"
+
+	for _, next := range []string{"MiB", "GiB", "TiB"} {
+		if value < unit {
+			break
+		}
+
+		value, suffix = value/unit, next
+	}
+
+	return strconv.FormatFloat(value, 'f', 1, 64) + " " + suffix
+}
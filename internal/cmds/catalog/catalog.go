@@ -0,0 +1,128 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/rules"
+	_ "github.com/serenitysz/serenity/internal/rules/all"
+)
+
+func List(group string) error {
+	descs := filter(group)
+
+	if len(descs) == 0 {
+		return exception.CommandError("no rules found in group %q", group)
+	}
+
+	if err := writeList(os.Stdout, descs); err != nil {
+		return exception.InternalError("could not write the rules list: %w", err)
+	}
+
+	return nil
+}
+
+// Docs writes the Markdown rules reference to out, or to stdout when out is
+// empty.
+func Docs(out string) error {
+	if out == "" {
+		if err := WriteDocs(os.Stdout); err != nil {
+			return exception.InternalError("could not write the rules reference: %w", err)
+		}
+
+		return nil
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return exception.InternalError("could not create %q: %w", out, err)
+	}
+
+	if err := WriteDocs(f); err != nil {
+		f.Close()
+
+		return exception.InternalError("could not write the rules reference: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return exception.InternalError("could not write %q: %w", out, err)
+	}
+
+	return nil
+}
+
+func WriteDocs(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("# Rules\n\n")
+	b.WriteString("This reference is generated by `serenity rules docs`; do not edit it by hand.\n")
+
+	for _, desc := range rules.Descriptors() {
+		defaults, err := json.MarshalIndent(desc.Defaults, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(&b, "\n## %s\n\n", desc.Name)
+		fmt.Fprintf(&b, "%s\n\n", desc.Description)
+		fmt.Fprintf(&b, "- Group: `%s`\n", desc.Group)
+		fmt.Fprintf(&b, "- Config: `%s`\n", desc.ConfigPath())
+		fmt.Fprintf(&b, "- Autofix: %s\n\n", fixLabel(desc, "no"))
+		fmt.Fprintf(&b, "Default options:\n\n```json\n%s\n```\n", defaults)
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func writeList(w io.Writer, descs []*rules.Descriptor) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	if _, err := io.WriteString(tw, "NAME\tGROUP\tFIX\tDESCRIPTION\n"); err != nil {
+		return err
+	}
+
+	for _, desc := range descs {
+		row := strings.Join([]string{desc.Name, desc.Group, fixLabel(desc, "-"), desc.Description}, "\t")
+
+		if _, err := io.WriteString(tw, row+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
+
+func filter(group string) []*rules.Descriptor {
+	descs := rules.Descriptors()
+
+	if group == "" {
+		return descs
+	}
+
+	filtered := descs[:0]
+	for _, desc := range descs {
+		if desc.Group == group {
+			filtered = append(filtered, desc)
+		}
+	}
+
+	return filtered
+}
+
+func fixLabel(desc *rules.Descriptor, none string) string {
+	switch {
+	case desc.Fixable:
+		return "safe"
+	case desc.UnsafeFix:
+		return "unsafe"
+	default:
+		return none
+	}
+}
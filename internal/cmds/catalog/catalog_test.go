@@ -0,0 +1,85 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/serenitysz/serenity/internal/rules"
+)
+
+func TestWriteDocsCoversEveryRegisteredRule(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	if err := WriteDocs(&out); err != nil {
+		t.Fatalf("WriteDocs: %v", err)
+	}
+
+	docs := out.String()
+	for _, desc := range rules.Descriptors() {
+		if !strings.Contains(docs, "\n## "+desc.Name+"\n") {
+			t.Fatalf("expected a section for %q", desc.Name)
+		}
+	}
+
+	if !strings.Contains(docs, "`linter.rules.bestPractices.maxParams`") {
+		t.Fatalf("expected config paths in the reference, got:\n%s", docs)
+	}
+
+	if !strings.Contains(docs, `"max": 5`) {
+		t.Fatalf("expected default options in the reference, got:\n%s", docs)
+	}
+}
+
+func TestDescriptorsMatchRuleMetadata(t *testing.T) {
+	t.Parallel()
+
+	descs := rules.Descriptors()
+	if len(descs) == 0 {
+		t.Fatal("expected built-in rules to be registered")
+	}
+
+	for _, desc := range descs {
+		if got := rules.GetRuleName(desc.ID); got != desc.Name {
+			t.Fatalf("descriptor %q has the ID of %q", desc.Name, got)
+		}
+
+		if desc.Fixable != rules.IsFixable(desc.ID) {
+			t.Fatalf("descriptor %q disagrees with the message registry about fixability", desc.Name)
+		}
+
+		cfg := &rules.LinterRulesGroup{}
+		if desc.Build(cfg) != nil {
+			t.Fatalf("expected %q to stay disabled without config", desc.Name)
+		}
+	}
+}
+
+func TestDescriptorOptionsFallBackToDefaults(t *testing.T) {
+	t.Parallel()
+
+	desc, ok := rules.LookupDescriptor(rules.MaxParamsID)
+	if !ok {
+		t.Fatal("expected max-params to be registered")
+	}
+
+	cfg := &rules.LinterRulesGroup{
+		BestPractices: &rules.BestPracticesRulesGroup{
+			Use:       true,
+			MaxParams: &rules.AnyMaxValueBasedRule{Severity: "error"},
+		},
+	}
+
+	opts := desc.Options(cfg).(*rules.AnyMaxValueBasedRule)
+	if opts.Severity != "error" || opts.Max == nil || *opts.Max != 5 {
+		t.Fatalf("expected configured severity over default max, got %+v", opts)
+	}
+
+	if cfg.BestPractices.MaxParams.Max != nil {
+		t.Fatal("expected merging defaults to leave the config untouched")
+	}
+
+	if rule := desc.Build(cfg); rule == nil || rule.Name() != desc.Name {
+		t.Fatalf("expected Build to construct %q, got %v", desc.Name, rule)
+	}
+}
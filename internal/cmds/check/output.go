@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/serenitysz/serenity/internal/exception"
 	"github.com/serenitysz/serenity/internal/render"
@@ -20,6 +21,14 @@ type issueSummary struct {
 	unsafeFixables int
 	writeMode      bool
 	renderer       issueRenderer
+
+	// modules counts the issues of each module of a workspace.
+	modules []moduleCount
+}
+
+type moduleCount struct {
+	path   string
+	issues int
 }
 
 func newIssueSummary(writeMode bool) issueSummary {
@@ -53,6 +62,19 @@ func (s *issueSummary) add(issues []rules.Issue) {
 	}
 }
 
+// addModule records issues found in a module of a workspace. Without one,
+// the summary only shows the total.
+func (s *issueSummary) addModule(mod *rules.Module, issues []rules.Issue) {
+	s.add(issues)
+
+	path := "(no module)"
+	if mod != nil {
+		path = mod.Path
+	}
+
+	s.modules = append(s.modules, moduleCount{path: path, issues: len(issues)})
+}
+
 func (s issueSummary) err() error {
 	if !s.hasIssues {
 		return nil
@@ -100,6 +122,23 @@ func (s issueSummary) describe() string {
 }
 
 func (s issueSummary) footer() string {
+	footer := s.totals()
+
+	if len(s.modules) < 2 {
+		return footer
+	}
+
+	var b strings.Builder
+	b.WriteString(footer)
+
+	for _, mod := range s.modules {
+		fmt.Fprintf(&b, "\n  %s: %s", mod.path, pluralize(mod.issues, "issue"))
+	}
+
+	return b.String()
+}
+
+func (s issueSummary) totals() string {
 	total := s.errors + s.warnings + s.infos
 	base := fmt.Sprintf("%s found (%s)", pluralize(total, "issue"), s.describe())
 
@@ -220,3 +220,19 @@ func TestSummaryErrorMentionsUnsafeFixes(t *testing.T) {
 		t.Fatalf("unexpected unsafe summary message: %q", got)
 	}
 }
+
+func TestIssueSummaryListsWorkspaceModules(t *testing.T) {
+	t.Parallel()
+
+	summary := issueSummary{renderer: newIssueRenderer(&bytes.Buffer{})}
+	summary.addModule(&rules.Module{Path: "example.com/api"}, []rules.Issue{
+		{Path: "api.go", Severity: rules.SeverityWarn},
+		{Path: "api.go", Severity: rules.SeverityError},
+	})
+	summary.addModule(&rules.Module{Path: "example.com/tools"}, nil)
+
+	want := "2 issues found (1 error and 1 warning)\n  example.com/api: 2 issues\n  example.com/tools: 0 issues"
+	if got := exception.Message(summary.err()); got != want {
+		t.Fatalf("unexpected summary message: %q", got)
+	}
+}
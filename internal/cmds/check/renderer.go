@@ -41,10 +41,9 @@ func newIssueRenderer(out io.Writer) issueRenderer {
 }
 
 func (r *issueRenderer) write(issue rules.Issue, msg string) {
-	meta, ok := rules.GetMetadata(issue.ID)
-	ruleName := "unknown-rule"
-	if ok && meta.Name != "" {
-		ruleName = meta.Name
+	ruleName := rules.IssueRuleName(issue)
+	if ruleName == "" {
+		ruleName = "unknown-rule"
 	}
 
 	var b strings.Builder
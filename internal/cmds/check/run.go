@@ -2,7 +2,9 @@ package check
 
 import (
 	"os"
+	"time"
 
+	"github.com/serenitysz/serenity/internal/config"
 	"github.com/serenitysz/serenity/internal/exception"
 	"github.com/serenitysz/serenity/internal/linter"
 	"github.com/serenitysz/serenity/internal/rules"
@@ -14,12 +16,24 @@ func Run(cmd *cobra.Command, args []string, opts *CheckOptions) error {
 		return err
 	}
 
-	cfg, err := loadConfig(opts.ConfigPath)
+	if opts.CPUProfile != "" {
+		stop, err := startCPUProfile(opts.CPUProfile)
+
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+	}
+
+	tree, err := config.LoadTree(opts.ConfigPath)
 
 	if err != nil {
 		return err
 	}
 
+	cfg := tree.Root()
+
 	maxIssues, err := resolveMaxIssues(cmd, cfg)
 
 	if err != nil {
@@ -34,7 +48,51 @@ func Run(cmd *cobra.Command, args []string, opts *CheckOptions) error {
 		opts.MaxFileSize,
 	)
 
-	return runOnPaths(l, args)
+	l.Configs = tree
+
+	if opts.Stats {
+		l.Stats = linter.NewRunStats()
+	}
+
+	if err := l.LoadPlugins(); err != nil {
+		return err
+	}
+
+	defer l.Close()
+
+	if opts.Backup {
+		root, err := linter.JournalRoot(tree)
+
+		if err != nil {
+			return exception.InternalError("could not resolve the backup journal directory: %w", err)
+		}
+
+		dir, err := linter.ResolveJournalDir(root)
+
+		if err != nil {
+			return exception.InternalError("could not resolve the backup journal directory: %w", err)
+		}
+
+		journal, err := linter.OpenJournal(dir)
+
+		if err != nil {
+			return err
+		}
+
+		defer journal.Close()
+
+		l.Journal = journal
+	}
+
+	err = runOnPaths(l, args)
+
+	if opts.MemProfile != "" {
+		if profileErr := writeHeapProfile(opts.MemProfile); profileErr != nil && err == nil {
+			return profileErr
+		}
+	}
+
+	return err
 }
 
 func validateOptions(opts *CheckOptions) error {
@@ -42,6 +100,10 @@ func validateOptions(opts *CheckOptions) error {
 		return exception.CommandError("--unsafe requires --write")
 	}
 
+	if opts != nil && opts.Backup && !opts.Write {
+		return exception.CommandError("--backup requires --write")
+	}
+
 	return nil
 }
 
@@ -66,6 +128,8 @@ func runOnPaths(l *linter.Linter, args []string) error {
 
 	summary := newIssueSummary(l.Write)
 	remaining := l.MaxIssues
+	timing := runTiming{workers: l.Workers}
+	start := time.Now()
 
 	for _, p := range args {
 		if remaining == 0 && l.MaxIssues > 0 {
@@ -86,20 +150,38 @@ func runOnPaths(l *linter.Linter, args []string) error {
 			l.MaxIssues = remaining
 		}
 
-		issues, err := l.ProcessPath(p)
+		results, err := l.ProcessModules(p)
 
 		if err != nil {
 			return exception.InternalError("could not lint %q: %w", p, err)
 		}
 
-		summary.add(issues)
+		rendering := time.Now()
+
+		for _, result := range results {
+			if len(results) > 1 {
+				summary.addModule(result.Module, result.Issues)
+			} else {
+				summary.add(result.Issues)
+			}
 
-		if remaining > 0 {
-			remaining -= len(issues)
-			if remaining < 0 {
-				remaining = 0
+			if remaining > 0 {
+				remaining -= len(result.Issues)
+				if remaining < 0 {
+					remaining = 0
+				}
 			}
 		}
+
+		timing.render += time.Since(rendering)
+	}
+
+	if report := l.StatsReport(); report != nil {
+		timing.wall = time.Since(start)
+
+		if err := writeStats(os.Stderr, report, timing); err != nil {
+			return exception.InternalError("could not write the run stats: %w", err)
+		}
 	}
 
 	return summary.err()
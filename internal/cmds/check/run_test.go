@@ -19,3 +19,16 @@ func TestValidateOptionsRejectsUnsafeWithoutWrite(t *testing.T) {
 		t.Fatalf("unexpected validation error: %q", got)
 	}
 }
+
+func TestValidateOptionsRejectsBackupWithoutWrite(t *testing.T) {
+	t.Parallel()
+
+	err := validateOptions(&CheckOptions{Backup: true})
+	if !errors.Is(err, exception.ErrCommand) {
+		t.Fatalf("expected command error, got %v", err)
+	}
+
+	if got := exception.Message(err); got != "--backup requires --write" {
+		t.Fatalf("unexpected validation error: %q", got)
+	}
+}
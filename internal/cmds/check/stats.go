@@ -0,0 +1,100 @@
+package check
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/linter"
+)
+
+// runTiming is what `check --stats` measures outside of the linter.
+type runTiming struct {
+	wall    time.Duration
+	render  time.Duration
+	workers int
+}
+
+// writeStats writes the phases and rules of a run, slowest rules first.
+// Phase times are summed over the workers.
+func writeStats(out io.Writer, report *linter.StatsReport, timing runTiming) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "\nRun\t%s wall time, %d workers, %s, %s\n",
+		formatMillis(timing.wall), timing.workers, pluralize(int(report.Packages), "package"), pluralize(int(report.Files), "file"))
+
+	if run := report.Cache; run != nil {
+		fmt.Fprintf(w, "Cache\t%d hits, %d misses, %d writes (%s%% hit rate)\n",
+			run.Hits, run.Misses, run.Writes, strconv.FormatFloat(run.HitRate()*100, 'f', 1, 64))
+	} else {
+		fmt.Fprintf(w, "Cache\tdisabled\n")
+	}
+
+	fmt.Fprintf(w, "\nPHASE\tTIME\n")
+
+	for _, phase := range report.Phases {
+		fmt.Fprintf(w, "%s\t%s\n", phase.Phase, formatMillis(phase.Time))
+	}
+
+	fmt.Fprintf(w, "render\t%s\n", formatMillis(timing.render))
+
+	if len(report.Rules) > 0 {
+		fmt.Fprintf(w, "\nRULE\tTIME\tCALLS\n")
+
+		for _, rule := range report.Rules {
+			fmt.Fprintf(w, "%s\t%s\t%d\n", rule.Name, formatMillis(rule.Time), rule.Calls)
+		}
+	}
+
+	return w.Flush()
+}
+
+func formatMillis(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 1, 64) + "ms"
+}
+
+// startCPUProfile writes a CPU profile to path until the returned function
+// is called.
+func startCPUProfile(path string) (func(), error) {
+	file, err := os.Create(path)
+
+	if err != nil {
+		return nil, exception.InternalError("could not create the CPU profile %q: %w", path, err)
+	}
+
+	if err := pprof.StartCPUProfile(file); err != nil {
+		_ = file.Close()
+
+		return nil, exception.InternalError("could not start the CPU profile: %w", err)
+	}
+
+	return func() {
+		pprof.StopCPUProfile()
+		_ = file.Close()
+	}, nil
+}
+
+func writeHeapProfile(path string) error {
+	file, err := os.Create(path)
+
+	if err != nil {
+		return exception.InternalError("could not create the memory profile %q: %w", path, err)
+	}
+
+	defer file.Close()
+
+	// Up to date statistics of what is still allocated.
+	runtime.GC()
+
+	if err := pprof.WriteHeapProfile(file); err != nil {
+		return exception.InternalError("could not write the memory profile: %w", err)
+	}
+
+	return nil
+}
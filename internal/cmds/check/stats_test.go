@@ -0,0 +1,43 @@
+package check
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/serenitysz/serenity/internal/linter"
+)
+
+func TestWriteStatsListsPhasesRulesAndCache(t *testing.T) {
+	t.Parallel()
+
+	report := &linter.StatsReport{
+		Phases: []linter.PhaseTiming{
+			{Phase: linter.PhaseParse, Time: 1500 * time.Microsecond},
+		},
+		Rules: []linter.RuleTiming{
+			{Name: "no-bare-returns", Time: 2 * time.Millisecond, Calls: 42},
+		},
+		Files:    3,
+		Packages: 1,
+		Cache:    &linter.CacheRun{Hits: 3, Misses: 1, Writes: 1},
+	}
+
+	var out bytes.Buffer
+	if err := writeStats(&out, report, runTiming{wall: time.Second, render: time.Millisecond, workers: 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"1000.0ms wall time, 4 workers, 1 package, 3 files",
+		"3 hits, 1 misses, 1 writes (75.0% hit rate)",
+		"parse   1.5ms",
+		"render  1.0ms",
+		"no-bare-returns  2.0ms  42",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in:\n%s", want, out.String())
+		}
+	}
+}
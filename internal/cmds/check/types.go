@@ -3,6 +3,10 @@ package check
 type CheckOptions struct {
 	Write       bool
 	Unsafe      bool
+	Backup      bool
 	MaxFileSize int64
 	ConfigPath  string
+	Stats       bool
+	CPUProfile  string
+	MemProfile  string
 }
@@ -0,0 +1,64 @@
+package configcmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/serenitysz/serenity/internal/config"
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/render"
+)
+
+// Convert writes the config file at path in another format, next to it and
+// with the same name. An existing file is only replaced with force.
+func Convert(path, to string, force bool) error {
+	ext := "." + strings.TrimPrefix(strings.ToLower(to), ".")
+
+	switch ext {
+	case ".json", ".yaml", ".yml", ".toml":
+	default:
+		return exception.CommandError("unsupported format %q; supported formats: json, yaml, yml, toml", to)
+	}
+
+	if ok, err := config.Exists(path); err != nil {
+		return err
+	} else if !ok {
+		return exception.CommandError("config file %q does not exist", path)
+	}
+
+	output := strings.TrimSuffix(path, filepath.Ext(path)) + ext
+
+	if strings.EqualFold(filepath.Ext(path), ext) {
+		return exception.CommandError("%s is already in %s", path, strings.TrimPrefix(ext, "."))
+	}
+
+	if ok, err := config.Exists(output); err != nil {
+		return err
+	} else if ok && !force {
+		return exception.CommandError("%s already exists; use --force to overwrite it", output)
+	}
+
+	data, err := config.Convert(path, ext)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(output, data, 0o644); err != nil {
+		return exception.InternalError("could not write config file %q: %w", output, err)
+	}
+
+	render.Successf("converted %s to %s", path, output)
+
+	// Files in the same directory are looked up in a fixed order.
+	source := slices.Index(config.CANDIDATES[:], filepath.Base(path))
+	target := slices.Index(config.CANDIDATES[:], filepath.Base(output))
+
+	if source >= 0 && target >= 0 && source < target {
+		render.Warnf("%s still takes precedence over %s; remove it to use the new file", path, output)
+	}
+
+	return nil
+}
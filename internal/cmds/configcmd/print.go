@@ -0,0 +1,326 @@
+package configcmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/serenitysz/serenity/internal/config"
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/linter"
+	"github.com/serenitysz/serenity/internal/rules"
+)
+
+type PrintOptions struct {
+	Format     string
+	For        string // A file or directory; the working directory when empty
+	ConfigPath string
+
+	// Flags of `serenity check` that change the config, when given.
+	MaxIssues   *int
+	MaxFileSize *int64
+}
+
+// Resolved is the effective config of a path and the rules it enables.
+type Resolved struct {
+	Path    string            `json:"path" yaml:"path" toml:"path"`
+	Config  map[string]any    `json:"config" yaml:"config" toml:"config"`
+	Origins map[string]string `json:"origins" yaml:"origins" toml:"origins"`
+	Rules   []ResolvedRule    `json:"rules" yaml:"rules" toml:"rules"`
+}
+
+type ResolvedRule struct {
+	Name     string         `json:"name" yaml:"name" toml:"name"`
+	Key      string         `json:"key,omitempty" yaml:"key,omitempty" toml:"key,omitempty"`
+	Severity string         `json:"severity,omitempty" yaml:"severity,omitempty" toml:"severity,omitempty"`
+	Options  map[string]any `json:"options,omitempty" yaml:"options,omitempty" toml:"options,omitempty"`
+	Origin   string         `json:"origin" yaml:"origin" toml:"origin"`
+}
+
+// Print writes the config a path is linted with, where each value comes
+// from, and the rules it enables.
+func Print(opts PrintOptions) error {
+	resolved, err := Resolve(opts)
+
+	if err != nil {
+		return err
+	}
+
+	data, err := encode(opts.Format, resolved)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stdout.Write(data); err != nil {
+		return exception.InternalError("could not write the config: %w", err)
+	}
+
+	return nil
+}
+
+func Resolve(opts PrintOptions) (*Resolved, error) {
+	target := opts.For
+
+	if target == "" {
+		target = "."
+	}
+
+	abs, err := filepath.Abs(target)
+
+	if err != nil {
+		return nil, exception.InternalError("could not resolve %q: %w", target, err)
+	}
+
+	info, err := os.Stat(abs)
+
+	if err != nil {
+		return nil, exception.CommandError("cannot print the config for %q: %v", target, err)
+	}
+
+	dir := abs
+
+	if !info.IsDir() {
+		dir = filepath.Dir(abs)
+	}
+
+	tree, err := config.LoadTree(opts.ConfigPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	origins, err := tree.Origins(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for path, origin := range origins {
+		if origin.Kind == config.OriginFile {
+			origin.Source = relative(origin.Source)
+			origins[path] = origin
+		}
+	}
+
+	// The rules are resolved by a linter set up like the one of `check`, so
+	// plugins, go version gating and generated files are accounted for.
+	l := linter.New(false, false, tree.Root(), 0, 0)
+	l.Configs = tree
+
+	if err := l.LoadPlugins(); err != nil {
+		return nil, err
+	}
+
+	defer l.Close()
+
+	cfg, err := tree.ForDir(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var active *linter.ActiveRules
+
+	if info.IsDir() {
+		if active, err = l.ActiveRulesForDir(dir); err != nil {
+			return nil, err
+		}
+	} else {
+		overrides := cfg.Overrides
+		merged, matched, err := l.ConfigFor(abs)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg = merged
+		if active, err = l.ActiveRulesFor(abs); err != nil {
+			return nil, err
+		}
+
+		for _, i := range matched {
+			source := origins["overrides"]
+			source.Source += " overrides[" + strconv.Itoa(i) + "]"
+
+			for path := range config.ConfigValues(overrides[i].Set()) {
+				origins["linter.rules."+path] = source
+			}
+		}
+	}
+
+	raw, err := config.ConfigMap(cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.MaxIssues != nil {
+		setPath(raw, []string{"linter", "issues", "max"}, *opts.MaxIssues)
+		origins["linter.issues.max"] = config.Origin{Kind: config.OriginFlag, Source: "--max-issues"}
+	}
+
+	if opts.MaxFileSize != nil {
+		setPath(raw, []string{"go", "maxFileSize"}, *opts.MaxFileSize)
+		origins["go.maxFileSize"] = config.Origin{Kind: config.OriginFlag, Source: "--max-file-size"}
+	}
+
+	resolved := &Resolved{
+		Path:    relative(abs),
+		Config:  raw,
+		Origins: make(map[string]string, len(origins)),
+		Rules:   append(resolveRules(active, cfg, origins), resolveAnalyzers(l, origins)...),
+	}
+
+	for path, origin := range origins {
+		resolved.Origins[path] = origin.String()
+	}
+
+	return resolved, nil
+}
+
+func resolveRules(active *linter.ActiveRules, cfg *rules.LinterOptions, origins map[string]config.Origin) []ResolvedRule {
+	// Rules are known by the name they report with, which is not always the
+	// one they are registered under.
+	byName := make(map[string]*rules.Descriptor, 64)
+
+	for _, desc := range rules.Descriptors() {
+		if rule := desc.Build(&cfg.Linter.Rules); rule != nil {
+			byName[rule.Name()] = desc
+		}
+	}
+
+	names := active.Names()
+	out := make([]ResolvedRule, 0, len(names))
+
+	for _, name := range names {
+		rule := ResolvedRule{Name: name}
+		desc, ok := byName[name]
+
+		if !ok {
+			rule.Origin = originOf(origins, "linter.patterns").String()
+			out = append(out, rule)
+
+			continue
+		}
+
+		rule.Key = desc.ConfigPath()
+		rule.Options = config.ConfigValues(desc.Options(&cfg.Linter.Rules))
+
+		if severity, ok := rule.Options["severity"].(string); ok {
+			rule.Severity = severity
+			delete(rule.Options, "severity")
+		}
+
+		if len(rule.Options) == 0 {
+			rule.Options = nil
+		}
+
+		origin := originOf(origins, rule.Key+".severity")
+
+		if origin.Kind == config.OriginDefault {
+			origin = originOf(origins, rule.Key)
+		}
+
+		rule.Origin = origin.String()
+		out = append(out, rule)
+	}
+
+	return out
+}
+
+// resolveAnalyzers lists the go/analysis analyzers of the run, which only
+// the root config enables.
+func resolveAnalyzers(l *linter.Linter, origins map[string]config.Origin) []ResolvedRule {
+	names := l.Analyzers.Names()
+	out := make([]ResolvedRule, 0, len(names))
+
+	for _, name := range names {
+		rule := ResolvedRule{Name: name, Key: "linter.analyzers." + name}
+
+		if base := l.Config.Linter.Analyzers[name]; base != nil {
+			rule.Severity = base.Severity
+		}
+
+		rule.Origin = originOf(origins, rule.Key).String()
+		out = append(out, rule)
+	}
+
+	return out
+}
+
+// originOf returns the origin of the value at path, or of the nearest
+// value below it when path is an object.
+func originOf(origins map[string]config.Origin, path string) config.Origin {
+	if origin, ok := origins[path]; ok {
+		return origin
+	}
+
+	found := config.Origin{Kind: config.OriginDefault}
+	best := ""
+
+	for key, origin := range origins {
+		if strings.HasPrefix(key, path+".") && (best == "" || key < best) {
+			found, best = origin, key
+		}
+	}
+
+	return found
+}
+
+func setPath(raw map[string]any, path []string, value any) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := raw[key].(map[string]any)
+
+		if !ok {
+			next = make(map[string]any)
+			raw[key] = next
+		}
+
+		raw = next
+	}
+
+	raw[path[len(path)-1]] = value
+}
+
+func relative(path string) string {
+	wd, err := os.Getwd()
+
+	if err != nil {
+		return path
+	}
+
+	if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+
+	return path
+}
+
+func encode(format string, resolved *Resolved) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	switch format {
+	case "", "json":
+		data, err = json.MarshalIndent(resolved, "", "  ")
+		data = append(data, '\n')
+	case "yaml", "yml":
+		data, err = yaml.Marshal(resolved)
+	case "toml":
+		data, err = toml.Marshal(resolved)
+	default:
+		return nil, exception.CommandError("unsupported format %q; supported formats: json, yaml, toml", format)
+	}
+
+	if err != nil {
+		return nil, exception.InternalError("could not encode the config as %s: %w", format, err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,150 @@
+package configcmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveAttributesValuesAndRules(t *testing.T) {
+	t.Setenv("SERENITY_CONFIG_PATH", "")
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"serenity.json": `{"linter": {"use": true, "rules": {"recommended": true,
+			"style": {"use": true, "preferIncDec": {"severity": "error"}}}},
+			"overrides": [{"files": ["cmd/**"], "rules": {"bestPractices": {"noMagicNumbers": {"severity": "off"}}}}]}`,
+		"cmd/serenity.yaml": "linter:\n  rules:\n    complexity:\n      maxFuncLines:\n        max: 60\n",
+		"cmd/tool/main.go":  "package main\n",
+		"pkg/lib.go":        "package pkg\n",
+	}
+
+	writeFiles(t, dir, files)
+
+	t.Chdir(dir)
+
+	maxIssues := 3
+	resolved, err := Resolve(PrintOptions{For: "cmd/tool/main.go", MaxIssues: &maxIssues})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	origins := map[string]string{
+		"linter.rules.style.preferIncDec.severity":           "file serenity.json",
+		"linter.rules.complexity.maxFuncLines.max":           "file " + filepath.Join("cmd", "serenity.yaml"),
+		"linter.rules.complexity.maxFuncLines.severity":      "default",
+		"linter.rules.bestPractices.noMagicNumbers.severity": "file serenity.json overrides[0]",
+		"linter.issues.max":                                  "flag --max-issues",
+		"linter.rules.bestPractices.noDeferInLoop.severity":  "preset recommended",
+	}
+
+	for path, want := range origins {
+		if got := resolved.Origins[path]; got != want {
+			t.Errorf("origin of %s = %q, want %q", path, got, want)
+		}
+	}
+
+	byName := make(map[string]ResolvedRule, len(resolved.Rules))
+	for _, rule := range resolved.Rules {
+		byName[rule.Name] = rule
+	}
+
+	if _, ok := byName["no-magic-numbers"]; ok {
+		t.Error("no-magic-numbers is active although the override turns it off")
+	}
+
+	if rule := byName["max-func-lines"]; rule.Options["max"] != int64(60) || rule.Origin != "file "+filepath.Join("cmd", "serenity.yaml") {
+		t.Errorf("max-func-lines = %+v, want max 60 from the nested file", rule)
+	}
+
+	if rule := byName["prefer-inc-dec"]; rule.Severity != "error" || rule.Origin != "file serenity.json" {
+		t.Errorf("prefer-inc-dec = %+v, want error from serenity.json", rule)
+	}
+
+	resolved, err = Resolve(PrintOptions{For: "pkg/lib.go"})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	found := false
+	for _, rule := range resolved.Rules {
+		found = found || rule.Key == "linter.rules.bestPractices.noMagicNumbers"
+	}
+
+	if !found {
+		t.Error("no-magic-numbers is not active outside cmd/")
+	}
+
+	for _, format := range []string{"json", "yaml", "toml"} {
+		data, err := encode(format, resolved)
+		if err != nil {
+			t.Errorf("encode %s: %v", format, err)
+		}
+
+		if strings.Contains(string(data), ".0") {
+			t.Errorf("encode %s printed whole numbers as floats:\n%s", format, data)
+		}
+	}
+}
+
+func TestResolveDirectoryListsTheRulesCheckRuns(t *testing.T) {
+	t.Setenv("SERENITY_CONFIG_PATH", "")
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"serenity.json": `{"linter": {"use": true, "rules": {"recommended": false},
+			"analyzers": {"printf": {"severity": "error"}},
+			"patterns": [{"name": "empty-check", "pattern": "len($s) == 0", "severity": "warn", "minGoVersion": "1.21"}]}}`,
+		"go.mod": "module example.com/old\n\ngo 1.18\n",
+	})
+
+	t.Chdir(dir)
+
+	names := func() map[string]ResolvedRule {
+		t.Helper()
+
+		resolved, err := Resolve(PrintOptions{})
+		if err != nil {
+			t.Fatalf("Resolve failed: %v", err)
+		}
+
+		byName := make(map[string]ResolvedRule, len(resolved.Rules))
+		for _, rule := range resolved.Rules {
+			byName[rule.Name] = rule
+		}
+
+		return byName
+	}
+
+	byName := names()
+	if _, ok := byName["empty-check"]; ok {
+		t.Error("empty-check is listed although the module's go version is older than it needs")
+	}
+
+	if rule := byName["printf"]; rule.Key != "linter.analyzers.printf" || rule.Severity != "error" || rule.Origin != "file serenity.json" {
+		t.Errorf("printf = %+v, want the analyzer from serenity.json", rule)
+	}
+
+	writeFiles(t, dir, map[string]string{"go.mod": "module example.com/old\n\ngo 1.22\n"})
+
+	if _, ok := names()["empty-check"]; !ok {
+		t.Error("empty-check is not listed for a module new enough to run it")
+	}
+}
+
+// writeFiles writes files, keyed by their slash-separated path under dir,
+// creating the directories they need.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, src := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
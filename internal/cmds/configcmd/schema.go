@@ -0,0 +1,32 @@
+package configcmd
+
+import (
+	"os"
+
+	"github.com/serenitysz/serenity/internal/config"
+	"github.com/serenitysz/serenity/internal/exception"
+)
+
+// Schema writes the JSON Schema of config files to output, or to stdout
+// when output is empty.
+func Schema(output string) error {
+	data, err := config.GenerateSchema()
+
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			return exception.InternalError("could not write the config schema: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := os.WriteFile(output, data, 0o644); err != nil {
+		return exception.InternalError("could not write the config schema to %q: %w", output, err)
+	}
+
+	return nil
+}
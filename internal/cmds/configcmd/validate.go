@@ -0,0 +1,87 @@
+package configcmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/serenitysz/serenity/internal/config"
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/render"
+)
+
+// Validate checks the config files at paths, or the one in use for the
+// working directory, and fails when any of them has an error.
+func Validate(paths []string) error {
+	if len(paths) == 0 {
+		path, err := config.SearchConfigPath()
+
+		if err != nil {
+			return err
+		}
+
+		if path == "" {
+			return exception.CommandError("no config file found; run `serenity init` to create one")
+		}
+
+		paths = []string{path}
+	}
+
+	var all []config.Problem
+
+	for _, path := range paths {
+		problems, err := config.Validate(path)
+
+		if err != nil {
+			return err
+		}
+
+		all = append(all, problems...)
+	}
+
+	errors, err := writeProblems(os.Stdout, all)
+
+	if err != nil {
+		return exception.InternalError("could not write the validation output: %w", err)
+	}
+
+	if errors > 0 {
+		return exception.CommandError("found %d %s in the config", errors, plural(errors, "problem", "problems"))
+	}
+
+	if len(paths) == 1 {
+		render.Successf("%s is valid", paths[0])
+	} else {
+		render.Successf("%d config files are valid", len(paths))
+	}
+
+	return nil
+}
+
+func writeProblems(w io.Writer, problems []config.Problem) (int, error) {
+	errors := 0
+
+	for _, p := range problems {
+		tag := render.Tag("error", render.Red, false)
+
+		if p.Warning {
+			tag = render.Tag("warn", render.Yellow, false)
+		} else {
+			errors++
+		}
+
+		if _, err := fmt.Fprintf(w, "%s %s\n", tag, p); err != nil {
+			return errors, err
+		}
+	}
+
+	return errors, nil
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+
+	return many
+}
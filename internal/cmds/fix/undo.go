@@ -0,0 +1,54 @@
+package fix
+
+import (
+	"errors"
+
+	"github.com/serenitysz/serenity/internal/config"
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/linter"
+	"github.com/serenitysz/serenity/internal/render"
+)
+
+// Undo restores the journal of the project the working directory is in, the
+// same one `check --write --backup` recorded from any of its directories.
+func Undo(configPath string) error {
+	tree, err := config.LoadTree(configPath)
+
+	if err != nil {
+		return err
+	}
+
+	root, err := linter.JournalRoot(tree)
+
+	if err != nil {
+		return exception.InternalError("could not resolve the backup journal directory: %w", err)
+	}
+
+	dir, err := linter.ResolveJournalDir(root)
+
+	if err != nil {
+		return exception.InternalError("could not resolve the backup journal directory: %w", err)
+	}
+
+	restored, err := linter.RestoreJournal(dir)
+
+	if errors.Is(err, linter.ErrNoJournal) {
+		return exception.CommandError("no backup journal found; run `serenity check --write --backup` first")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	render.Successf("restored %d %s from the backup journal", restored, pluralFiles(restored))
+
+	return nil
+}
+
+func pluralFiles(count int) string {
+	if count == 1 {
+		return "file"
+	}
+
+	return "files"
+}
@@ -0,0 +1,89 @@
+package suppressions
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// blameTimes maps each line of path to the time it was last authored
+// according to git. It returns nil when the file is not tracked or git is
+// unavailable, in which case ages are shown as unknown.
+func blameTimes(path string) map[int]time.Time {
+	cmd := exec.Command("git", "blame", "--line-porcelain", "--", filepath.Base(path))
+	cmd.Dir = filepath.Dir(path)
+
+	out, err := cmd.Output()
+
+	if err != nil {
+		return nil
+	}
+
+	return parseBlame(out)
+}
+
+func parseBlame(out []byte) map[int]time.Time {
+	times := make(map[int]time.Time, 64)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	line := 0
+
+	for scanner.Scan() {
+		text := scanner.Text()
+
+		if strings.HasPrefix(text, "\t") {
+			continue
+		}
+
+		if value, ok := strings.CutPrefix(text, "author-time "); ok {
+			if unix, err := strconv.ParseInt(value, 10, 64); err == nil && line > 0 {
+				times[line] = time.Unix(unix, 0)
+			}
+
+			continue
+		}
+
+		fields := strings.Fields(text)
+
+		if len(fields) >= 3 && isObjectID(fields[0]) {
+			if final, err := strconv.Atoi(fields[2]); err == nil {
+				line = final
+			}
+		}
+	}
+
+	return times
+}
+
+// isObjectID reports whether s is a full git object id: 40 hex digits, or 64
+// in a SHA-256 repository.
+func isObjectID(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+
+	_, err := hex.DecodeString(s)
+
+	return err == nil
+}
+
+func formatAge(authored time.Time, now time.Time) string {
+	if authored.IsZero() {
+		return "-"
+	}
+
+	days := int(now.Sub(authored).Hours() / 24)
+
+	switch {
+	case days <= 0:
+		return "today"
+	case days == 1:
+		return "1 day"
+	default:
+		return strconv.Itoa(days) + " days"
+	}
+}
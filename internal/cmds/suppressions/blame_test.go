@@ -0,0 +1,63 @@
+package suppressions
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBlameMapsFinalLinesToAuthorTime(t *testing.T) {
+	t.Parallel()
+
+	out := []byte("1111111111111111111111111111111111111111 1 1 2\n" +
+		"author Someone\n" +
+		"author-time 1700000000\n" +
+		"filename a.go\n" +
+		"\tpackage a\n" +
+		"2222222222222222222222222222222222222222 5 2\n" +
+		"author Someone\n" +
+		"author-time 1700086400\n" +
+		"filename a.go\n" +
+		"\t// author-time 1\n")
+
+	times := parseBlame(out)
+
+	if got := times[1].Unix(); got != 1700000000 {
+		t.Fatalf("expected line 1 authored at 1700000000, got %d", got)
+	}
+
+	if got := times[2].Unix(); got != 1700086400 {
+		t.Fatalf("expected line 2 authored at 1700086400, got %d", got)
+	}
+}
+
+func TestParseBlameAcceptsSHA256ObjectIDs(t *testing.T) {
+	t.Parallel()
+
+	out := []byte(strings.Repeat("ab", 32) + " 3 3 1\n" +
+		"author-time 1700000000\n" +
+		"\tpackage a\n")
+
+	if got := parseBlame(out)[3].Unix(); got != 1700000000 {
+		t.Fatalf("expected line 3 authored at 1700000000, got %d", got)
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	cases := map[string]time.Time{
+		"-":       {},
+		"today":   now.Add(-time.Hour),
+		"1 day":   now.Add(-30 * time.Hour),
+		"10 days": now.AddDate(0, 0, -10),
+	}
+
+	for want, authored := range cases {
+		if got := formatAge(authored, now); got != want {
+			t.Fatalf("formatAge(%v) = %q, want %q", authored, got, want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package suppressions
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/serenitysz/serenity/internal/config"
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/linter"
+	"github.com/serenitysz/serenity/internal/render"
+	"github.com/serenitysz/serenity/internal/rules"
+)
+
+type Entry struct {
+	Path   string
+	Line   int
+	Rule   string
+	Reason string
+	Until  string
+	Age    string
+}
+
+func List(args []string, configPath string) error {
+	tree, err := config.LoadTree(configPath)
+
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	cfg := tree.Root()
+	l := linter.New(false, false, cfg, 0, 0)
+	l.Configs = tree
+
+	now := time.Now()
+	parser := rules.NewSuppressionParser(cfg.Linter.Suppressions, now)
+	entries := make([]Entry, 0, 32)
+
+	for _, root := range args {
+		found, err := collect(l, root, parser, now)
+
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, found...)
+	}
+
+	if len(entries) == 0 {
+		render.Infof("no suppressions found")
+
+		return nil
+	}
+
+	if err := writeEntries(os.Stdout, entries); err != nil {
+		return exception.InternalError("could not write the suppressions list: %w", err)
+	}
+
+	return nil
+}
+
+// collect lists the suppressions of the files under root a check would lint.
+func collect(l *linter.Linter, root string, sp *rules.SuppressionParser, now time.Time) ([]Entry, error) {
+	var entries []Entry
+
+	err := l.WalkFiles([]string{root}, func(path string) error {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+
+		if err != nil {
+			render.Warnf("%s  could not parse Go file: %v", path, err)
+
+			return nil
+		}
+
+		sups := rules.ProcessSuppressions(file.Comments, fset, file.Decls, file.Package, sp)
+
+		if len(sups) == 0 {
+			return nil
+		}
+
+		blamed := blameTimes(path)
+
+		for _, sup := range sups {
+			entries = append(entries, Entry{
+				Path:   path,
+				Line:   sup.Line,
+				Rule:   sup.RuleName,
+				Reason: sup.Reason,
+				Until:  sup.Until,
+				Age:    formatAge(blamed[sup.Line], now),
+			})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, exception.InternalError("could not scan %q for suppressions: %w", root, err)
+	}
+
+	return entries, nil
+}
+
+func writeEntries(out *os.File, entries []Entry) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	if _, err := w.Write([]byte("LOCATION\tRULE\tREASON\tUNTIL\tAGE\n")); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		row := strings.Join([]string{
+			entry.Path + ":" + strconv.Itoa(entry.Line),
+			orDash(entry.Rule),
+			orDash(entry.Reason),
+			orDash(entry.Until),
+			entry.Age,
+		}, "\t")
+
+		if _, err := w.Write([]byte(row + "\n")); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
@@ -25,9 +26,26 @@ func Read(path string) (*rules.LinterOptions, error) {
 		return nil, exception.InternalError("config file %q has no extension", path)
 	}
 
+	raw := make(map[string]any)
+
+	if err := unmarshalByExt(ext, data, &raw); err != nil {
+		return nil, exception.InternalError("could not parse config file %q: %w", path, err)
+	}
+
+	if err := problemsError(validateRaw(path, ext, data, raw)); err != nil {
+		return nil, err
+	}
+
+	// Validation renames deprecated keys in raw, so decode from it.
+	merged, err := json.Marshal(raw)
+
+	if err != nil {
+		return nil, exception.InternalError("could not parse config file %q: %w", path, err)
+	}
+
 	var cfg rules.LinterOptions
 
-	if err := unmarshalByExt(ext, data, &cfg); err != nil {
+	if err := json.Unmarshal(merged, &cfg); err != nil {
 		return nil, exception.InternalError("could not parse config file %q: %w", path, err)
 	}
 
@@ -52,3 +70,24 @@ func unmarshalByExt(ext string, data []byte, out any) error {
 		)
 	}
 }
+
+// restoreIntegers turns the whole numbers of v, decoded from JSON as float64,
+// back into integers so YAML and TOML encode them without a fraction.
+func restoreIntegers(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for key, item := range value {
+			value[key] = restoreIntegers(item)
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = restoreIntegers(item)
+		}
+	case float64:
+		if value == math.Trunc(value) && math.Abs(value) < 1<<53 {
+			return int64(value)
+		}
+	}
+
+	return v
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/serenitysz/serenity/internal/rules"
@@ -71,3 +75,581 @@ func TestApplyRecommendedPersistsMutations(t *testing.T) {
 		t.Fatal("expected complexity recommendations to be enabled")
 	}
 }
+
+func TestTreeMergesNestedConfigs(t *testing.T) {
+	t.Setenv("SERENITY_CONFIG_PATH", "")
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"serenity.json": `{
+	"linter": {
+		"use": true,
+		"rules": {
+			"bestPractices": {
+				"use": true,
+				"noBareReturns": {"severity": "warn"},
+				"maxParams": {"severity": "warn", "max": 6}
+			},
+			"naming": {
+				"use": true,
+				"receiverNames": {"severity": "info"}
+			}
+		}
+	}
+}`,
+		"services/payments/serenity.yaml": `linter:
+  rules:
+    bestPractices:
+      maxParams:
+        max: 2
+    naming:
+      use: false
+`,
+		"tools/serenity.toml": `root = true
+
+[linter]
+use = true
+`,
+	}
+
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "services", "payments", "ledger"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir(dir)
+
+	tree, err := LoadTree("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := tree.Root()
+	if got := *root.Linter.Rules.BestPractices.MaxParams.Max; got != 6 {
+		t.Fatalf("expected the root maxParams to be 6, got %d", got)
+	}
+
+	payments, err := tree.ForDir(filepath.Join(dir, "services", "payments", "ledger"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bp := payments.Linter.Rules.BestPractices
+	if got := *bp.MaxParams.Max; got != 2 {
+		t.Fatalf("expected the payments maxParams to be 2, got %d", got)
+	}
+	if bp.MaxParams.Severity != "warn" || bp.NoBareReturns == nil || !bp.Use {
+		t.Fatalf("expected payments to inherit the rest of bestPractices, got %+v", bp)
+	}
+	if payments.Linter.Rules.Naming.Use {
+		t.Fatal("expected payments to disable the naming group")
+	}
+
+	services, err := tree.ForDir(filepath.Join(dir, "services"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if services != root {
+		t.Fatal("expected directories without their own config to share the root config")
+	}
+
+	tools, err := tree.ForDir(filepath.Join(dir, "tools"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tools.Linter.Rules.BestPractices != nil {
+		t.Fatal("expected root: true to stop inheriting from the parent config")
+	}
+
+	explicit, err := LoadTree(filepath.Join(dir, "serenity.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg, _ := explicit.ForDir(filepath.Join(dir, "services", "payments")); cfg != explicit.Root() {
+		t.Fatal("expected an explicit config path to apply to every directory")
+	}
+}
+
+func TestValidateReportsProblemsWithPositions(t *testing.T) {
+	files := map[string]string{
+		"serenity.json": `{
+  "linter": {
+    "use": true,
+    "rules": {
+      "bestPractices": {"use": true, "noMagicNumber": {"severity": "warn"}},
+      "complexity": {"use": "yes", "maxFuncLines": {"severity": "warn", "max": 0}},
+      "correctness": {"use": true, "ununsedParams": {"severity": "warn"}},
+      "naming": {"use": true, "exportedIdentifiers": {"severity": "warn", "pattern": "^[A-Z"}}
+    }
+  },
+  "overrides": [{"files": ["cmd/**"], "rules": {"style": {"maxLineLength": {"severity": "fatal"}}}}]
+}
+`,
+		"serenity.yaml": `linter:
+  use: true
+  rules:
+    bestPractices:
+      use: true
+      noMagicNumber:
+        severity: warn
+    complexity:
+      use: yes
+      maxFuncLines:
+        severity: warn
+        max: 0
+    correctness:
+      use: true
+      ununsedParams:
+        severity: warn
+    naming:
+      use: true
+      exportedIdentifiers:
+        severity: warn
+        pattern: "^[A-Z"
+overrides:
+  - files: ["cmd/**"]
+    rules:
+      style:
+        maxLineLength:
+          severity: fatal
+`,
+		"serenity.toml": `[linter]
+use = true
+
+[linter.rules.bestPractices]
+use = true
+noMagicNumber = { severity = "warn" }
+
+[linter.rules.complexity]
+use = "yes"
+maxFuncLines = { severity = "warn", max = 0 }
+
+[linter.rules.correctness]
+use = true
+ununsedParams = { severity = "warn" }
+
+[linter.rules.naming]
+use = true
+exportedIdentifiers = { severity = "warn", pattern = "^[A-Z" }
+
+[[overrides]]
+files = ["cmd/**"]
+
+[overrides.rules.style.maxLineLength]
+severity = "fatal"
+`,
+	}
+
+	type found struct {
+		Line    int
+		Key     string
+		Message string
+		Warning bool
+	}
+
+	want := map[string][]found{
+		"serenity.json": {
+			{5, "linter.rules.bestPractices.noMagicNumber", `unknown field "noMagicNumber"; did you mean "noMagicNumbers"?`, false},
+			{6, "linter.rules.complexity.maxFuncLines.max", "must be at least 1, got 0", false},
+			{6, "linter.rules.complexity.use", `expected a boolean, got "yes"`, false},
+			{7, "linter.rules.correctness.ununsedParams", `"ununsedParams" is deprecated; use "unusedParams"`, true},
+			{8, "linter.rules.naming.exportedIdentifiers.pattern", "invalid pattern: error parsing regexp: missing closing ]: `[A-Z`", false},
+			{11, "overrides[0].rules.style.maxLineLength.severity", `invalid severity "fatal"; expected one of error, warn, info, off`, false},
+		},
+		"serenity.yaml": {
+			{6, "linter.rules.bestPractices.noMagicNumber", `unknown field "noMagicNumber"; did you mean "noMagicNumbers"?`, false},
+			{12, "linter.rules.complexity.maxFuncLines.max", "must be at least 1, got 0", false},
+			{9, "linter.rules.complexity.use", `expected a boolean, got "yes"`, false},
+			{15, "linter.rules.correctness.ununsedParams", `"ununsedParams" is deprecated; use "unusedParams"`, true},
+			{21, "linter.rules.naming.exportedIdentifiers.pattern", "invalid pattern: error parsing regexp: missing closing ]: `[A-Z`", false},
+			{27, "overrides[0].rules.style.maxLineLength.severity", `invalid severity "fatal"; expected one of error, warn, info, off`, false},
+		},
+		"serenity.toml": {
+			{6, "linter.rules.bestPractices.noMagicNumber", `unknown field "noMagicNumber"; did you mean "noMagicNumbers"?`, false},
+			{10, "linter.rules.complexity.maxFuncLines.max", "must be at least 1, got 0", false},
+			{9, "linter.rules.complexity.use", `expected a boolean, got "yes"`, false},
+			{14, "linter.rules.correctness.ununsedParams", `"ununsedParams" is deprecated; use "unusedParams"`, true},
+			{18, "linter.rules.naming.exportedIdentifiers.pattern", "invalid pattern: error parsing regexp: missing closing ]: `[A-Z`", false},
+			{24, "overrides[0].rules.style.maxLineLength.severity", `invalid severity "fatal"; expected one of error, warn, info, off`, false},
+		},
+	}
+
+	dir := t.TempDir()
+
+	for name, src := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			problems, err := Validate(path)
+			if err != nil {
+				t.Fatalf("Validate failed: %v", err)
+			}
+
+			var got []found
+			for _, p := range problems {
+				if p.File != path || p.Column == 0 {
+					t.Errorf("problem %q has no position in %s", p, path)
+				}
+				got = append(got, found{p.Line, p.Key, p.Message, p.Warning})
+			}
+
+			if !reflect.DeepEqual(got, want[name]) {
+				t.Fatalf("problems = %+v, want %+v", got, want[name])
+			}
+
+			if _, err := LoadTree(path); err == nil {
+				t.Fatal("LoadTree accepted an invalid config")
+			}
+		})
+	}
+}
+
+func TestTreeAcceptsDeprecatedKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "serenity.json")
+	src := `{"linter": {"use": true, "rules": {"correctness": {"use": true, "ununsedParams": {"severity": "error"}}}}}`
+
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err := LoadTree(path)
+	if err != nil {
+		t.Fatalf("LoadTree failed: %v", err)
+	}
+
+	rule := tree.Root().Linter.Rules.Correctness.UnusedParams
+	if rule == nil || rule.Severity != "error" {
+		t.Fatalf("unusedParams = %+v, want severity error", rule)
+	}
+}
+
+func TestEmbeddedSchemaIsUpToDate(t *testing.T) {
+	generated, err := GenerateSchema()
+	if err != nil {
+		t.Fatalf("GenerateSchema failed: %v", err)
+	}
+
+	if string(generated) != string(Schema()) {
+		t.Fatal("schema.json is out of date with the config types; run `go generate ./internal/config`")
+	}
+
+	// The copy the repository's own serenity.json points at.
+	if checkedIn, err := os.ReadFile(filepath.Join("..", "..", SchemaFile)); err != nil || string(checkedIn) != string(generated) {
+		t.Fatalf("%s is missing or out of date (%v); run `go generate ./internal/config`", SchemaFile, err)
+	}
+
+	var schema struct {
+		Defs map[string]struct {
+			Properties map[string]struct {
+				Ref         string   `json:"$ref"`
+				Description string   `json:"description"`
+				Enum        []string `json:"enum"`
+			} `json:"properties"`
+		} `json:"$defs"`
+	}
+
+	if err := json.Unmarshal(generated, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	rule := schema.Defs["ComplexityRulesGroup"].Properties["maxFuncLines"]
+	if rule.Ref != "#/$defs/AnyMaxValueBasedRule" || rule.Description == "" {
+		t.Fatalf("maxFuncLines = %+v, want a described reference to AnyMaxValueBasedRule", rule)
+	}
+
+	if severity := schema.Defs["LinterBaseRule"].Properties["severity"]; !reflect.DeepEqual(severity.Enum, severities) {
+		t.Fatalf("severity enum = %v, want %v", severity.Enum, severities)
+	}
+
+	if _, ok := schema.Defs["CorrectnessRulesGroup"].Properties["unusedParams"]; !ok {
+		t.Fatal("schema has no correctness.unusedParams")
+	}
+}
+
+func TestFieldDescriptionsNameConfigFields(t *testing.T) {
+	types := make(map[string]reflect.Type, 32)
+
+	var collect func(reflect.Type)
+	collect = func(t reflect.Type) {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+
+		if t.Kind() != reflect.Struct || types[t.Name()] != nil {
+			return
+		}
+
+		types[t.Name()] = t
+
+		for i := range t.NumField() {
+			collect(t.Field(i).Type)
+		}
+	}
+
+	collect(reflect.TypeFor[rules.LinterOptions]())
+
+	for key := range fieldDescriptions {
+		typeName, fieldName, _ := strings.Cut(key, ".")
+
+		typ, ok := types[typeName]
+		if !ok {
+			t.Errorf("fieldDescriptions[%q]: no config type %s", key, typeName)
+			continue
+		}
+
+		field, ok := typ.FieldByName(fieldName)
+		if !ok || jsonName(field) == "" {
+			t.Errorf("fieldDescriptions[%q]: %s has no config field %s", key, typeName, fieldName)
+		}
+	}
+}
+
+func TestNewConfigsUseTheLocalSchema(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "serenity.json")
+
+	if err := CreateConfigFile(GenDefaultConfig(nil), path); err != nil {
+		t.Fatalf("CreateConfigFile failed: %v", err)
+	}
+
+	schema, err := os.ReadFile(filepath.Join(dir, SchemaFile))
+	if err != nil {
+		t.Fatalf("schema was not written next to the config: %v", err)
+	}
+
+	if string(schema) != string(Schema()) {
+		t.Fatal("written schema differs from the embedded one")
+	}
+
+	problems, err := Validate(path)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	if len(problems) != 0 {
+		t.Fatalf("problems = %+v, want none for a new config", problems)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, SchemaFile), []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err = Validate(path)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	if len(problems) != 1 || problems[0].Key != "$schema" || !problems[0].Warning || !strings.Contains(problems[0].Message, "out of date") {
+		t.Fatalf("problems = %+v, want a warning about the stale schema", problems)
+	}
+
+	remote := filepath.Join(dir, "remote.json")
+	if err := os.WriteFile(remote, []byte(`{"$schema": "https://example.com/schema.json"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err = Validate(remote)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	if len(problems) != 1 || problems[0].Key != "$schema" || problems[0].Line != 1 {
+		t.Fatalf("problems = %+v, want a positioned warning about the remote schema", problems)
+	}
+}
+
+func TestConvertKeepsValuesOrderAndComments(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "serenity.yaml")
+
+	yamlSrc := `# Serenity config
+linter:
+  use: true # always
+  rules:
+    errors:
+      use: true
+      noErrorShadowing:
+        severity: warn
+go:
+  exclude: ["vendor/**", "a: b"]
+overrides:
+  # tests are looser
+  - files: ["**/*_test.go"]
+    rules:
+      errors:
+        noErrorShadowing:
+          severity: "off"
+`
+
+	if err := os.WriteFile(src, []byte(yamlSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := Read(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := src
+
+	for _, ext := range []string{".toml", ".yml", ".json", ".yaml"} {
+		data, err := Convert(path, ext)
+		if err != nil {
+			t.Fatalf("%s to %s: %v", filepath.Ext(path), ext, err)
+		}
+
+		path = filepath.Join(dir, "converted"+ext)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := Read(path)
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", ext, err, data)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s changed the config:\n%s", ext, data)
+		}
+
+		// Defaults stay out of the converted file.
+		if strings.Contains(string(data), "maxFileSize") {
+			t.Fatalf("%s expanded defaults:\n%s", ext, data)
+		}
+
+		if ext == ".toml" {
+			for _, line := range []string{"# Serenity config\n[linter]\nuse = true # always\n", "# tests are looser\n[[overrides]]\n"} {
+				if !strings.Contains(string(data), line) {
+					t.Fatalf("TOML lost %q:\n%s", line, data)
+				}
+			}
+		}
+	}
+}
+
+func TestGolangciTargetsAreRegisteredRules(t *testing.T) {
+	registered := make(map[string]bool)
+	for _, desc := range rules.Descriptors() {
+		registered[desc.Group+"."+desc.Key] = true
+	}
+
+	for _, table := range []map[string]golangciRule{golangciLinters, reviveRules} {
+		for name, target := range table {
+			if !registered[target.rule] {
+				t.Errorf("%s maps to %s, which no rule implements", name, target.rule)
+			}
+		}
+	}
+}
+
+func TestImportGolangciTranslatesLintersAndExclusions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".golangci.yml")
+
+	src := `version: "2"
+linters:
+  default: none
+  enable: [gocyclo, funlen, lll, depguard, errcheck]
+  settings:
+    gocyclo:
+      min-complexity: 15
+    funlen:
+      lines: 80
+    lll:
+      line-length: 120
+      tab-width: 4
+    depguard:
+      rules:
+        main:
+          deny:
+            - pkg: github.com/pkg/errors
+  exclusions:
+    paths:
+      - third_party$
+      - _gen\.go$
+    rules:
+      - path: _test\.go
+        linters: [lll]
+`
+
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := ImportGolangci(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	group := imported.Config.Linter.Rules
+
+	if group.Complexity.CyclomaticComplexity != nil {
+		t.Fatalf("cyclomaticComplexity = %+v, want gocyclo skipped as no rule implements it", group.Complexity.CyclomaticComplexity)
+	}
+
+	if max := group.Complexity.MaxFuncLines.Max; max == nil || *max != 80 {
+		t.Fatalf("maxFuncLines.max = %v, want 80", max)
+	}
+
+	if max := group.Complexity.MaxLineLength.Max; max == nil || *max != 120 {
+		t.Fatalf("maxLineLength.max = %v, want 120", max)
+	}
+
+	if got := group.Imports.DisallowedPackages.Packages; !reflect.DeepEqual(got, []string{"github.com/pkg/errors"}) {
+		t.Fatalf("disallowedPackages.packages = %v", got)
+	}
+
+	exclude := *imported.Config.File.Exclude
+	for _, glob := range []string{"**/third_party/**", "**/*_gen.go"} {
+		if !slices.Contains(exclude, glob) {
+			t.Fatalf("go.exclude = %v, want %s in it", exclude, glob)
+		}
+	}
+
+	overrides := imported.Config.Overrides
+	if len(overrides) != 1 || overrides[0].Files[0] != "**/*_test.go" ||
+		overrides[0].Rules.Complexity.MaxLineLength.Severity != rules.SeverityOff {
+		t.Fatalf("overrides = %+v", overrides)
+	}
+
+	for _, want := range []string{"errcheck: no equivalent rule", "gocyclo: no equivalent rule", "lll.tab-width: no equivalent option"} {
+		if !slices.Contains(imported.Skipped, want) {
+			t.Fatalf("skipped = %q, want %q in it", imported.Skipped, want)
+		}
+	}
+
+	// The override only turns lll off: the rest of the group is left as is.
+	want := map[string]any{"complexity": map[string]any{"maxLineLength": map[string]any{"severity": rules.SeverityOff}}}
+
+	for _, name := range []string{"serenity.json", "serenity.yaml", "serenity.toml"} {
+		written := filepath.Join(t.TempDir(), name)
+		if err := CreateConfigFile(imported.Config, written); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := Read(written)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if got := cfg.Overrides[0].Set(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: override rules = %v, want %v", name, got, want)
+		}
+
+		if merged := rules.MergeRules(cfg.Linter.Rules, cfg.Overrides[0]); !merged.Complexity.Use {
+			t.Fatalf("%s: expected the override to keep the complexity group on", name)
+		}
+	}
+}
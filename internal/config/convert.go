@@ -0,0 +1,493 @@
+package config
+
+import (
+	"bytes"
+	"cmp"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/render"
+)
+
+// Convert returns the config file at path in the format of ext. Only the
+// values the file sets are kept, in the order it sets them, with the
+// comments of YAML and TOML files. JSON has no comments, so converting to it
+// drops them.
+func Convert(path, ext string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, exception.InternalError("could not read config file %q: %w", path, err)
+	}
+
+	from := strings.ToLower(filepath.Ext(path))
+	raw := make(map[string]any)
+
+	if err := unmarshalByExt(from, data, &raw); err != nil {
+		return nil, exception.InternalError("could not parse config file %q: %w", path, err)
+	}
+
+	// Null is the same as unset, and TOML cannot write it.
+	prune(raw)
+
+	// The schema only helps JSON editors, as when the file is generated.
+	if ext != ".json" {
+		delete(raw, "$schema")
+	}
+
+	order, notes := indexByExt(from, data)
+	doc := &document{order: order, comments: notes}
+
+	var b bytes.Buffer
+
+	switch ext {
+	case ".json":
+		doc.json(&b, raw, nil, "")
+		b.WriteByte('\n')
+
+		if len(notes) > 0 {
+			render.Warnf("%s: JSON has no comments; the ones in the file are dropped", path)
+		}
+	case ".toml":
+		doc.toml(&b, raw, nil, nil)
+	case ".yml", ".yaml":
+		doc.yaml(&b, raw, nil, "", "")
+	default:
+		return nil, exception.InternalError(
+			"unsupported config format %q; supported formats: .json, .toml, .yaml, .yml",
+			ext,
+		)
+	}
+
+	converted := make(map[string]any)
+	if err := unmarshalByExt(ext, b.Bytes(), &converted); err != nil || !sameValues(raw, converted) {
+		return nil, exception.InternalError("could not convert config file %q to %s: the values differ", path, ext)
+	}
+
+	return b.Bytes(), nil
+}
+
+func prune(obj map[string]any) {
+	for key, value := range obj {
+		switch v := value.(type) {
+		case nil:
+			delete(obj, key)
+		case map[string]any:
+			prune(v)
+		}
+	}
+}
+
+// sameValues compares two decoded files as JSON sees them, as each decoder
+// has its own numeric types.
+func sameValues(a, b map[string]any) bool {
+	normalize := func(v map[string]any) any {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+
+		var out any
+		if json.Unmarshal(data, &out) != nil {
+			return nil
+		}
+
+		return out
+	}
+
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+// document writes a decoded file with the key order and comments of its
+// source.
+type document struct {
+	order    positions
+	comments comments
+}
+
+// keys returns the keys of obj, at path, in the order of the source file.
+func (d *document) keys(obj map[string]any, path []string) []string {
+	keys := slices.Sorted(maps.Keys(obj))
+
+	slices.SortStableFunc(keys, func(a, b string) int {
+		pa, okA := d.order[keyPath(append(path[:len(path):len(path)], a))]
+		pb, okB := d.order[keyPath(append(path[:len(path):len(path)], b))]
+
+		switch {
+		case okA != okB && okA:
+			return -1
+		case okA != okB:
+			return 1
+		case pa.line != pb.line:
+			return pa.line - pb.line
+		}
+
+		return pa.column - pb.column
+	})
+
+	return keys
+}
+
+func (d *document) json(b *bytes.Buffer, value any, path []string, indent string) {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			b.WriteString("{}")
+			return
+		}
+
+		b.WriteString("{\n")
+
+		for i, key := range d.keys(v, path) {
+			if i > 0 {
+				b.WriteString(",\n")
+			}
+
+			b.WriteString(indent + "\t" + jsonScalar(key) + ": ")
+			d.json(b, v[key], append(path[:len(path):len(path)], key), indent+"\t")
+		}
+
+		b.WriteString("\n" + indent + "}")
+	case []any:
+		if len(v) == 0 {
+			b.WriteString("[]")
+			return
+		}
+
+		b.WriteString("[\n")
+
+		for i, item := range v {
+			if i > 0 {
+				b.WriteString(",\n")
+			}
+
+			b.WriteString(indent + "\t")
+			d.json(b, item, append(path[:len(path):len(path)], indexPart(i)), indent+"\t")
+		}
+
+		b.WriteString("\n" + indent + "]")
+	default:
+		b.WriteString(jsonScalar(v))
+	}
+}
+
+// yaml writes the keys of obj in block style. The first one starts with
+// first instead of indent when obj is an element of a sequence.
+func (d *document) yaml(b *bytes.Buffer, obj map[string]any, path []string, indent, first string) {
+	for i, key := range d.keys(obj, path) {
+		child := append(path[:len(path):len(path)], key)
+		note := d.comments[keyPath(child)]
+
+		prefix := indent
+		if i == 0 && first != "" {
+			prefix = first
+		}
+
+		writeComments(b, strings.TrimSuffix(prefix, "- "), note.head)
+		b.WriteString(prefix + yamlString(key) + ":")
+
+		switch v := obj[key].(type) {
+		case map[string]any:
+			if len(v) == 0 {
+				b.WriteString(" {}" + lineComment(note.line) + "\n")
+				continue
+			}
+
+			b.WriteString(lineComment(note.line) + "\n")
+			d.yaml(b, v, child, indent+"  ", "")
+		case []any:
+			if len(v) == 0 {
+				b.WriteString(" []" + lineComment(note.line) + "\n")
+				continue
+			}
+
+			b.WriteString(lineComment(note.line) + "\n")
+			d.yamlSequence(b, v, child, indent+"  ")
+		default:
+			b.WriteString(" " + yamlScalar(v) + lineComment(note.line) + "\n")
+		}
+	}
+}
+
+func (d *document) yamlSequence(b *bytes.Buffer, items []any, path []string, indent string) {
+	for i, item := range items {
+		child := append(path[:len(path):len(path)], indexPart(i))
+		note := d.comments[keyPath(child)]
+
+		writeComments(b, indent, note.head)
+
+		switch v := item.(type) {
+		case map[string]any:
+			if len(v) == 0 {
+				b.WriteString(indent + "- {}" + lineComment(note.line) + "\n")
+				continue
+			}
+
+			d.yaml(b, v, child, indent+"  ", indent+"- ")
+		case []any:
+			if len(v) == 0 {
+				b.WriteString(indent + "- []" + lineComment(note.line) + "\n")
+				continue
+			}
+
+			b.WriteString(indent + "-" + lineComment(note.line) + "\n")
+			d.yamlSequence(b, v, child, indent+"  ")
+		default:
+			b.WriteString(indent + "- " + yamlScalar(v) + lineComment(note.line) + "\n")
+		}
+	}
+}
+
+// toml writes the keys of obj, a table at path, named header in the file.
+// Values come before the tables they are followed by, as TOML requires.
+func (d *document) toml(b *bytes.Buffer, obj map[string]any, path, header []string) {
+	keys := d.keys(obj, path)
+
+	for _, key := range keys {
+		if isTable(obj[key]) || isTableArray(obj[key]) {
+			continue
+		}
+
+		child := append(path[:len(path):len(path)], key)
+		note := d.comments[keyPath(child)]
+
+		writeComments(b, "", note.head)
+		b.WriteString(tomlKey(key) + " = " + d.tomlValue(obj[key], child) + lineComment(note.line) + "\n")
+	}
+
+	for _, key := range keys {
+		child := append(path[:len(path):len(path)], key)
+		name := append(header[:len(header):len(header)], key)
+
+		switch {
+		case isTable(obj[key]):
+			table := obj[key].(map[string]any)
+
+			// A table of tables alone is declared by them.
+			if note, ok := d.comments[keyPath(child)]; ok || !onlyTables(table) {
+				d.tomlTable(b, "["+tomlHeader(name)+"]", note)
+			}
+
+			d.toml(b, table, child, name)
+		case isTableArray(obj[key]):
+			for i, item := range obj[key].([]any) {
+				element := append(child[:len(child):len(child)], indexPart(i))
+				note := d.comments[keyPath(element)]
+
+				// The comments of the array go with its first table.
+				if i == 0 {
+					outer := d.comments[keyPath(child)]
+					note.head = append(outer.head[:len(outer.head):len(outer.head)], note.head...)
+					note.line = cmp.Or(note.line, outer.line)
+				}
+
+				d.tomlTable(b, "[["+tomlHeader(name)+"]]", note)
+				d.toml(b, item.(map[string]any), element, name)
+			}
+		}
+	}
+}
+
+func (d *document) tomlTable(b *bytes.Buffer, header string, note comment) {
+	if b.Len() > 0 {
+		b.WriteByte('\n')
+	}
+
+	writeComments(b, "", note.head)
+	b.WriteString(header + lineComment(note.line) + "\n")
+}
+
+func (d *document) tomlValue(value any, path []string) string {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			return "{}"
+		}
+
+		parts := make([]string, 0, len(v))
+		for _, key := range d.keys(v, path) {
+			parts = append(parts, tomlKey(key)+" = "+d.tomlValue(v[key], append(path[:len(path):len(path)], key)))
+		}
+
+		return "{ " + strings.Join(parts, ", ") + " }"
+	case []any:
+		parts := make([]string, 0, len(v))
+		for i, item := range v {
+			parts = append(parts, d.tomlValue(item, append(path[:len(path):len(path)], indexPart(i))))
+		}
+
+		return "[" + strings.Join(parts, ", ") + "]"
+	case string:
+		return tomlString(v)
+	case encoding.TextMarshaler:
+		// Dates and times, which TOML writes bare.
+		text, _ := v.MarshalText()
+		return string(text)
+	}
+
+	return number(value)
+}
+
+func isTable(value any) bool {
+	obj, ok := value.(map[string]any)
+	return ok && len(obj) > 0
+}
+
+func onlyTables(obj map[string]any) bool {
+	for _, value := range obj {
+		if !isTable(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isTableArray(value any) bool {
+	items, ok := value.([]any)
+	if !ok || len(items) == 0 {
+		return false
+	}
+
+	for _, item := range items {
+		if _, ok := item.(map[string]any); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func writeComments(b *bytes.Buffer, indent string, lines []string) {
+	for _, line := range lines {
+		b.WriteString(indent + "#" + line + "\n")
+	}
+}
+
+func lineComment(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	return " #" + text
+}
+
+func jsonScalar(value any) string {
+	var b bytes.Buffer
+
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(value); err != nil {
+		return "null"
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func yamlScalar(value any) string {
+	switch v := value.(type) {
+	case string:
+		return yamlString(v)
+	case encoding.TextMarshaler:
+		text, _ := v.MarshalText()
+		return yamlString(string(text))
+	}
+
+	return number(value)
+}
+
+// yamlString quotes s when YAML would read it as something else.
+func yamlString(s string) string {
+	out, err := yaml.Marshal(s)
+	if err != nil || strings.Contains(s, "\n") || bytes.Count(out, []byte{'\n'}) > 1 {
+		return strconv.Quote(s)
+	}
+
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func tomlKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+
+	return tomlString(key)
+}
+
+func tomlHeader(name []string) string {
+	parts := make([]string, len(name))
+	for i, part := range name {
+		parts[i] = tomlKey(part)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func tomlString(s string) string {
+	var b strings.Builder
+
+	b.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+				continue
+			}
+
+			b.WriteRune(r)
+		}
+	}
+
+	b.WriteByte('"')
+
+	return b.String()
+}
+
+// number writes the numbers and booleans of any decoder. Whole floats are
+// integers, as JSON does not tell them apart.
+func number(value any) string {
+	rv := reflect.ValueOf(value)
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		if f := rv.Float(); f == math.Trunc(f) && math.Abs(f) < 1e15 {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 64)
+	}
+
+	return fmt.Sprint(value)
+}
@@ -87,6 +87,13 @@ func CreateConfigFile(config *rules.LinterOptions, path string) error {
 		return exception.InternalError("could not write config file %q: %w", path, err)
 	}
 
+	if strings.ToLower(filepath.Ext(path)) == ".json" && config.Schema == "./"+SchemaFile {
+		schema := filepath.Join(filepath.Dir(path), SchemaFile)
+		if err := os.WriteFile(schema, Schema(), 0o644); err != nil {
+			return exception.InternalError("could not write the config schema to %q: %w", schema, err)
+		}
+	}
+
 	return nil
 }
 
@@ -116,15 +123,31 @@ func marshalConfigByExt(config *rules.LinterOptions, path string) ([]byte, error
 		cfg.Schema = ""
 	}
 
-	switch ext {
-	case ".json":
+	if ext == ".json" {
 		return json.MarshalIndent(&cfg, "", "\t")
+	}
+
+	// The YAML and TOML encoders skip MarshalJSON, which writes overrides as
+	// their file set them, so overrides are encoded from that and appended.
+	overrides := cfg.Overrides
+	cfg.Overrides = nil
 
+	var tail map[string]any
+	if len(overrides) > 0 {
+		values, err := overrideValues(overrides)
+		if err != nil {
+			return nil, err
+		}
+
+		tail = map[string]any{"overrides": values}
+	}
+
+	switch ext {
 	case ".toml":
-		return toml.Marshal(&cfg)
+		return appendEncoded(toml.Marshal, &cfg, tail)
 
 	case ".yml", ".yaml":
-		return yaml.Marshal(&cfg)
+		return appendEncoded(yaml.Marshal, &cfg, tail)
 
 	default:
 		return nil, exception.InternalError(
@@ -133,3 +156,31 @@ func marshalConfigByExt(config *rules.LinterOptions, path string) ([]byte, error
 		)
 	}
 }
+
+func overrideValues(overrides []rules.OverrideOptions) ([]any, error) {
+	data, err := json.Marshal(overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	return restoreIntegers(values).([]any), nil
+}
+
+func appendEncoded(marshal func(any) ([]byte, error), cfg *rules.LinterOptions, tail map[string]any) ([]byte, error) {
+	data, err := marshal(cfg)
+	if err != nil || tail == nil {
+		return data, err
+	}
+
+	extra, err := marshal(tail)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(append(data, '\n'), extra...), nil
+}
@@ -3,7 +3,6 @@ package config
 import (
 	"github.com/serenitysz/serenity/internal/rules"
 	"github.com/serenitysz/serenity/internal/utils"
-	"github.com/serenitysz/serenity/internal/version"
 )
 
 func GenDefaultConfig(autofix *bool) *rules.LinterOptions {
@@ -13,8 +12,9 @@ func GenDefaultConfig(autofix *bool) *rules.LinterOptions {
 		File: &rules.GoFileOptions{
 			MaxFileSize: &OneMBInBytes,
 			Exclude:     &[]string{"**/vendor/**", "**/*.test.go"},
+			Generated:   utils.Ptr(rules.GeneratedSkip),
 		},
-		Schema: "https://raw.githubusercontent.com/serenitysz/schema/main/versions/" + version.Version + ".json",
+		Schema: "./" + SchemaFile,
 		Linter: rules.LinterRules{
 			Use: true,
 			Rules: rules.LinterRulesGroup{
@@ -50,10 +50,11 @@ func GenStrictDefaultConfig(autofix *bool) *rules.LinterOptions {
 	)
 
 	return &rules.LinterOptions{
-		Schema: "https://raw.githubusercontent.com/serenitysz/schema/main/versions/" + version.Version + ".json",
+		Schema: "./" + SchemaFile,
 		File: &rules.GoFileOptions{
 			MaxFileSize: &oneMB,
 			Exclude:     &[]string{"**/vendor/**", "**/*.test.go"},
+			Generated:   utils.Ptr(rules.GeneratedSkip),
 		},
 		Linter: rules.LinterRules{
 			Use: true,
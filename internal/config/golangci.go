@@ -0,0 +1,778 @@
+package config
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/serenitysz/serenity/internal/analyzers"
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/rules"
+	"github.com/serenitysz/serenity/internal/utils"
+)
+
+// Imported is a config translated from another linter's, with what could
+// not be translated.
+type Imported struct {
+	Config     *rules.LinterOptions
+	Translated []string // e.g. "lll.line-length -> complexity.maxLineLength.max"
+	Skipped    []string // e.g. "errcheck: no equivalent rule"
+}
+
+// golangciRule is where a golangci-lint linter, or a revive rule, lands: a
+// rule as group.key, and the setting that becomes one of its options. For
+// revive the setting is the index of the argument, or * for all of them.
+type golangciRule struct {
+	rule    string
+	setting string
+	option  string
+}
+
+// golangciLinters are the linters with an equivalent rule, as
+// rules.CompatSuppressions knows them. depguard and revive are read apart.
+var golangciLinters = map[string]golangciRule{
+	"depguard":  {rule: "imports.disallowedPackages"},
+	"funlen":    {rule: "complexity.maxFuncLines", setting: "lines", option: "max"},
+	"gomnd":     {rule: "bestPractices.noMagicNumbers"},
+	"lll":       {rule: "complexity.maxLineLength", setting: "line-length", option: "max"},
+	"mnd":       {rule: "bestPractices.noMagicNumbers"},
+	"nakedret":  {rule: "bestPractices.noBareReturns"},
+	"prealloc":  {rule: "bestPractices.useSliceCapacity"},
+	"wrapcheck": {rule: "errors.errorNotWrapped"},
+}
+
+var reviveRules = map[string]golangciRule{
+	"argument-limit":         {rule: "bestPractices.maxParams", setting: "0", option: "max"},
+	"bare-return":            {rule: "bestPractices.noBareReturns"},
+	"bool-literal-in-expr":   {rule: "correctness.boolLiteralExpressions"},
+	"context-as-argument":    {rule: "bestPractices.useContextInFirstParam"},
+	"dot-imports":            {rule: "imports.noDotImports"},
+	"empty-block":            {rule: "correctness.emptyBlock"},
+	"error-strings":          {rule: "errors.errorStringFormat"},
+	"exported":               {rule: "naming.exportedIdentifiers"},
+	"function-length":        {rule: "complexity.maxFuncLines", setting: "1", option: "max"},
+	"imports-blacklist":      {rule: "imports.disallowedPackages", setting: "*", option: "packages"},
+	"imports-blocklist":      {rule: "imports.disallowedPackages", setting: "*", option: "packages"},
+	"increment-decrement":    {rule: "style.preferIncDec"},
+	"line-length-limit":      {rule: "complexity.maxLineLength", setting: "0", option: "max"},
+	"receiver-naming":        {rule: "naming.receiverNames"},
+	"redundant-import-alias": {rule: "imports.redundantImportAlias"},
+}
+
+// The rules revive runs when its settings list none.
+var reviveDefaults = []string{
+	"blank-imports", "context-as-argument", "context-keys-type", "dot-imports",
+	"empty-block", "error-naming", "error-return", "error-strings", "errorf",
+	"exported", "increment-decrement", "indent-error-flow", "package-comments",
+	"range", "receiver-naming", "redefines-builtin-id", "superfluous-else",
+	"time-naming", "unexported-return", "unreachable-code", "unused-parameter",
+	"var-declaration", "var-naming",
+}
+
+// The passes of go vet, which govet runs unless told otherwise.
+var govetDefaults = []string{
+	"appends", "asmdecl", "assign", "atomic", "bools", "buildtag", "cgocall",
+	"composites", "copylocks", "directive", "errorsas", "framepointer",
+	"httpresponse", "ifaceassert", "loopclosure", "lostcancel", "nilfunc",
+	"printf", "shift", "sigchanyzer", "slog", "stdmethods", "stringintconv",
+	"structtag", "testinggoroutine", "tests", "timeformat", "unmarshal",
+	"unreachable", "unsafeptr", "unusedresult",
+}
+
+// The linters golangci-lint enables when the config does not say.
+var golangciStandard = []string{"errcheck", "govet", "ineffassign", "staticcheck", "unused"}
+
+// golangci-lint fails the run on any issue, so imported rules are errors.
+const importedSeverity = "error"
+
+// ImportGolangci translates the golangci-lint config at path, in the v1 or
+// v2 format, to the closest Serenity config.
+func ImportGolangci(path string) (*Imported, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, exception.InternalError("could not read golangci-lint config %q: %w", path, err)
+	}
+
+	src := make(map[string]any)
+	if err := unmarshalByExt(strings.ToLower(filepath.Ext(path)), data, &src); err != nil {
+		return nil, exception.InternalError("could not parse golangci-lint config %q: %w", path, err)
+	}
+
+	autofix := false
+	base := GenDefaultConfig(&autofix)
+	base.Linter.Rules = rules.LinterRulesGroup{}
+	base.Linter.Issues = nil
+
+	raw, err := ConfigMap(base)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &golangciImport{
+		src: src,
+		raw: raw,
+		v2:  fmt.Sprint(src["version"]) == "2",
+		out: &Imported{},
+
+		sources: make(map[string]string, 8),
+	}
+
+	// Existing //nolint comments keep silencing the rules they name.
+	suppressions := nested(raw, "linter", "suppressions")
+	suppressions["use"], suppressions["compat"] = true, true
+
+	g.enabled = g.linters()
+
+	for _, linter := range g.enabled {
+		g.linter(linter)
+	}
+
+	g.exclusions()
+	g.buildTags()
+
+	cfg, err := decodeImported(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	g.out.Config = cfg
+
+	return g.out, nil
+}
+
+type golangciImport struct {
+	src     map[string]any
+	raw     map[string]any // the Serenity config, as a file would set it
+	v2      bool
+	out     *Imported
+	enabled []string
+	sources map[string]string // the setting each option comes from
+}
+
+func (g *golangciImport) translated(format string, args ...any) {
+	g.out.Translated = append(g.out.Translated, fmt.Sprintf(format, args...))
+}
+
+func (g *golangciImport) skip(format string, args ...any) {
+	g.out.Skipped = append(g.out.Skipped, fmt.Sprintf(format, args...))
+}
+
+// settings returns the settings of linter, where the version keeps them.
+func (g *golangciImport) settings(linter string) map[string]any {
+	if g.v2 {
+		obj, _ := lookup(g.src, "linters", "settings", linter).(map[string]any)
+		return obj
+	}
+
+	obj, _ := lookup(g.src, "linters-settings", linter).(map[string]any)
+	return obj
+}
+
+// linters returns the enabled linters, sorted.
+func (g *golangciImport) linters() []string {
+	enabled := make(map[string]bool, 16)
+
+	all := lookup(g.src, "linters", "enable-all") == true
+	none := lookup(g.src, "linters", "disable-all") == true
+
+	if g.v2 {
+		switch mode := lookup(g.src, "linters", "default"); mode {
+		case nil, "standard":
+		case "all":
+			all = true
+		case "none":
+			none = true
+		default:
+			none = true
+			g.skip("linters.default: %v has no equivalent; only the linters enabled by name are imported", mode)
+		}
+	}
+
+	switch {
+	case all:
+		for name := range golangciLinters {
+			enabled[name] = true
+		}
+		enabled["govet"], enabled["revive"], enabled["nolintlint"] = true, true, true
+
+		g.skip("linters: all enables every linter; only the ones with an equivalent are imported")
+	case !none:
+		for _, name := range golangciStandard {
+			enabled[name] = true
+		}
+	}
+
+	for _, name := range stringList(lookup(g.src, "linters", "enable")) {
+		enabled[name] = true
+	}
+
+	for _, name := range stringList(lookup(g.src, "linters", "disable")) {
+		delete(enabled, name)
+	}
+
+	return sortedKeys(enabled)
+}
+
+func (g *golangciImport) linter(name string) {
+	settings := g.settings(name)
+
+	switch name {
+	case "govet":
+		g.govet(settings)
+		return
+	case "revive":
+		g.revive(settings)
+		return
+	case "nolintlint":
+		g.nolintlint(settings)
+		return
+	case "depguard":
+		g.depguard(settings)
+		return
+	}
+
+	target, ok := golangciLinters[name]
+	if !ok {
+		g.skip("%s: no equivalent rule", name)
+		return
+	}
+
+	opts := g.enable(target.rule, importedSeverity)
+	g.translated("%s -> %s", name, target.rule)
+
+	for _, key := range sortedKeys(settings) {
+		if key != target.setting {
+			g.skip("%s.%s: no equivalent option", name, key)
+			continue
+		}
+
+		g.option(opts, target, settings[key], name+"."+key)
+	}
+}
+
+// enable turns on a rule, given as group.key, and returns its options.
+func (g *golangciImport) enable(rule, severity string) map[string]any {
+	group, key, _ := strings.Cut(rule, ".")
+
+	groupOpts := nested(g.raw, "linter", "rules", group)
+	groupOpts["use"] = true
+
+	opts := nested(groupOpts, key)
+	if _, ok := opts["severity"]; !ok {
+		opts["severity"] = severity
+	}
+
+	return opts
+}
+
+// option sets the option of target from the value of a setting, when it
+// makes sense for the rule.
+func (g *golangciImport) option(opts map[string]any, target golangciRule, value any, source string) {
+	key := target.rule + "." + target.option
+	if first, ok := g.sources[key]; ok {
+		g.skip("%s: %s is already set from %s", source, key, first)
+		return
+	}
+
+	switch target.option {
+	case "max":
+		n, ok := integer(value)
+		if !ok || n < 1 || n > 65535 {
+			g.skip("%s: %v is not a limit Serenity can use", source, value)
+			return
+		}
+
+		opts["max"] = int(n)
+	default:
+		list := stringList(value)
+		if list == nil {
+			g.skip("%s: expected a list of strings", source)
+			return
+		}
+
+		opts[target.option] = list
+	}
+
+	g.sources[key] = source
+	g.translated("%s -> %s", source, key)
+}
+
+func (g *golangciImport) depguard(settings map[string]any) {
+	var packages []string
+
+	// depguard v2 keeps named lists of denied packages; v1 had a single one.
+	if lists, ok := settings["rules"].(map[string]any); ok {
+		for _, name := range sortedKeys(lists) {
+			list, _ := lists[name].(map[string]any)
+			source := "depguard.rules." + name
+
+			for _, key := range sortedKeys(list) {
+				switch key {
+				case "deny":
+					items, _ := list[key].([]any)
+					for _, item := range items {
+						entry, _ := item.(map[string]any)
+						pkg, _ := entry["pkg"].(string)
+
+						if pkg == "" || strings.HasPrefix(pkg, "$") {
+							g.skip("%s.deny: %q has no equivalent", source, pkg)
+							continue
+						}
+
+						packages = append(packages, pkg)
+					}
+				case "files":
+					g.skip("%s.files: packages are denied in every file", source)
+				default:
+					g.skip("%s.%s: no equivalent option", source, key)
+				}
+			}
+		}
+	} else {
+		switch settings["list-type"] {
+		case nil, "denylist", "blacklist":
+			packages = stringList(settings["packages"])
+		default:
+			g.skip("depguard.list-type: %v has no equivalent", settings["list-type"])
+		}
+	}
+
+	if len(packages) == 0 {
+		g.skip("depguard: no denied packages to import")
+		return
+	}
+
+	slices.Sort(packages)
+
+	opts := g.enable("imports.disallowedPackages", importedSeverity)
+	opts["packages"] = slices.Compact(packages)
+
+	g.translated("depguard -> imports.disallowedPackages")
+}
+
+func (g *golangciImport) revive(settings map[string]any) {
+	type reviveRule struct {
+		name     string
+		args     []any
+		severity string
+	}
+
+	var list []reviveRule
+
+	for _, key := range sortedKeys(settings) {
+		if key != "rules" && key != "severity" {
+			g.skip("revive.%s: no equivalent option", key)
+		}
+	}
+
+	items, ok := settings["rules"].([]any)
+	if !ok {
+		for _, name := range reviveDefaults {
+			list = append(list, reviveRule{name: name})
+		}
+	}
+
+	for _, item := range items {
+		entry, _ := item.(map[string]any)
+		if entry["disabled"] == true {
+			continue
+		}
+
+		name, _ := entry["name"].(string)
+		args, _ := entry["arguments"].([]any)
+		severity, _ := entry["severity"].(string)
+		list = append(list, reviveRule{name: name, args: args, severity: severity})
+	}
+
+	fallback, _ := settings["severity"].(string)
+
+	for _, rule := range list {
+		target, ok := reviveRules[rule.name]
+		if !ok {
+			g.skip("revive.%s: no equivalent rule", rule.name)
+			continue
+		}
+
+		severity := importedSeverity
+		if cmp.Or(rule.severity, fallback) == "warning" {
+			severity = "warn"
+		}
+
+		opts := g.enable(target.rule, severity)
+		g.translated("revive.%s -> %s", rule.name, target.rule)
+
+		source := "revive." + rule.name + ".arguments"
+
+		switch {
+		case target.setting == "*" && len(rule.args) > 0:
+			g.option(opts, target, rule.args, source)
+		case target.setting != "" && target.setting != "*":
+			if i, _ := strconv.Atoi(target.setting); i < len(rule.args) {
+				g.option(opts, target, rule.args[i], source+"["+target.setting+"]")
+			}
+		case len(rule.args) > 0:
+			g.skip("%s: no equivalent option", source)
+		}
+	}
+}
+
+func (g *golangciImport) govet(settings map[string]any) {
+	enabled := make(map[string]bool, len(govetDefaults))
+
+	switch {
+	case settings["enable-all"] == true:
+		for _, name := range analyzers.Names() {
+			enabled[name] = true
+		}
+	case settings["disable-all"] != true:
+		for _, name := range govetDefaults {
+			enabled[name] = true
+		}
+	}
+
+	for _, name := range stringList(settings["enable"]) {
+		enabled[name] = true
+	}
+
+	if settings["check-shadowing"] == true {
+		enabled["shadow"] = true
+	}
+
+	for _, name := range stringList(settings["disable"]) {
+		delete(enabled, name)
+	}
+
+	var missing []string
+
+	for _, name := range sortedKeys(enabled) {
+		if _, ok := analyzers.Lookup(name); !ok {
+			missing = append(missing, name)
+			continue
+		}
+
+		nested(g.raw, "linter", "analyzers", name)["severity"] = importedSeverity
+	}
+
+	g.translated("govet -> linter.analyzers")
+
+	if len(missing) > 0 {
+		g.skip("govet: the %s passes are not available", strings.Join(missing, ", "))
+	}
+
+	if _, ok := settings["settings"]; ok {
+		g.skip("govet.settings: analyzers take no options")
+	}
+}
+
+func (g *golangciImport) nolintlint(settings map[string]any) {
+	if reason, ok := settings["require-explanation"].(bool); ok {
+		nested(g.raw, "linter", "suppressions")["requireReason"] = reason
+		g.translated("nolintlint.require-explanation -> linter.suppressions.requireReason")
+	}
+
+	for _, key := range sortedKeys(settings) {
+		if key != "require-explanation" {
+			g.skip("nolintlint.%s: no equivalent option", key)
+		}
+	}
+}
+
+// exclusions turns the files golangci-lint skips into go.exclude, and the
+// linters it skips for some files into overrides.
+func (g *golangciImport) exclusions() {
+	var (
+		files []string
+		dirs  []string
+		paths []string
+		excl  []any
+	)
+
+	if g.v2 {
+		paths = stringList(lookup(g.src, "linters", "exclusions", "paths"))
+		excl, _ = lookup(g.src, "linters", "exclusions", "rules").([]any)
+
+		if lookup(g.src, "linters", "exclusions", "generated") == "disable" {
+			nested(g.raw, "go")["generated"] = rules.GeneratedLint
+			g.translated("linters.exclusions.generated -> go.generated")
+		}
+	} else {
+		dirs = append(stringList(lookup(g.src, "issues", "exclude-dirs")), stringList(lookup(g.src, "run", "skip-dirs"))...)
+		files = append(stringList(lookup(g.src, "issues", "exclude-files")), stringList(lookup(g.src, "run", "skip-files"))...)
+		excl, _ = lookup(g.src, "issues", "exclude-rules").([]any)
+
+		if lookup(g.src, "issues", "exclude-generated") == "disable" {
+			nested(g.raw, "go")["generated"] = rules.GeneratedLint
+			g.translated("issues.exclude-generated -> go.generated")
+		}
+
+		if lookup(g.src, "issues", "exclude") != nil {
+			g.skip("issues.exclude: excluding issues by text has no equivalent")
+		}
+	}
+
+	exclude := stringList(lookup(g.raw, "go", "exclude"))
+
+	add := func(expr string, glob string, ok bool) {
+		if !ok {
+			g.skip("exclusion %q: only simple path patterns translate to globs", expr)
+			return
+		}
+
+		if !slices.Contains(exclude, glob) {
+			exclude = append(exclude, glob)
+		}
+
+		g.translated("exclusion %q -> go.exclude %q", expr, glob)
+	}
+
+	for _, expr := range dirs {
+		glob, ok := globFromRegexp(expr, true)
+		add(expr, glob, ok)
+	}
+
+	for _, expr := range files {
+		glob, ok := globFromRegexp(expr, false)
+		add(expr, glob, ok)
+	}
+
+	for _, expr := range paths {
+		glob, ok := pathGlob(expr)
+		add(expr, glob, ok)
+	}
+
+	for i, item := range excl {
+		entry, _ := item.(map[string]any)
+		source := fmt.Sprintf("exclusion rule %d", i+1)
+
+		if _, ok := entry["text"]; ok {
+			g.skip("%s: matching issues by text has no equivalent", source)
+			continue
+		}
+
+		if _, ok := entry["source"]; ok {
+			g.skip("%s: matching issues by source has no equivalent", source)
+			continue
+		}
+
+		if _, ok := entry["path-except"]; ok {
+			g.skip("%s: path-except has no equivalent", source)
+			continue
+		}
+
+		expr, _ := entry["path"].(string)
+		if expr == "" {
+			g.skip("%s: exclusions for every file have no equivalent; disable the linters instead", source)
+			continue
+		}
+
+		glob, ok := pathGlob(expr)
+		if !ok {
+			g.skip("%s: only simple path patterns translate to globs", source)
+			continue
+		}
+
+		linters := stringList(entry["linters"])
+		if len(linters) == 0 {
+			add(expr, glob, true)
+			continue
+		}
+
+		g.override(source, glob, linters)
+	}
+
+	nested(g.raw, "go")["exclude"] = exclude
+}
+
+// override turns linters off for the files matching glob.
+func (g *golangciImport) override(source, glob string, linters []string) {
+	overrideRules := make(map[string]any)
+
+	for _, name := range linters {
+		// Nothing to turn off.
+		if !slices.Contains(g.enabled, name) {
+			continue
+		}
+
+		target, ok := golangciLinters[name]
+		if !ok {
+			g.skip("%s: %s has no equivalent rule to turn off", source, name)
+			continue
+		}
+
+		group, key, _ := strings.Cut(target.rule, ".")
+		nested(overrideRules, group, key)["severity"] = rules.SeverityOff
+	}
+
+	if len(overrideRules) == 0 {
+		return
+	}
+
+	overrides, _ := g.raw["overrides"].([]any)
+	g.raw["overrides"] = append(overrides, map[string]any{
+		"files": []any{glob},
+		"rules": overrideRules,
+	})
+
+	g.translated("%s -> overrides for %q", source, glob)
+}
+
+func (g *golangciImport) buildTags() {
+	tags := stringList(lookup(g.src, "run", "build-tags"))
+	if len(tags) == 0 {
+		return
+	}
+
+	nested(g.raw, "go")["targets"] = []any{map[string]any{"tags": tags}}
+	g.translated("run.build-tags -> go.targets")
+}
+
+// pathGlob translates a regexp matched against file paths, telling files
+// from directories by whether the last element names a file.
+func pathGlob(expr string) (string, bool) {
+	name := expr[strings.LastIndex(expr, "/")+1:]
+
+	return globFromRegexp(expr, !strings.Contains(name, `\.`) && !strings.Contains(name, ".*"))
+}
+
+var extension = regexp.MustCompile(`\\\.[A-Za-z0-9]+$`)
+
+// globFromRegexp translates the path regexps golangci-lint excludes with to
+// a glob, for the simple ones people write: names, ^ and $ anchors, .* and
+// escaped dots. Without ^ the pattern applies in any directory.
+func globFromRegexp(expr string, dir bool) (string, bool) {
+	body := expr
+	start := strings.HasPrefix(body, "^")
+	body = strings.TrimPrefix(strings.TrimPrefix(body, "^"), "(^|/)")
+
+	// A pattern naming an extension means files ending with it.
+	end := strings.HasSuffix(body, "$") || strings.HasSuffix(body, "($|/)") || extension.MatchString(body)
+	body = strings.TrimSuffix(strings.TrimSuffix(body, "$"), "($|/)")
+
+	var b strings.Builder
+
+	for i := 0; i < len(body); i++ {
+		switch c := body[i]; {
+		case c == '\\' && i+1 < len(body) && strings.IndexByte(`.-_/`, body[i+1]) >= 0:
+			b.WriteByte(body[i+1])
+			i++
+		case c == '.' && i+1 < len(body) && body[i+1] == '*':
+			b.WriteByte('*')
+			i++
+		case c == '/' || c == '-' || c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9':
+			b.WriteByte(c)
+		default:
+			return "", false
+		}
+	}
+
+	glob := b.String()
+	if glob == "" {
+		return "", false
+	}
+
+	if dir {
+		glob = strings.TrimSuffix(glob, "/") + "/**"
+	} else {
+		if !start && !strings.HasPrefix(glob, "*") && !strings.Contains(glob, "/") {
+			glob = "*" + glob
+		}
+
+		if !end && !strings.HasSuffix(glob, "*") {
+			glob += "*"
+		}
+	}
+
+	if !start && !strings.HasPrefix(glob, "/") {
+		glob = "**/" + glob
+	}
+
+	glob = strings.TrimPrefix(glob, "/")
+
+	return glob, utils.ValidGlob(glob)
+}
+
+func decodeImported(raw map[string]any) (*rules.LinterOptions, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, exception.InternalError("could not build the imported config: %w", err)
+	}
+
+	// Checked as a file would be, with the types a decoder gives.
+	decoded := make(map[string]any)
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, exception.InternalError("could not build the imported config: %w", err)
+	}
+
+	if err := problemsError(validateRaw("imported config", ".json", data, decoded)); err != nil {
+		return nil, err
+	}
+
+	var cfg rules.LinterOptions
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, exception.InternalError("could not build the imported config: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+func lookup(obj map[string]any, path ...string) any {
+	var value any = obj
+
+	for _, key := range path {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		value = m[key]
+	}
+
+	return value
+}
+
+// nested returns the object at path in obj, creating what is missing.
+func nested(obj map[string]any, path ...string) map[string]any {
+	for _, key := range path {
+		next, ok := obj[key].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			obj[key] = next
+		}
+
+		obj = next
+	}
+
+	return obj
+}
+
+func stringList(value any) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil
+			}
+
+			out = append(out, s)
+		}
+
+		return out
+	}
+
+	return nil
+}
+
+func sortedKeys[V any](obj map[string]V) []string {
+	return slices.Sorted(maps.Keys(obj))
+}
@@ -0,0 +1,15 @@
+package config
+
+import "github.com/serenitysz/serenity/internal/rules"
+
+// Load returns the config at path, or the one for the working directory
+// merged from every config file above it when path is empty.
+func Load(path string) (*rules.LinterOptions, error) {
+	tree, err := LoadTree(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tree.Root(), nil
+}
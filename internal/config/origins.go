@@ -0,0 +1,135 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/rules"
+)
+
+// Kinds of Origin.
+const (
+	OriginDefault = "default"
+	OriginPreset  = "preset"
+	OriginFile    = "file"
+	OriginFlag    = "flag"
+)
+
+// Origin is where a config value comes from: the built-in defaults, a preset
+// like the recommended rules, a config file or a command line flag.
+type Origin struct {
+	Kind   string
+	Source string // The preset, file or flag name
+}
+
+func (o Origin) String() string {
+	if o.Source == "" {
+		return o.Kind
+	}
+
+	return o.Kind + " " + o.Source
+}
+
+// Origins returns where each value in the config of dir comes from, by key
+// path. Arrays are one value, as a file sets them as a whole.
+func (t *Tree) Origins(dir string) (map[string]Origin, error) {
+	chain, err := t.Files(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	origins := make(map[string]Origin, 64)
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		raw, err := t.read(chain[i])
+		if err != nil {
+			return nil, err
+		}
+
+		for path := range flatten(raw, nil, nil) {
+			origins[path] = Origin{Kind: OriginFile, Source: chain[i]}
+		}
+	}
+
+	base, err := t.decode(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := t.merge(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	// Whatever the preset changed is its own; the rest the files left unset
+	// are defaults.
+	before := ConfigValues(base)
+
+	for path, value := range ConfigValues(cfg) {
+		if old, ok := before[path]; !ok || !reflect.DeepEqual(old, value) {
+			origins[path] = Origin{Kind: OriginPreset, Source: "recommended"}
+			continue
+		}
+
+		if _, ok := origins[path]; !ok {
+			origins[path] = Origin{Kind: OriginDefault}
+		}
+	}
+
+	return origins, nil
+}
+
+// ConfigValues flattens v, a config or part of one, to its values by key
+// path.
+func ConfigValues(v any) map[string]any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+
+	return flatten(restoreIntegers(raw).(map[string]any), nil, nil)
+}
+
+// ConfigMap returns cfg as the generic map the config files decode to, with
+// whole numbers kept as integers so YAML and TOML do not print them as 20.0.
+func ConfigMap(cfg *rules.LinterOptions) (map[string]any, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, exception.InternalError("could not encode the config: %w", err)
+	}
+
+	raw := make(map[string]any)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, exception.InternalError("could not encode the config: %w", err)
+	}
+
+	return restoreIntegers(raw).(map[string]any), nil
+}
+
+func flatten(value map[string]any, path []string, out map[string]any) map[string]any {
+	if out == nil {
+		out = make(map[string]any, 64)
+	}
+
+	for key, item := range value {
+		child := append(path[:len(path):len(path)], key)
+
+		if obj, ok := asMap(item); ok && len(obj) > 0 {
+			flatten(obj, child, out)
+			continue
+		}
+
+		out[keyPath(child)] = item
+	}
+
+	return out
+}
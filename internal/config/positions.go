@@ -0,0 +1,387 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+type position struct {
+	line   int
+	column int
+}
+
+// positions maps the key paths of a config file, as keyPath builds them, to
+// where each key starts. Array elements without a key of their own may be
+// missing; lookups fall back to the closest parent.
+type positions map[string]position
+
+// comment is what a YAML or TOML file says about a key: the lines above it
+// and the end of its own line, without the leading '#'.
+type comment struct {
+	head []string
+	line string
+}
+
+type comments map[string]comment
+
+func (p positions) lookup(path []string) position {
+	for n := len(path); n > 0; n-- {
+		if pos, ok := p[keyPath(path[:n])]; ok {
+			return pos
+		}
+	}
+
+	return position{line: 1, column: 1}
+}
+
+// keyPath joins path as a.b[0].c, the form problems are reported with.
+func keyPath(path []string) string {
+	var b bytes.Buffer
+
+	for i, part := range path {
+		if len(part) > 0 && part[0] == '[' {
+			b.WriteString(part)
+			continue
+		}
+
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(part)
+	}
+
+	return b.String()
+}
+
+func indexPart(i int) string {
+	return "[" + strconv.Itoa(i) + "]"
+}
+
+func positionsByExt(ext string, data []byte) positions {
+	p, _ := indexByExt(ext, data)
+	return p
+}
+
+// indexByExt returns where each key of a file of format ext starts, and its
+// comments. JSON has none.
+func indexByExt(ext string, data []byte) (positions, comments) {
+	switch ext {
+	case ".json":
+		return jsonPositions(data), comments{}
+	case ".toml":
+		return tomlIndex(data)
+	case ".yml", ".yaml":
+		return yamlIndex(data)
+	}
+
+	return positions{}, comments{}
+}
+
+// syntaxPosition returns where a decoder of ext failed, when it says.
+func syntaxPosition(data []byte, err error) (position, bool) {
+	var jsonErr *json.SyntaxError
+	if errors.As(err, &jsonErr) {
+		return offsetPosition(data, int(jsonErr.Offset)), true
+	}
+
+	var tomlErr *toml.DecodeError
+	if errors.As(err, &tomlErr) {
+		line, column := tomlErr.Position()
+		return position{line: line, column: column}, true
+	}
+
+	var yamlErr interface{ GetToken() *token.Token }
+	if errors.As(err, &yamlErr) && yamlErr.GetToken() != nil {
+		pos := yamlErr.GetToken().Position
+		return position{line: pos.Line, column: pos.Column}, true
+	}
+
+	return position{}, false
+}
+
+func offsetPosition(data []byte, offset int) position {
+	if offset > len(data) {
+		offset = len(data)
+	}
+
+	lead := data[:offset]
+
+	return position{
+		line:   bytes.Count(lead, []byte{'\n'}) + 1,
+		column: offset - bytes.LastIndexByte(lead, '\n'),
+	}
+}
+
+func jsonPositions(data []byte) positions {
+	p := make(positions, 64)
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	// start skips what the decoder consumed without returning it, so an
+	// offset points at the next token rather than at the separator before it.
+	start := func() int {
+		offset := int(dec.InputOffset())
+		for offset < len(data) && bytes.IndexByte([]byte(" \t\r\n,:"), data[offset]) >= 0 {
+			offset++
+		}
+
+		return offset
+	}
+
+	var value func(path []string) error
+	value = func(path []string) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				offset := start()
+
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+
+				name, _ := key.(string)
+				child := append(path[:len(path):len(path)], name)
+				p[keyPath(child)] = offsetPosition(data, offset)
+
+				if err := value(child); err != nil {
+					return err
+				}
+			}
+
+			_, err = dec.Token()
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				child := append(path[:len(path):len(path)], indexPart(i))
+				p[keyPath(child)] = offsetPosition(data, start())
+
+				if err := value(child); err != nil {
+					return err
+				}
+			}
+
+			_, err = dec.Token()
+		}
+
+		return err
+	}
+
+	// A syntax error leaves the keys before it, which is all there is to find.
+	_ = value(nil)
+
+	return p
+}
+
+func yamlIndex(data []byte) (positions, comments) {
+	p := make(positions, 64)
+	c := make(comments)
+
+	file, err := parser.ParseBytes(data, parser.ParseComments)
+	if err != nil || len(file.Docs) == 0 {
+		return p, c
+	}
+
+	var walk func(node ast.Node, path []string)
+	walk = func(node ast.Node, path []string) {
+		switch n := node.(type) {
+		case *ast.DocumentNode:
+			walk(n.Body, path)
+		case *ast.TagNode:
+			walk(n.Value, path)
+		case *ast.AnchorNode:
+			walk(n.Value, path)
+		case *ast.MappingNode:
+			for _, value := range n.Values {
+				walk(value, path)
+			}
+		case *ast.MappingValueNode:
+			tok := n.Key.GetToken()
+			if tok == nil {
+				return
+			}
+
+			child := append(path[:len(path):len(path)], tok.Value)
+			p[keyPath(child)] = position{line: tok.Position.Line, column: tok.Position.Column}
+
+			// The end of the line is the key's when the value starts below it.
+			line := n.Key.GetComment()
+			if inline(n.Value) {
+				line = n.Value.GetComment()
+			}
+			c.add(child, n.GetComment(), line)
+
+			walk(n.Value, child)
+		case *ast.SequenceNode:
+			for i, value := range n.Values {
+				child := append(path[:len(path):len(path)], indexPart(i))
+				if tok := value.GetToken(); tok != nil {
+					p[keyPath(child)] = position{line: tok.Position.Line, column: tok.Position.Column}
+				}
+
+				// The comment of a block sequence is above its first element.
+				var head, line *ast.CommentGroupNode
+				if i < len(n.ValueHeadComments) {
+					head = n.ValueHeadComments[i]
+				}
+				if i == 0 && head == nil && !n.IsFlowStyle {
+					head = n.GetComment()
+				}
+				if inline(value) {
+					line = value.GetComment()
+				}
+				c.add(child, head, line)
+
+				walk(value, child)
+			}
+		}
+	}
+
+	walk(file.Docs[0], nil)
+
+	return p, c
+}
+
+// inline reports whether node is written on the line of its key.
+func inline(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		return n.IsFlowStyle
+	case *ast.SequenceNode:
+		return n.IsFlowStyle
+	case *ast.MappingValueNode, *ast.TagNode, *ast.AnchorNode:
+		return false
+	}
+
+	return true
+}
+
+func (c comments) add(path []string, head, line *ast.CommentGroupNode) {
+	var found comment
+
+	if head != nil {
+		for _, text := range head.Comments {
+			found.head = append(found.head, text.Token.Value)
+		}
+	}
+
+	if line != nil && len(line.Comments) > 0 {
+		found.line = line.Comments[0].Token.Value
+	}
+
+	if found.head != nil || found.line != "" {
+		c[keyPath(path)] = found
+	}
+}
+
+func tomlIndex(data []byte) (positions, comments) {
+	p := make(positions, 64)
+	c := make(comments)
+	tables := make(map[string]int, 4) // elements of each array of tables so far
+
+	parse := unstable.Parser{KeepComments: true}
+	parse.Reset(data)
+
+	at := func(node *unstable.Node) position {
+		shape := parse.Shape(node.Raw)
+		return position{line: shape.Start.Line, column: shape.Start.Column}
+	}
+
+	// key records every part of a dotted key under path. A part naming an
+	// array of tables refers to its latest element, except as the header of
+	// a new element.
+	key := func(path []string, node *unstable.Node) []string {
+		it := node.Key()
+		for it.Next() {
+			part := it.Node()
+			path = append(path[:len(path):len(path)], string(part.Data))
+			p[keyPath(path)] = at(part)
+
+			if n := tables[keyPath(path)]; n > 0 && !(node.Kind == unstable.ArrayTable && it.IsLast()) {
+				path = append(path, indexPart(n-1))
+			}
+		}
+
+		return path
+	}
+
+	var value func(path []string, node *unstable.Node)
+	value = func(path []string, node *unstable.Node) {
+		switch node.Kind {
+		case unstable.InlineTable:
+			it := node.Children()
+			for it.Next() {
+				kv := it.Node()
+				value(key(path, kv), kv.Value())
+			}
+		case unstable.Array:
+			it := node.Children()
+			for i := 0; it.Next(); {
+				if it.Node().Kind == unstable.Comment {
+					continue
+				}
+
+				value(append(path[:len(path):len(path)], indexPart(i)), it.Node())
+				i++
+			}
+		}
+	}
+
+	var (
+		table []string
+		head  []string // comment lines since the last expression
+	)
+
+	// record gives the comments around an expression to the key or table it
+	// declares.
+	record := func(path []string, expr *unstable.Node) {
+		found := comment{head: head}
+		if next := expr.Next(); next != nil && next.Kind == unstable.Comment {
+			found.line = string(next.Data[1:])
+		}
+
+		if found.head != nil || found.line != "" {
+			c[keyPath(path)] = found
+		}
+
+		head = nil
+	}
+
+	for parse.NextExpression() {
+		expr := parse.Expression()
+
+		switch expr.Kind {
+		case unstable.Comment:
+			head = append(head, string(expr.Data[1:]))
+		case unstable.Table:
+			table = key(nil, expr)
+			record(table, expr)
+		case unstable.ArrayTable:
+			table = key(nil, expr)
+
+			name := keyPath(table)
+			pos := p[name]
+			table = append(table, indexPart(tables[name]))
+			tables[name]++
+			p[keyPath(table)] = pos
+			record(table, expr)
+		case unstable.KeyValue:
+			path := key(table, expr)
+			record(path, expr)
+			value(path, expr.Value())
+		}
+	}
+
+	return p, c
+}
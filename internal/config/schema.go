@@ -0,0 +1,271 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"math"
+	"reflect"
+
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/rules"
+	_ "github.com/serenitysz/serenity/internal/rules/all"
+)
+
+//go:generate go run ../.. config schema --output schema.json
+//go:generate go run ../.. config schema --output ../../serenity.schema.json
+
+// embeddedSchema is GenerateSchema's output for this build, so editors and
+// validation work offline. A test keeps it in sync with the types.
+//
+//go:embed schema.json
+var embeddedSchema []byte
+
+// SchemaFile is where new JSON configs expect the schema, next to them, so
+// editors check them against this build's options rather than a URL.
+const SchemaFile = "serenity.schema.json"
+
+// Schema returns the JSON Schema of config files built into the binary.
+func Schema() []byte {
+	return embeddedSchema
+}
+
+// fieldDescriptions documents options that are not rules, by struct and
+// field name. Rules take theirs from the registry.
+var fieldDescriptions = map[string]string{
+	"LinterOptions.Linter":      "Rules and how issues are reported.",
+	"LinterOptions.File":        "Which Go files are linted.",
+	"LinterOptions.Git":         "Reserved for limiting linting to files known to git; not read yet.",
+	"LinterOptions.Schema":      "JSON Schema of this file, for editors.",
+	"LinterOptions.Extends":     "Reserved for config files this one builds on; not read yet.",
+	"LinterOptions.Root":        "Stops merging config files from parent directories.",
+	"LinterOptions.Overrides":   "Rule changes for the files matching some globs, applied in order.",
+	"LinterOptions.Assistance":  "Automatic fixes.",
+	"LinterOptions.Performance": "Worker threads and the result cache.",
+	"LinterOptions.Plugins":     "Out-of-tree rules, built in or run as executables. Only read from the root config.",
+
+	"LinterRules.Use":          "Enables the linter.",
+	"LinterRules.Issues":       "Caps the number of issues reported.",
+	"LinterRules.Suppressions": "Comments that silence issues.",
+	"LinterRules.Patterns":     "Rules declared as Go expression patterns.",
+	"LinterRules.Analyzers":    "golang.org/x/tools/go/analysis passes, by name. Only read from the root config.",
+
+	"LinterRulesGroup.UseRecommended": "Enables the recommended rules on top of the ones configured.",
+
+	"GoFileOptions.Exclude":     "Globs of files to skip when walking directories, relative to the config file.",
+	"GoFileOptions.MaxFileSize": "Files larger than this many bytes are skipped.",
+	"GoFileOptions.Generated":   "How files with a \"Code generated ... DO NOT EDIT.\" header are linted.",
+	"GoFileOptions.Targets":     "GOOS/GOARCH/tags combinations to select files for; issues are merged.",
+
+	"OverrideOptions.Files": "Globs relative to the config file; ** matches any number of directories.",
+	"OverrideOptions.Rules": "Rules merged over the config's for the matching files. Use severity \"off\" to disable one.",
+
+	"PatternRuleOptions.Name":         "Rule name used in issues and suppressions; defaults to pattern-N, N being the place of the pattern in the list.",
+	"PatternRuleOptions.Files":        "Globs of the files the rule applies to, relative to the config file as in overrides.",
+	"PatternRuleOptions.Pattern":      "Go expression to match; $name matches any expression.",
+	"PatternRuleOptions.MinGoVersion": "Skips the rule in modules with an older go directive, e.g. \"1.21\".",
+
+	"PerformanceOptions.Threads":      "Number of packages analyzed at once.",
+	"PerformanceOptions.Caching":      "Reuses the results of unchanged packages.",
+	"PerformanceOptions.CacheMaxSize": "Bytes the cache may take before the least recently used entries are evicted; 0 for no limit. Defaults to 512 MiB.",
+	"PerformanceOptions.CacheDir":     "Directory the cache is kept in, in a serenity-lint-cache subdirectory, relative to the config file. SERENITY_CACHE_DIR takes precedence.",
+	"PerformanceOptions.RemoteCache":  "URL of an HTTP cache shared between machines, read with GET and written with PUT. SERENITY_REMOTE_CACHE takes precedence; SERENITY_REMOTE_CACHE_TOKEN is sent as a bearer token.",
+}
+
+// GenerateSchema derives the JSON Schema of config files from
+// rules.LinterOptions and the registered rules.
+func GenerateSchema() ([]byte, error) {
+	g := &schemaGenerator{defs: make(map[string]any, 32), rules: ruleDescriptors()}
+
+	root := g.object(reflect.TypeFor[rules.LinterOptions]())
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["title"] = "Serenity configuration"
+	root["$defs"] = g.defs
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, exception.InternalError("could not encode the config schema: %w", err)
+	}
+
+	return append(data, '\n'), nil
+}
+
+type schemaGenerator struct {
+	defs  map[string]any
+	rules map[string]*rules.Descriptor
+}
+
+// ref returns a reference to the definition of t, adding it the first time.
+// Struct types used by several options, like the rule options, are only
+// described once.
+func (g *schemaGenerator) ref(t reflect.Type) map[string]any {
+	if _, ok := g.defs[t.Name()]; !ok {
+		g.defs[t.Name()] = nil
+		g.defs[t.Name()] = g.object(t)
+	}
+
+	return map[string]any{"$ref": "#/$defs/" + t.Name()}
+}
+
+func (g *schemaGenerator) object(t reflect.Type) map[string]any {
+	properties := make(map[string]any, t.NumField())
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		name := jsonName(field)
+		if name == "" {
+			continue
+		}
+
+		properties[name] = g.field(t, field)
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func (g *schemaGenerator) field(owner reflect.Type, field reflect.StructField) map[string]any {
+	schema := g.value(field.Type, owner, field)
+
+	if desc := fieldDescriptions[owner.Name()+"."+field.Name]; desc != "" {
+		schema["description"] = desc
+	}
+
+	if owner.Name() == "LinterRulesGroup" && field.Name != "UseRecommended" {
+		schema["description"] = "Enables and configures the " + jsonName(field) + " rules."
+	}
+
+	if rule := g.rules[owner.Name()+"."+field.Name]; rule != nil {
+		schema["description"] = rule.Description
+
+		if defaults := ruleDefaults(rule); defaults != nil {
+			schema["default"] = defaults
+		}
+	}
+
+	if field.Name == "Use" && field.Type.Kind() == reflect.Bool && schema["description"] == nil {
+		schema["description"] = "Enables this section."
+	}
+
+	return schema
+}
+
+func (g *schemaGenerator) value(t reflect.Type, owner reflect.Type, field reflect.StructField) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return g.ref(t)
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.value(t.Elem(), nil, reflect.StructField{})}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": g.value(t.Elem(), owner, field)}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.String:
+		return stringSchema(owner, field)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return integerSchema(t, owner, field)
+	}
+
+	return map[string]any{}
+}
+
+func stringSchema(owner reflect.Type, field reflect.StructField) map[string]any {
+	schema := map[string]any{"type": "string"}
+
+	switch {
+	case field.Name == "Severity":
+		schema["enum"] = severities
+	case owner == reflect.TypeFor[rules.GoFileOptions]() && field.Name == "Generated":
+		schema["enum"] = rules.GeneratedModes
+	case owner == reflect.TypeFor[rules.AnyPatternBasedRule]() && field.Name == "Pattern":
+		schema["format"] = "regex"
+	}
+
+	return schema
+}
+
+func integerSchema(t reflect.Type, owner reflect.Type, field reflect.StructField) map[string]any {
+	schema := map[string]any{"type": "integer"}
+
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint64, reflect.Uint32:
+		schema["minimum"] = 0
+	case reflect.Uint8:
+		schema["minimum"], schema["maximum"] = 0, math.MaxUint8
+	case reflect.Uint16:
+		schema["minimum"], schema["maximum"] = 0, math.MaxUint16
+	}
+
+	if owner != nil {
+		if min, ok := minimums[owner.Name()+"."+field.Name]; ok {
+			schema["minimum"] = min
+		}
+	}
+
+	return schema
+}
+
+// ruleDescriptors indexes the registered rules by the struct and field
+// name of their option, e.g. "ComplexityRulesGroup.MaxFuncLines".
+func ruleDescriptors() map[string]*rules.Descriptor {
+	groups := reflect.TypeFor[rules.LinterRulesGroup]()
+	byGroup := make(map[string]reflect.Type, groups.NumField())
+
+	for i := range groups.NumField() {
+		field := groups.Field(i)
+		if field.Type.Kind() == reflect.Pointer && field.Type.Elem().Kind() == reflect.Struct {
+			byGroup[jsonName(field)] = field.Type.Elem()
+		}
+	}
+
+	out := make(map[string]*rules.Descriptor, 64)
+
+	for _, desc := range rules.Descriptors() {
+		group, ok := byGroup[desc.Group]
+		if !ok {
+			continue
+		}
+
+		for i := range group.NumField() {
+			if field := group.Field(i); jsonName(field) == desc.Key {
+				out[group.Name()+"."+field.Name] = desc
+			}
+		}
+	}
+
+	return out
+}
+
+// ruleDefaults returns the options a rule uses when the config leaves them
+// unset.
+func ruleDefaults(desc *rules.Descriptor) map[string]any {
+	data, err := json.Marshal(desc.Defaults)
+	if err != nil {
+		return nil
+	}
+
+	var defaults map[string]any
+	if json.Unmarshal(data, &defaults) != nil {
+		return nil
+	}
+
+	for key, value := range defaults {
+		if value == nil || value == "" {
+			delete(defaults, key)
+		}
+	}
+
+	if len(defaults) == 0 {
+		return nil
+	}
+
+	return defaults
+}
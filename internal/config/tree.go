@@ -0,0 +1,403 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/render"
+	"github.com/serenitysz/serenity/internal/rules"
+)
+
+// Tree resolves the config of every directory from the serenity.* files found
+// walking up from it. Each file is merged over the ones above it until a
+// file sets `root: true`. A tree loaded from an explicit path has that file
+// alone for every directory.
+type Tree struct {
+	root          *rules.LinterOptions
+	rootDir       string
+	explicit      bool
+	explicitFiles []string // The explicit file, when it exists
+
+	mu      sync.Mutex
+	files   map[string]map[string]any // parsed files by path
+	chains  map[string][]string       // config files by directory, nearest first
+	configs map[string]*rules.LinterOptions
+}
+
+// LoadTree loads the config at path, or the one for the working directory
+// when path is empty, and keeps the files it read for later lookups.
+func LoadTree(path string) (*Tree, error) {
+	t := &Tree{
+		files:   make(map[string]map[string]any, 4),
+		chains:  make(map[string][]string, 64),
+		configs: make(map[string]*rules.LinterOptions, 4),
+	}
+
+	if path == "" {
+		env, err := getFromEnv()
+		if err != nil {
+			return nil, err
+		}
+
+		path = env
+	}
+
+	if path != "" {
+		t.explicit = true
+
+		exists, err := Exists(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if exists {
+			t.explicitFiles = []string{path}
+			render.Debugf("using config %s", path)
+		} else {
+			render.Debugf("config %s not found, using the defaults", path)
+		}
+
+		root, err := t.merge(t.explicitFiles)
+		if err != nil {
+			return nil, err
+		}
+
+		t.root = root
+		t.rootDir, _ = filepath.Abs(filepath.Dir(path))
+
+		return t, nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, exception.InternalError("could not determine the current working directory: %w", err)
+	}
+
+	root, err := t.ForDir(wd)
+	if err != nil {
+		return nil, err
+	}
+
+	t.root = root
+	t.rootDir = t.BaseDir(wd)
+
+	if render.Enabled(render.LevelDebug) {
+		if files, _ := t.Files(wd); len(files) > 0 {
+			render.Debugf("using config %s", strings.Join(files, ", "))
+		} else {
+			render.Debugf("no config file found from %s, using the defaults", wd)
+		}
+	}
+
+	return t, nil
+}
+
+// Root returns the config of the working directory, or the explicit one.
+func (t *Tree) Root() *rules.LinterOptions {
+	return t.root
+}
+
+// ForDir returns the config that applies to dir. Directories governed by the
+// same files share one *LinterOptions.
+func (t *Tree) ForDir(dir string) (*rules.LinterOptions, error) {
+	if t.explicit {
+		return t.root, nil
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, exception.InternalError("could not resolve %q: %w", dir, err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	chain, err := t.chain(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.merge(chain)
+}
+
+// BaseDir returns the directory the overrides globs of dir's config are
+// relative to: that of the file setting them, or of the nearest config file.
+// Without any config file it is dir itself.
+func (t *Tree) BaseDir(dir string) string {
+	if t.explicit {
+		return t.rootDir
+	}
+
+	files, err := t.Files(dir)
+	if err != nil || len(files) == 0 {
+		abs, _ := filepath.Abs(dir)
+		return abs
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, file := range files {
+		if raw, err := t.read(file); err == nil && raw["overrides"] != nil {
+			return filepath.Dir(file)
+		}
+	}
+
+	return filepath.Dir(files[0])
+}
+
+// RootDir is the BaseDir of the root config.
+func (t *Tree) RootDir() string {
+	return t.rootDir
+}
+
+// Files returns the config files dir inherits from, nearest first. With an
+// explicit path that is the file alone.
+func (t *Tree) Files(dir string) ([]string, error) {
+	if t.explicit {
+		return t.explicitFiles, nil
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, exception.InternalError("could not resolve %q: %w", dir, err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.chain(abs)
+}
+
+// ConfigDirs returns the directories under roots with a config file of their
+// own, skipping vendor and .git like the linter's walk. With an explicit path
+// there are none.
+func (t *Tree) ConfigDirs(roots []string) []string {
+	if t.explicit {
+		return nil
+	}
+
+	var dirs []string
+
+	var walk func(dir string)
+	walk = func(dir string) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+
+		found := false
+		for _, entry := range entries {
+			found = found || (!entry.IsDir() && slices.Contains(CANDIDATES[:], entry.Name()))
+		}
+
+		if found {
+			dirs = append(dirs, dir)
+		}
+
+		for _, entry := range entries {
+			if name := entry.Name(); entry.IsDir() && name != "vendor" && name != ".git" {
+				walk(filepath.Join(dir, name))
+			}
+		}
+	}
+
+	for _, root := range roots {
+		walk(root)
+	}
+
+	return dirs
+}
+
+func (t *Tree) chain(dir string) ([]string, error) {
+	if chain, ok := t.chains[dir]; ok {
+		return chain, nil
+	}
+
+	var chain []string
+	inherit := true
+
+	if path, ok := configIn(dir); ok {
+		raw, err := t.read(path)
+		if err != nil {
+			return nil, err
+		}
+
+		chain = append(chain, path)
+		if isRoot, _ := raw["root"].(bool); isRoot {
+			inherit = false
+		}
+	}
+
+	if parent := filepath.Dir(dir); inherit && parent != dir {
+		parentChain, err := t.chain(parent)
+		if err != nil {
+			return nil, err
+		}
+
+		chain = append(chain, parentChain...)
+	}
+
+	t.chains[dir] = chain
+
+	return chain, nil
+}
+
+func (t *Tree) read(path string) (map[string]any, error) {
+	if raw, ok := t.files[path]; ok {
+		return raw, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, exception.InternalError("could not read config file %q: %w", path, err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	raw := make(map[string]any)
+
+	if err := unmarshalByExt(ext, data, &raw); err != nil {
+		if pos, ok := syntaxPosition(data, err); ok {
+			return nil, exception.InternalError("could not parse config file %s:%d:%d: %w", path, pos.line, pos.column, err)
+		}
+
+		return nil, exception.InternalError("could not parse config file %q: %w", path, err)
+	}
+
+	if err := problemsError(validateRaw(path, ext, data, raw)); err != nil {
+		return nil, err
+	}
+
+	resolvePaths(raw, filepath.Dir(path))
+	t.files[path] = raw
+	render.Tracef("read config %s", path)
+
+	return raw, nil
+}
+
+// merge builds the config of chain, applying the farthest file first.
+func (t *Tree) merge(chain []string) (*rules.LinterOptions, error) {
+	key := strings.Join(chain, "\x00")
+	if cfg, ok := t.configs[key]; ok {
+		return cfg, nil
+	}
+
+	cfg, err := t.decode(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	ApplyRecommended(cfg)
+	t.configs[key] = cfg
+
+	return cfg, nil
+}
+
+// decode builds the config of chain before presets apply.
+func (t *Tree) decode(chain []string) (*rules.LinterOptions, error) {
+	cfg := GenDefaultConfig(new(bool))
+
+	if len(chain) > 0 {
+		merged := make(map[string]any)
+
+		for i := len(chain) - 1; i >= 0; i-- {
+			raw, err := t.read(chain[i])
+			if err != nil {
+				return nil, err
+			}
+
+			merged = mergeRaw(merged, raw)
+		}
+
+		data, err := json.Marshal(merged)
+		if err != nil {
+			return nil, exception.InternalError("could not merge config files %s: %w", strings.Join(chain, ", "), err)
+		}
+
+		cfg = &rules.LinterOptions{}
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, exception.InternalError("could not merge config files %s: %w", strings.Join(chain, ", "), err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// resolvePaths makes the paths set in raw, read from a config file in dir,
+// absolute, so they keep pointing to the same place once merged.
+func resolvePaths(raw map[string]any, dir string) {
+	perf, ok := asMap(raw["performance"])
+	if !ok {
+		return
+	}
+
+	if path, ok := perf["cacheDir"].(string); ok && path != "" && !filepath.IsAbs(path) {
+		abs, err := filepath.Abs(filepath.Join(dir, path))
+		if err != nil {
+			return
+		}
+
+		perf["cacheDir"] = abs
+		raw["performance"] = perf
+	}
+}
+
+// mergeRaw merges child over parent: objects merge key by key, anything else
+// in child replaces the parent's value.
+func mergeRaw(parent, child map[string]any) map[string]any {
+	out := make(map[string]any, len(parent)+len(child))
+
+	for key, value := range parent {
+		out[key] = value
+	}
+
+	for key, value := range child {
+		childMap, childIsMap := asMap(value)
+		parentMap, parentIsMap := asMap(out[key])
+
+		if childIsMap && parentIsMap {
+			out[key] = mergeRaw(parentMap, childMap)
+			continue
+		}
+
+		out[key] = value
+	}
+
+	return out
+}
+
+func asMap(value any) (map[string]any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		return v, true
+	case map[any]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			s, ok := key.(string)
+			if !ok {
+				return nil, false
+			}
+			out[s] = item
+		}
+		return out, true
+	}
+
+	return nil, false
+}
+
+func configIn(dir string) (string, bool) {
+	for _, name := range CANDIDATES {
+		path := filepath.Join(dir, name)
+
+		if ok, err := Exists(path); err == nil && ok {
+			return path, true
+		}
+	}
+
+	return "", false
+}
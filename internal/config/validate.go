@@ -0,0 +1,464 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/render"
+	"github.com/serenitysz/serenity/internal/rules"
+	"github.com/serenitysz/serenity/internal/utils"
+)
+
+// Problem is something wrong with a config file, at the key it concerns.
+type Problem struct {
+	File    string
+	Line    int
+	Column  int
+	Key     string // e.g. linter.rules.complexity.maxFuncLines.max
+	Message string
+	Warning bool // The file still loads, e.g. with a deprecated key
+}
+
+func (p Problem) String() string {
+	var b strings.Builder
+
+	b.WriteString(p.File)
+	if p.Line > 0 {
+		b.WriteString(":" + strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column))
+	}
+	b.WriteString(": ")
+	if p.Key != "" {
+		b.WriteString(p.Key + ": ")
+	}
+	b.WriteString(p.Message)
+
+	return b.String()
+}
+
+// Validate checks the config file at path against the options Serenity
+// knows: unknown keys, values of the wrong type or out of range, and
+// patterns that do not compile. Only an unreadable file is an error.
+func Validate(path string) ([]Problem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, exception.InternalError("could not read config file %q: %w", path, err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	raw := make(map[string]any)
+
+	if err := unmarshalByExt(ext, data, &raw); err != nil {
+		if !isSupportedExt(ext) {
+			return nil, err
+		}
+
+		problem := Problem{File: path, Message: err.Error()}
+		if pos, ok := syntaxPosition(data, err); ok {
+			problem.Line, problem.Column = pos.line, pos.column
+		}
+
+		return []Problem{problem}, nil
+	}
+
+	problems := validateRaw(path, ext, data, raw)
+	if problem, ok := checkSchemaRef(path, raw); ok {
+		pos := positionsByExt(ext, data).lookup([]string{"$schema"})
+		problem.Line, problem.Column = pos.line, pos.column
+		problems = append(problems, problem)
+	}
+
+	return problems, nil
+}
+
+// checkSchemaRef warns when the $schema of a config file is not the schema
+// of this build, which `config schema --output` writes next to it.
+func checkSchemaRef(path string, raw map[string]any) (Problem, bool) {
+	ref, _ := raw["$schema"].(string)
+	if ref == "" {
+		return Problem{}, false
+	}
+
+	problem := Problem{File: path, Key: "$schema", Warning: true}
+	fix := "run `serenity config schema --output " + SchemaFile + "` and point $schema to ./" + SchemaFile
+
+	if strings.Contains(ref, "://") {
+		problem.Message = fmt.Sprintf("%s may not match this version of serenity; %s", ref, fix)
+		return problem, true
+	}
+
+	local := filepath.Join(filepath.Dir(path), filepath.FromSlash(ref))
+	data, err := os.ReadFile(local)
+
+	switch {
+	case err != nil:
+		problem.Message = fmt.Sprintf("could not read %s: %v; %s", ref, err, fix)
+	case string(data) != string(Schema()):
+		problem.Message = fmt.Sprintf("%s is out of date for this version of serenity; %s", ref, fix)
+	default:
+		return Problem{}, false
+	}
+
+	return problem, true
+}
+
+func isSupportedExt(ext string) bool {
+	switch ext {
+	case ".json", ".toml", ".yml", ".yaml":
+		return true
+	}
+
+	return false
+}
+
+// validateRaw checks a decoded file and renames deprecated keys in raw to
+// their current names. Positions are only looked up for files with problems.
+func validateRaw(path, ext string, data []byte, raw map[string]any) []Problem {
+	v := &validator{}
+	v.object(raw, reflect.TypeFor[rules.LinterOptions](), nil)
+
+	if len(v.found) == 0 {
+		return nil
+	}
+
+	index := positionsByExt(ext, data)
+	problems := make([]Problem, 0, len(v.found))
+
+	for _, f := range v.found {
+		pos := index.lookup(f.path)
+		problems = append(problems, Problem{
+			File:    path,
+			Line:    pos.line,
+			Column:  pos.column,
+			Key:     keyPath(f.path),
+			Message: f.message,
+			Warning: f.warning,
+		})
+	}
+
+	return problems
+}
+
+// problemsError reports the problems of a file being loaded, or nil when
+// there are only warnings, which it prints.
+func problemsError(problems []Problem) error {
+	var errs []string
+
+	for _, p := range problems {
+		if p.Warning {
+			render.Warnf("%s", p)
+			continue
+		}
+
+		errs = append(errs, p.String())
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return exception.InternalError("invalid config:\n  %s", strings.Join(errs, "\n  "))
+}
+
+// renamedKeys are keys accepted under an old name, by the current one.
+var renamedKeys = map[string]string{
+	"ununsedParams": "unusedParams",
+}
+
+var severities = []string{"error", "warn", "info", rules.SeverityOff}
+
+// minimums are lower bounds for numbers whose type allows values that make
+// no sense, like a zero max or a negative size, by struct and field name.
+var minimums = map[string]int64{
+	"AnyMaxValueBasedRule.Max":                1,
+	"ReceiverNamesRule.MaxSize":               1,
+	"AmbiguousReturnsRule.MaxUnnamedSameType": 1,
+	"PerformanceOptions.CacheMaxSize":         0,
+}
+
+type finding struct {
+	path    []string
+	message string
+	warning bool
+}
+
+type validator struct {
+	found []finding
+}
+
+func (v *validator) report(path []string, format string, args ...any) {
+	v.found = append(v.found, finding{path: path, message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) value(value any, t reflect.Type, path []string, field reflect.StructField, owner reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if value == nil {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		v.object(value, t, path)
+	case reflect.Map:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			v.report(path, "expected an object, got %s", describe(value))
+			return
+		}
+
+		for key, item := range obj {
+			v.value(item, t.Elem(), append(path[:len(path):len(path)], key), reflect.StructField{}, nil)
+		}
+	case reflect.Slice:
+		items, ok := value.([]any)
+		if !ok {
+			v.report(path, "expected an array, got %s", describe(value))
+			return
+		}
+
+		for i, item := range items {
+			v.value(item, t.Elem(), append(path[:len(path):len(path)], indexPart(i)), field, owner)
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			v.report(path, "expected a boolean, got %s", describe(value))
+		}
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			v.report(path, "expected a string, got %s", describe(value))
+			return
+		}
+
+		v.checkString(s, path, field, owner)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := integer(value)
+		if !ok {
+			v.report(path, "expected an integer, got %s", describe(value))
+			return
+		}
+
+		v.checkInteger(n, t, path, field, owner)
+	}
+}
+
+func (v *validator) object(value any, t reflect.Type, path []string) {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		v.report(path, "expected an object, got %s", describe(value))
+		return
+	}
+
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if name := jsonName(field); name != "" {
+			fields[name] = field
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		child := append(path[:len(path):len(path)], key)
+
+		field, ok := fields[key]
+		if !ok {
+			if renamed, deprecated := renamedKeys[key]; deprecated {
+				if _, known := fields[renamed]; known {
+					v.found = append(v.found, finding{path: slices.Clone(child), message: fmt.Sprintf("%q is deprecated; use %q", key, renamed), warning: true})
+
+					value := obj[key]
+					delete(obj, key)
+
+					// The current name wins, and is checked on its own.
+					if _, taken := obj[renamed]; taken {
+						continue
+					}
+					obj[renamed] = value
+
+					key, field = renamed, fields[renamed]
+					child[len(child)-1] = renamed
+					ok = true
+				}
+			}
+		}
+
+		if !ok {
+			if suggestion := closest(key, fields); suggestion != "" {
+				v.report(child, "unknown field %q; did you mean %q?", key, suggestion)
+			} else {
+				v.report(child, "unknown field %q", key)
+			}
+
+			continue
+		}
+
+		v.value(obj[key], field.Type, child, field, t)
+	}
+}
+
+func (v *validator) checkString(s string, path []string, field reflect.StructField, owner reflect.Type) {
+	switch {
+	case field.Name == "Severity" && s != "":
+		if !slices.Contains(severities, s) {
+			v.report(path, "invalid severity %q; expected one of %s", s, strings.Join(severities, ", "))
+		}
+	case owner == reflect.TypeFor[rules.GoFileOptions]() && field.Name == "Generated":
+		if !slices.Contains(rules.GeneratedModes, s) {
+			v.report(path, "invalid mode %q; expected one of %s", s, strings.Join(rules.GeneratedModes, ", "))
+		}
+	case owner == reflect.TypeFor[rules.AnyPatternBasedRule]() && field.Name == "Pattern":
+		if _, err := regexp.Compile(s); err != nil {
+			v.report(path, "invalid pattern: %v", err)
+		}
+	case owner == reflect.TypeFor[rules.OverrideOptions]() && field.Name == "Files":
+		if !utils.ValidGlob(s) {
+			v.report(path, "invalid glob %q", s)
+		}
+	}
+}
+
+func (v *validator) checkInteger(n float64, t reflect.Type, path []string, field reflect.StructField, owner reflect.Type) {
+	low, high := float64(math.MinInt64), float64(math.MaxInt64)
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int64:
+	case reflect.Int8:
+		low, high = math.MinInt8, math.MaxInt8
+	case reflect.Int16:
+		low, high = math.MinInt16, math.MaxInt16
+	case reflect.Int32:
+		low, high = math.MinInt32, math.MaxInt32
+	case reflect.Uint8:
+		low, high = 0, math.MaxUint8
+	case reflect.Uint16:
+		low, high = 0, math.MaxUint16
+	case reflect.Uint32:
+		low, high = 0, math.MaxUint32
+	case reflect.Uint, reflect.Uint64:
+		low, high = 0, math.MaxUint64
+	}
+
+	if owner != nil {
+		if min, ok := minimums[owner.Name()+"."+field.Name]; ok {
+			low = max(low, float64(min))
+		}
+	}
+
+	switch {
+	case n < low:
+		v.report(path, "must be at least %s, got %s", formatNumber(low), formatNumber(n))
+	case n > high:
+		v.report(path, "must be at most %s, got %s", formatNumber(high), formatNumber(n))
+	}
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+
+	return name
+}
+
+// integer returns value as a number when it is a whole one. Each decoder
+// has its own numeric types.
+func integer(value any) (float64, bool) {
+	rv := reflect.ValueOf(value)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		return f, f == math.Trunc(f)
+	}
+
+	return 0, false
+}
+
+func describe(value any) string {
+	switch v := value.(type) {
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	case string:
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	if n, ok := integer(value); ok {
+		return formatNumber(n)
+	}
+
+	return fmt.Sprint(value)
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// closest returns the known field nearest to key, if any is close enough to
+// be a typo of it.
+func closest(key string, fields map[string]reflect.StructField) string {
+	best, bestDistance := "", 3
+
+	for name := range fields {
+		if d := editDistance(strings.ToLower(key), strings.ToLower(name)); d < bestDistance || d == bestDistance && name < best {
+			best, bestDistance = name, d
+		}
+	}
+
+	if bestDistance > 2 {
+		return ""
+	}
+
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
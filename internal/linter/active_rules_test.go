@@ -0,0 +1,84 @@
+package linter
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/serenitysz/serenity/internal/rules"
+)
+
+// dispatchCountRule records how many nodes it was dispatched to.
+type dispatchCountRule struct {
+	targets []ast.Node
+	calls   int
+}
+
+func (r *dispatchCountRule) Name() string { return "dispatch-count" }
+
+func (r *dispatchCountRule) Targets() []ast.Node { return r.targets }
+
+func (r *dispatchCountRule) Run(runner *rules.Runner, node ast.Node) { r.calls++ }
+
+func TestActiveRules_DispatchesDeclaredTargets(t *testing.T) {
+	t.Parallel()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "sample.go", `package sample
+
+func pick(ok bool) int {
+	if ok {
+		return 1
+	}
+	switch {
+	case ok:
+		if !ok {
+			return 2
+		}
+	}
+	return 0
+}
+`, 0)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	rule := &dispatchCountRule{targets: []ast.Node{(*ast.IfStmt)(nil), (*ast.SwitchStmt)(nil)}}
+	var active ActiveRules
+	active.Add(rule)
+
+	runner := rules.Runner{File: file}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n != nil {
+			active.Run(&runner, n)
+		}
+		return true
+	})
+
+	if rule.calls != 3 {
+		t.Fatalf("expected rule to run on 2 if statements and 1 switch, got %d calls", rule.calls)
+	}
+}
+
+func TestActiveRules_DispatchDoesNotAllocate(t *testing.T) {
+	nodes := prepareDispatchNodes(t)
+	rule := &dispatchCountRule{targets: dispatchBenchmarkTargets}
+
+	var active ActiveRules
+	active.Add(rule)
+	runner := rules.Runner{}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, node := range nodes {
+			active.Run(&runner, node)
+		}
+	})
+
+	if allocs != 0 {
+		t.Fatalf("expected dispatch to be allocation free, got %.1f allocs per run", allocs)
+	}
+
+	if rule.calls == 0 {
+		t.Fatal("expected the rule to be dispatched")
+	}
+}
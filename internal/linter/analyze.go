@@ -4,9 +4,10 @@ import (
 	"bytes"
 	"go/ast"
 	"go/format"
-	"os"
+	"time"
 
 	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/render"
 	"github.com/serenitysz/serenity/internal/rules"
 )
 
@@ -18,11 +19,21 @@ type visitFrame struct {
 }
 
 func (l *Linter) Analyze(params AnalysisParams) ([]rules.Issue, error) {
+	if params.config == nil {
+		params.config = l.rootScope()
+	}
+
+	start := time.Now()
 	constCandidates := l.buildConstCandidates(params)
+	l.Stats.since(PhaseConstAnalysis, start)
 
 	estimatedIssues := len(params.pkgFiles) * 8
 	allIssues := make([]rules.Issue, 0, estimatedIssues)
 
+	start = time.Now()
+	findings := l.runAnalyzers(params)
+	l.Stats.since(PhaseAnalyzers, start)
+
 	for i, file := range params.pkgFiles {
 		filePath := params.pkgPaths[i]
 		issues := make([]rules.Issue, 0, FINAL_FILE_ISSUE_CAP)
@@ -30,8 +41,10 @@ func (l *Linter) Analyze(params AnalysisParams) ([]rules.Issue, error) {
 
 		runner := rules.Runner{
 			File:            file,
+			Src:             params.sources[filePath],
+			RelPath:         relativeTo(params.config.baseDir, filePath),
 			Fset:            params.fset,
-			Cfg:             l.Config,
+			Cfg:             params.config.config,
 			Unsafe:          l.Unsafe,
 			Issues:          &issues,
 			IssuesCount:     new(uint16),
@@ -42,9 +55,33 @@ func (l *Linter) Analyze(params AnalysisParams) ([]rules.Issue, error) {
 			},
 			Suppressions: suppressions,
 			MaxIssues:    params.maxIssues,
+			Facts:        params.facts,
+			Module:       params.module,
 		}
 
-		l.runFile(&runner, file, params.rules)
+		active := params.config.rulesFor(filePath, file).forModule(params.module)
+		l.runFile(&runner, file, active)
+
+		if fileFindings := findings[filePath]; len(fileFindings) > 0 {
+			// Analyzer edits are offsets into the source as read, which
+			// fixes applied to the syntax tree have moved; they are left for
+			// the next run.
+			switch {
+			case !params.autofix:
+			case runner.Modified:
+				if skipped := skippedAnalyzerFixes(fileFindings, suppressions); skipped > 0 {
+					render.Warnf("%s  %d analyzer fixes not applied as rules rewrote the file; run again to apply them", filePath, skipped)
+				}
+			default:
+				if err := l.applyAnalyzerFixes(filePath, params.sources[filePath], params.fset, fileFindings, suppressions); err != nil {
+					return allIssues, err
+				}
+			}
+
+			for _, finding := range fileFindings {
+				issues = append(issues, finding.Issue)
+			}
+		}
 
 		unusedWarnings := rules.CheckUnusedSuppressions(filePath, issues, suppressions)
 
@@ -52,13 +89,20 @@ func (l *Linter) Analyze(params AnalysisParams) ([]rules.Issue, error) {
 		issues = append(issues, unusedWarnings...)
 		allIssues = append(allIssues, issues...)
 
+		if render.Tracing() {
+			render.Tracef("%s  %d rules, %d issues", filePath, active.count(), len(issues))
+		}
+
 		if runner.Modified {
 			var buf bytes.Buffer
 
 			if err := format.Node(&buf, params.fset, file); err != nil {
 				return allIssues, exception.InternalError("could not format %q after applying fixes: %w", filePath, err)
 			}
-			if err := os.WriteFile(filePath, buf.Bytes(), DEFAULT_FILE_MODE); err != nil {
+			if err := l.Journal.Record(filePath); err != nil {
+				return allIssues, exception.InternalError("could not back up %q before applying fixes: %w", filePath, err)
+			}
+			if err := writeFileAtomic(filePath, buf.Bytes()); err != nil {
 				return allIssues, exception.InternalError("could not write %q after applying fixes: %w", filePath, err)
 			}
 		}
@@ -72,6 +116,13 @@ func (l *Linter) runFile(runner *rules.Runner, file *ast.File, active *ActiveRul
 		return
 	}
 
+	var timings ruleTimings
+	if l.Stats != nil {
+		timings = make(ruleTimings, 32)
+		defer l.Stats.addRules(timings)
+		defer l.Stats.since(PhaseRules, time.Now())
+	}
+
 	stack := make([]visitFrame, 0, 64)
 	nodeStack := make([]ast.Node, 0, 64)
 
@@ -95,12 +146,17 @@ func (l *Linter) runFile(runner *rules.Runner, file *ast.File, active *ActiveRul
 		}
 
 		runner.Parent = lastNode(nodeStack)
+		runner.Ancestors = nodeStack
 
 		frame := applyTraversalState(runner, n)
 		stack = append(stack, frame)
 		nodeStack = append(nodeStack, n)
 
-		active.Run(runner, n)
+		if timings != nil {
+			active.runTimed(runner, n, timings)
+		} else {
+			active.Run(runner, n)
+		}
 
 		if runner.ReachedMax() || (runner.ShouldStop != nil && runner.ShouldStop()) {
 			stack = stack[:len(stack)-1]
@@ -168,6 +224,7 @@ func functionContextForDecl(fn *ast.FuncDecl) *rules.FunctionContext {
 	return &rules.FunctionContext{
 		Name:            name,
 		HasNamedResults: hasNamedResults(fn.Type),
+		Node:            fn,
 	}
 }
 
@@ -175,6 +232,7 @@ func functionContextForLit(fn *ast.FuncLit) *rules.FunctionContext {
 	return &rules.FunctionContext{
 		Name:            "anonymous",
 		HasNamedResults: hasNamedResults(fn.Type),
+		Node:            fn,
 	}
 }
 
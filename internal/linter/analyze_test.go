@@ -1,15 +1,28 @@
 package linter
 
 import (
+	"errors"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/serenitysz/serenity/internal/config"
+	"github.com/serenitysz/serenity/internal/plugin"
 	"github.com/serenitysz/serenity/internal/rules"
 	"github.com/serenitysz/serenity/internal/utils"
+	"github.com/serenitysz/serenity/pkg/serenity/rule"
+	"golang.org/x/tools/go/analysis"
 )
 
 func TestProcessPath_ContextAwareRules(t *testing.T) {
@@ -414,10 +427,7 @@ var report = 7
 		t.Fatalf("probe cache path: %v", err)
 	}
 
-	entryPath, err := l.Cache.entryPath(probes)
-	if err != nil {
-		t.Fatalf("resolve cache entry path: %v", err)
-	}
+	entryPath := l.Cache.local.(*localCacheBackend).path(l.Cache.entryKey(probes) + ".bin")
 
 	before, err := os.Stat(entryPath)
 	if err != nil {
@@ -737,6 +747,174 @@ func Handle(name string, ctx context.Context, count int) {
 	}
 }
 
+func TestProcessPath_WritePreservesFileModeAndJournalRestores(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sample.go")
+
+	src := "package sample\n\nimport fmt \"fmt\"\n\nfunc handle() { _ = fmt.Sprintf(\"x\") }\n"
+
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use: true,
+			Rules: rules.LinterRulesGroup{
+				Imports: &rules.ImportRulesGroup{
+					Use:                  true,
+					RedundantImportAlias: &rules.LinterBaseRule{Severity: "warn"},
+				},
+			},
+			Issues: &rules.LinterIssuesOptions{},
+		},
+	}
+
+	journalDir := filepath.Join(t.TempDir(), "journal")
+	journal, err := OpenJournal(journalDir)
+	if err != nil {
+		t.Fatalf("open journal: %v", err)
+	}
+
+	l := New(true, false, cfg, 0, 0)
+	l.Journal = journal
+
+	if _, err := l.ProcessPath(path); err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	if err := journal.Close(); err != nil {
+		t.Fatalf("close journal: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat rewritten file: %v", err)
+	}
+
+	if got := info.Mode().Perm(); got != 0o600 {
+		t.Fatalf("expected rewritten file to keep mode 0600, got %v", got)
+	}
+
+	rewritten, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read rewritten file: %v", err)
+	}
+
+	if string(rewritten) == src {
+		t.Fatal("expected --write to rewrite the file")
+	}
+
+	restored, err := RestoreJournal(journalDir)
+	if err != nil {
+		t.Fatalf("restore journal: %v", err)
+	}
+
+	if restored != 1 {
+		t.Fatalf("expected 1 restored file, got %d", restored)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read restored file: %v", err)
+	}
+
+	if string(data) != src {
+		t.Fatalf("expected original source after undo, got:\n%s", string(data))
+	}
+
+	if _, err := RestoreJournal(journalDir); !errors.Is(err, ErrNoJournal) {
+		t.Fatalf("expected journal to be consumed by restore, got %v", err)
+	}
+}
+
+func TestProcessPath_NolintCompatSuppressions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sample.go")
+
+	src := `package sample
+
+func handle() int {
+	c := 44 //nolint:errcheck
+	a := 42 //nolint:mnd // legacy value
+
+	b := 43 //nolint
+
+	//lint:ignore no-magic-numbers imported from staticcheck
+	d := 45
+
+	e := 1 //nolint:gomnd
+	return a + b + c + d + e
+}
+`
+
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use: true,
+			Rules: rules.LinterRulesGroup{
+				BestPractices: &rules.BestPracticesRulesGroup{
+					Use:            true,
+					NoMagicNumbers: &rules.LinterBaseRule{Severity: "warn"},
+				},
+			},
+			Issues: &rules.LinterIssuesOptions{},
+			Suppressions: &rules.SuppressionOptions{
+				Use:    true,
+				Compat: utils.Ptr(true),
+			},
+		},
+	}
+
+	l := New(false, false, cfg, 0, 0)
+	issues, err := l.ProcessPath(path)
+	if err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	magic := make([]uint32, 0, 1)
+	unused := make([]uint32, 0, 1)
+
+	for _, issue := range issues {
+		switch issue.ID {
+		case rules.NoMagicNumbersID:
+			magic = append(magic, issue.Line)
+		case rules.UnusedSuppressionID:
+			unused = append(unused, issue.Line)
+		}
+	}
+
+	if len(magic) != 1 || magic[0] != 4 {
+		t.Fatalf("expected only the errcheck-suppressed line to be reported, got lines %v", magic)
+	}
+
+	if len(unused) != 1 || unused[0] != 12 {
+		t.Fatalf("expected the stale gomnd directive to be reported as unused, got lines %v", unused)
+	}
+
+	l = New(false, false, &rules.LinterOptions{Linter: rules.LinterRules{
+		Use:    true,
+		Rules:  cfg.Linter.Rules,
+		Issues: &rules.LinterIssuesOptions{},
+	}}, 0, 0)
+
+	issues, err = l.ProcessPath(path)
+	if err != nil {
+		t.Fatalf("ProcessPath without compat failed: %v", err)
+	}
+
+	if got := countIssuesByID(issues, rules.NoMagicNumbersID); got != 4 {
+		t.Fatalf("expected nolint directives to be ignored without compat mode, got %d issues", got)
+	}
+}
+
 func countIssuesByID(issues []rules.Issue, id uint16) int {
 	count := 0
 
@@ -748,3 +926,1903 @@ func countIssuesByID(issues []rules.Issue, id uint16) int {
 
 	return count
 }
+
+func TestProcessPath_RangeAndScopeSuppressions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sample.go")
+
+	src := `package sample
+
+// @serenity-ignore no-magic-numbers: lookup table
+func table() int {
+	return 40 + 41
+}
+
+func ranged() int {
+	// @serenity-ignore-start no-magic-numbers, no-bare-returns: generated values
+	a := 42
+	b := 43
+	// @serenity-ignore-end
+	c := 44 // @serenity-ignore no-bare-returns, no-magic-numbers
+
+	d := 45
+	return a + b + c + d
+}
+
+// @serenity-ignore-start no-magic-numbers
+func open() int {
+	return 46
+}
+`
+
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use: true,
+			Rules: rules.LinterRulesGroup{
+				BestPractices: &rules.BestPracticesRulesGroup{
+					Use:            true,
+					NoMagicNumbers: &rules.LinterBaseRule{Severity: "warn"},
+				},
+			},
+			Issues: &rules.LinterIssuesOptions{},
+		},
+	}
+
+	l := New(false, false, cfg, 0, 0)
+	issues, err := l.ProcessPath(path)
+	if err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	magic := make([]uint32, 0, 2)
+	unused := make([]uint32, 0, 2)
+	unbalanced := make([]uint32, 0, 1)
+
+	for _, issue := range issues {
+		switch issue.ID {
+		case rules.NoMagicNumbersID:
+			magic = append(magic, issue.Line)
+		case rules.UnusedSuppressionID:
+			unused = append(unused, issue.Line)
+		case rules.UnbalancedSuppressionRangeID:
+			unbalanced = append(unbalanced, issue.Line)
+		}
+	}
+
+	if len(magic) != 2 || magic[0] != 15 || magic[1] != 21 {
+		t.Fatalf("expected magic numbers only outside suppressed scopes, got lines %v", magic)
+	}
+
+	if len(unused) != 2 || unused[0] != 9 || unused[1] != 13 {
+		t.Fatalf("expected the unmatched no-bare-returns entries to be reported as unused, got lines %v", unused)
+	}
+
+	if len(unbalanced) != 1 || unbalanced[0] != 19 {
+		t.Fatalf("expected the unclosed range to be reported, got lines %v", unbalanced)
+	}
+}
+
+func TestProcessPath_ExpiringSuppressionsAndRequiredReasons(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sample.go")
+
+	src := `package sample
+
+func handle() int {
+	a := 42 // @serenity-ignore no-magic-numbers until=2000-01-01: migrate later
+
+	b := 43 // @serenity-ignore no-magic-numbers until=2999-12-31: migrate later
+
+	c := 44 // @serenity-ignore no-magic-numbers
+	return a + b + c
+}
+`
+
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use: true,
+			Rules: rules.LinterRulesGroup{
+				BestPractices: &rules.BestPracticesRulesGroup{
+					Use:            true,
+					NoMagicNumbers: &rules.LinterBaseRule{Severity: "warn"},
+				},
+			},
+			Issues: &rules.LinterIssuesOptions{},
+			Suppressions: &rules.SuppressionOptions{
+				Use:           true,
+				RequireReason: utils.Ptr(true),
+			},
+		},
+	}
+
+	l := New(false, false, cfg, 0, 0)
+	issues, err := l.ProcessPath(path)
+	if err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	lines := map[uint16][]uint32{}
+	for _, issue := range issues {
+		lines[issue.ID] = append(lines[issue.ID], issue.Line)
+	}
+
+	if got := lines[rules.NoMagicNumbersID]; len(got) != 1 || got[0] != 4 {
+		t.Fatalf("expected only the expired suppression to let its issue through, got lines %v", got)
+	}
+
+	if got := lines[rules.ExpiredSuppressionID]; len(got) != 1 || got[0] != 4 {
+		t.Fatalf("expected one expired-suppression diagnostic, got lines %v", got)
+	}
+
+	if got := lines[rules.MissingSuppressionReasonID]; len(got) != 1 || got[0] != 8 {
+		t.Fatalf("expected one missing-suppression-reason diagnostic, got lines %v", got)
+	}
+
+	if got := lines[rules.UnusedSuppressionID]; len(got) != 0 {
+		t.Fatalf("expected no unused suppressions, got lines %v", got)
+	}
+}
+
+func TestProcessPath_CachesExpiringSuppressionsUntilTheyExpire(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SERENITY_CACHE_DIR", t.TempDir())
+
+	path := filepath.Join(dir, "expiring.go")
+	src := "package sample\n\nvar a = 42 // @serenity-ignore no-magic-numbers until=2999-12-31: later\n"
+
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use: true,
+			Rules: rules.LinterRulesGroup{
+				BestPractices: &rules.BestPracticesRulesGroup{
+					Use:            true,
+					NoMagicNumbers: &rules.LinterBaseRule{Severity: "warn"},
+				},
+			},
+			Issues: &rules.LinterIssuesOptions{},
+		},
+		Performance: &rules.PerformanceOptions{
+			Use:     true,
+			Caching: utils.Ptr(true),
+		},
+	}
+
+	run := func() (*Linter, []rules.Issue) {
+		l := New(false, false, cfg, 0, 0)
+
+		issues, err := l.ProcessPath(path)
+		if err != nil {
+			t.Fatalf("ProcessPath failed: %v", err)
+		}
+
+		return l, issues
+	}
+
+	if l, issues := run(); len(issues) != 0 || l.Cache.run.writes.Load() != 1 {
+		t.Fatalf("expected the suppressed file to be cached, got %v and %d writes", issues, l.Cache.run.writes.Load())
+	}
+
+	l, issues := run()
+	if len(issues) != 0 || l.Cache.run.hits.Load() != 1 {
+		t.Fatalf("expected the second run to reuse the entry, got %v and %d hits", issues, l.Cache.run.hits.Load())
+	}
+
+	// The state of the cache once the date saved beside the entry passes.
+	inputs, err := probePackageInputs([]string{path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := l.rootScope().cacheFor(l.Cache).withModule(l.Modules.lookup(dir))
+	if err := base.local.Put(base.expiryKey(inputs), []byte("2000-01-01")); err != nil {
+		t.Fatal(err)
+	}
+
+	if l, _ := run(); l.Cache.run.hits.Load() != 0 || l.Cache.run.writes.Load() != 1 {
+		t.Fatalf("expected a passed date to miss and relint, got %d hits", l.Cache.run.hits.Load())
+	}
+}
+
+func TestProcessPath_ReportsInvalidSuppressionDates(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "sample.go")
+	src := "package sample\n\nvar a = 42 // @serenity-ignore no-magic-numbers until=31/12/2999: later\n"
+
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use: true,
+			Rules: rules.LinterRulesGroup{
+				BestPractices: &rules.BestPracticesRulesGroup{
+					Use:            true,
+					NoMagicNumbers: &rules.LinterBaseRule{Severity: "warn"},
+				},
+			},
+			Issues: &rules.LinterIssuesOptions{},
+		},
+	}
+
+	issues, err := New(false, false, cfg, 0, 0).ProcessPath(path)
+	if err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, rules.IssueRuleName(issue)+": "+rules.FormatMessage(issue))
+	}
+	sort.Strings(got)
+
+	// The suppression applies nothing, so the issue it meant to hide shows.
+	want := `invalid-suppression: suppression for rule "no-magic-numbers" has until=31/12/2999, which is not a YYYY-MM-DD date`
+	if len(got) != 2 || got[0] != want || !strings.HasPrefix(got[1], "no-magic-numbers: ") {
+		t.Fatalf("issues = %q, want %q and the no-magic-numbers issue", got, want)
+	}
+}
+
+func TestProcessPath_GeneratedFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	generated := `// Code generated by stringer; DO NOT EDIT.
+
+package sample
+
+import . "strings"
+
+func lookup() int {
+	_ = ToUpper
+	return 42
+}
+`
+
+	handwritten := `package sample
+
+func handle() int {
+	return 43
+}
+`
+
+	if err := os.WriteFile(filepath.Join(dir, "kind_string.go"), []byte(generated), 0o644); err != nil {
+		t.Fatalf("write generated fixture: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "handle.go"), []byte(handwritten), 0o644); err != nil {
+		t.Fatalf("write handwritten fixture: %v", err)
+	}
+
+	newConfig := func(mode string) *rules.LinterOptions {
+		cfg := &rules.LinterOptions{
+			Linter: rules.LinterRules{
+				Use: true,
+				Rules: rules.LinterRulesGroup{
+					BestPractices: &rules.BestPracticesRulesGroup{
+						Use:            true,
+						NoMagicNumbers: &rules.LinterBaseRule{Severity: "warn"},
+					},
+					Imports: &rules.ImportRulesGroup{
+						Use:          true,
+						NoDotImports: &rules.LinterBaseRule{Severity: "error"},
+					},
+				},
+				Issues: &rules.LinterIssuesOptions{},
+			},
+		}
+
+		if mode != "" {
+			cfg.File = &rules.GoFileOptions{Generated: utils.Ptr(mode)}
+		}
+
+		return cfg
+	}
+
+	cases := []struct {
+		mode       string
+		magic      int
+		dotImports int
+	}{
+		{mode: "", magic: 1, dotImports: 0},
+		{mode: rules.GeneratedLint, magic: 2, dotImports: 1},
+		{mode: rules.GeneratedLintLight, magic: 1, dotImports: 1},
+		{mode: "lnt", magic: 1, dotImports: 0},
+	}
+
+	for _, tc := range cases {
+		issues, err := New(false, false, newConfig(tc.mode), 0, 0).ProcessPath(dir)
+		if err != nil {
+			t.Fatalf("ProcessPath with mode %q failed: %v", tc.mode, err)
+		}
+
+		if got := countIssuesByID(issues, rules.NoMagicNumbersID); got != tc.magic {
+			t.Fatalf("mode %q: expected %d magic number issues, got %d", tc.mode, tc.magic, got)
+		}
+
+		if got := countIssuesByID(issues, rules.NoDotImportsID); got != tc.dotImports {
+			t.Fatalf("mode %q: expected %d dot import issues, got %d", tc.mode, tc.dotImports, got)
+		}
+	}
+}
+
+var registerPlaceholderFuncRule sync.Once
+
+type placeholderFuncRule struct{}
+
+func (placeholderFuncRule) Name() string { return "test-no-placeholder-funcs" }
+
+func (placeholderFuncRule) Targets() []ast.Node { return []ast.Node{(*ast.FuncDecl)(nil)} }
+
+func (placeholderFuncRule) Run(runner *rule.Runner, node ast.Node) {
+	fn := node.(*ast.FuncDecl)
+	if fn.Name.Name == "placeholder" || fn.Name.Name == "stub" {
+		runner.Reportf(fn.Name, "function %q is a placeholder", fn.Name.Name)
+	}
+}
+
+func TestProcessPath_CompiledPluginRules(t *testing.T) {
+	registerPlaceholderFuncRule.Do(func() {
+		rule.Register(placeholderFuncRule{})
+	})
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sample.go")
+
+	src := `package sample
+
+func placeholder() {}
+
+// @serenity-ignore test-no-placeholder-funcs: kept for the demo
+func stub() {}
+`
+
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use:    true,
+			Issues: &rules.LinterIssuesOptions{},
+		},
+		Plugins: []rules.PluginOptions{{
+			Name: "house",
+			Rules: map[string]*rules.LinterBaseRule{
+				"test-no-placeholder-funcs": {Severity: "error"},
+			},
+		}},
+	}
+
+	l := New(false, false, cfg, 0, 0)
+	if err := l.LoadPlugins(); err != nil {
+		t.Fatalf("LoadPlugins failed: %v", err)
+	}
+	defer l.Close()
+
+	issues, err := l.ProcessPath(path)
+	if err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	if len(issues) != 1 {
+		t.Fatalf("expected one plugin issue, got %+v", issues)
+	}
+
+	issue := issues[0]
+	if issue.ID != rules.PluginIssueID || issue.Line != 3 || issue.Severity != rules.SeverityError {
+		t.Fatalf("unexpected plugin issue: %+v", issue)
+	}
+
+	if got := rules.IssueRuleName(issue); got != "test-no-placeholder-funcs" {
+		t.Fatalf("expected the plugin rule name, got %q", got)
+	}
+
+	if got := rules.FormatMessage(issue); got != `function "placeholder" is a placeholder` {
+		t.Fatalf("unexpected message %q", got)
+	}
+
+	generated := filepath.Join(t.TempDir(), "stub_gen.go")
+	if err := os.WriteFile(generated, []byte("// Code generated by stubgen. DO NOT EDIT.\n\n"+src), 0o644); err != nil {
+		t.Fatalf("write generated fixture: %v", err)
+	}
+
+	cfg.File = &rules.GoFileOptions{Generated: utils.Ptr(rules.GeneratedLintLight)}
+
+	l = New(false, false, cfg, 0, 0)
+	if err := l.LoadPlugins(); err != nil {
+		t.Fatalf("LoadPlugins failed: %v", err)
+	}
+	defer l.Close()
+
+	issues, err = l.ProcessPath(generated)
+	if err != nil {
+		t.Fatalf("ProcessPath on the generated file failed: %v", err)
+	}
+
+	if len(issues) != 1 || rules.IssueRuleName(issues[0]) != "test-no-placeholder-funcs" {
+		t.Fatalf("expected the plugin rule to run on generated files in lint-light mode, got %+v", issues)
+	}
+
+	cfg.Plugins[0].Rules = map[string]*rules.LinterBaseRule{"not-compiled-in": {Severity: "warn"}}
+	if err := New(false, false, cfg, 0, 0).LoadPlugins(); err == nil {
+		t.Fatal("expected an error for a rule that is not compiled in")
+	}
+}
+
+func TestProcessPath_PatternRules(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "sample.go")
+	testPath := filepath.Join(dir, "sample_test.go")
+
+	src := `package sample
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+func handle(f *os.File, n int) error {
+	defer f.Close()
+	f.Close()
+	time.Sleep(time.Second)
+
+	if n == n {
+		return errors.New(fmt.Sprintf("bad %d", n))
+	}
+
+	return nil
+}
+`
+
+	testSrc := `package sample
+
+import "time"
+
+func wait() {
+	time.Sleep(10 * time.Millisecond)
+}
+`
+
+	if err := os.WriteFile(mainPath, []byte(src), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	if err := os.WriteFile(testPath, []byte(testSrc), 0o644); err != nil {
+		t.Fatalf("write test fixture: %v", err)
+	}
+
+	newConfig := func() *rules.LinterOptions {
+		return &rules.LinterOptions{
+			Linter: rules.LinterRules{
+				Use:    true,
+				Issues: &rules.LinterIssuesOptions{},
+				Patterns: []rules.PatternRuleOptions{
+					{Name: "close-outside-defer", Pattern: "$x.Close()", Message: "close $x in a defer", Severity: "warn", NotInside: []string{"defer"}},
+					{Name: "no-sleep-in-tests", Pattern: "time.Sleep($d)", Message: "avoid sleeping for $d in tests", Severity: "error", Files: []string{"*_test.go"}},
+					{Name: "self-compare", Pattern: "$x == $x", Message: "$x is compared with itself", Severity: "error"},
+					{Name: "use-errorf", Pattern: "errors.New(fmt.Sprintf($f, $a))", Severity: "warn", Replacement: "fmt.Errorf($f, $a)"},
+				},
+			},
+		}
+	}
+
+	issues, err := New(false, false, newConfig(), 0, 0).ProcessPath(dir)
+	if err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	messages := make(map[string]string, len(issues))
+	for _, issue := range issues {
+		if issue.ID != rules.PatternMatchID {
+			t.Fatalf("unexpected non-pattern issue: %+v", issue)
+		}
+
+		messages[rules.IssueRuleName(issue)] = fmt.Sprintf("%s:%d %s", filepath.Base(issue.Path), issue.Line, rules.FormatMessage(issue))
+	}
+
+	want := map[string]string{
+		"close-outside-defer": "sample.go:12 close f in a defer",
+		"no-sleep-in-tests":   "sample_test.go:6 avoid sleeping for 10 * time.Millisecond in tests",
+		"self-compare":        "sample.go:15 n is compared with itself",
+		"use-errorf":          `sample.go:16 replace with fmt.Errorf("bad %d", n)`,
+	}
+
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d pattern issues, got %d: %v", len(want), len(issues), messages)
+	}
+
+	for name, msg := range want {
+		if messages[name] != msg {
+			t.Fatalf("rule %s: expected %q, got %q", name, msg, messages[name])
+		}
+	}
+
+	if _, err := New(true, false, newConfig(), 0, 0).ProcessPath(mainPath); err != nil {
+		t.Fatalf("ProcessPath with write failed: %v", err)
+	}
+
+	fixed, err := os.ReadFile(mainPath)
+	if err != nil {
+		t.Fatalf("read fixed file: %v", err)
+	}
+
+	if !strings.Contains(string(fixed), `return fmt.Errorf("bad %d", n)`) {
+		t.Fatalf("expected the replacement to be applied, got:\n%s", fixed)
+	}
+}
+
+func TestProcessPath_PatternGlobsAndDefaultNames(t *testing.T) {
+	dir := t.TempDir()
+	src := "package p\n\nfunc same(n int) bool {\n\treturn n == n || n != n\n}\n"
+
+	writeFiles(t, dir, map[string]string{
+		"cmd/tool/main.go":  src,
+		"internal/api/a.go": src,
+	})
+
+	t.Chdir(dir)
+
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use:    true,
+			Issues: &rules.LinterIssuesOptions{},
+			Patterns: []rules.PatternRuleOptions{
+				{Pattern: "$x == $x", Severity: "warn", Files: []string{"cmd/**"}},
+				{Pattern: "$x != $x", Severity: "warn", Files: []string{"**/api/*.go"}},
+			},
+		},
+	}
+
+	issues, err := New(false, false, cfg, 0, 0).ProcessPath(".")
+	if err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, filepath.ToSlash(issue.Path)+":"+rules.IssueRuleName(issue))
+	}
+	sort.Strings(got)
+
+	want := []string{"cmd/tool/main.go:pattern-1", "internal/api/a.go:pattern-2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("issues = %v, want %v", got, want)
+	}
+}
+
+func TestProcessPath_SkipsGoExcludeFiles(t *testing.T) {
+	dir := t.TempDir()
+	src := "package p\n\nfunc same(n int) bool {\n\treturn n == n\n}\n"
+
+	writeFiles(t, dir, map[string]string{
+		"api/a.go":           src,
+		"api/a_gen.go":       src,
+		"third_party/x/x.go": src,
+		"cmd/tool/main.go":   src,
+	})
+
+	t.Chdir(dir)
+
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use:      true,
+			Issues:   &rules.LinterIssuesOptions{},
+			Patterns: []rules.PatternRuleOptions{{Pattern: "$x == $x", Severity: "warn"}},
+		},
+		File: &rules.GoFileOptions{Exclude: &[]string{"*_gen.go", "third_party/**", "cmd/**"}},
+	}
+
+	l := New(false, false, cfg, 0, 0)
+
+	issues, err := l.ProcessPath(".")
+	if err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, filepath.ToSlash(issue.Path))
+	}
+	sort.Strings(got)
+
+	want := []string{"api/a.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("issues in %v, want only %v", got, want)
+	}
+
+	var walked []string
+	if err := l.WalkFiles([]string{"."}, func(path string) error {
+		walked = append(walked, filepath.ToSlash(path))
+		return nil
+	}); err != nil {
+		t.Fatalf("WalkFiles failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(walked, want) {
+		t.Fatalf("WalkFiles = %v, want %v", walked, want)
+	}
+}
+
+func TestProcessPath_PatternsTellVariadicCallsApart(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "sample.go")
+	src := "package sample\n\nimport \"fmt\"\n\nfunc log(args []any) {\n\tfmt.Println(args...)\n\tfmt.Println(args)\n}\n"
+
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use:    true,
+			Issues: &rules.LinterIssuesOptions{},
+			Patterns: []rules.PatternRuleOptions{
+				{Name: "spread-println", Pattern: "fmt.Println($x...)", Severity: "warn"},
+			},
+		},
+	}
+
+	issues, err := New(false, false, cfg, 0, 0).ProcessPath(path)
+	if err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	if len(issues) != 1 || issues[0].Line != 6 {
+		t.Fatalf("expected only the spread call on line 6 to match, got %+v", issues)
+	}
+}
+
+func TestNew_CompilesPatternsOncePerConfig(t *testing.T) {
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use:      true,
+			Patterns: []rules.PatternRuleOptions{{Name: "self-compare", Pattern: "$x == $x", Severity: "warn"}},
+		},
+		Overrides: []rules.OverrideOptions{{Files: []string{"cmd/**"}}},
+	}
+
+	l := New(false, false, cfg, 0, 0)
+	if len(l.patterns) != 1 {
+		t.Fatalf("expected one compiled pattern, got %d", len(l.patterns))
+	}
+
+	has := func(active *ActiveRules, want rules.Rule) bool {
+		for _, kind := range active.byKind {
+			if slices.Contains(kind, want) {
+				return true
+			}
+		}
+		return false
+	}
+
+	root := l.rootScope()
+	if !has(root.rules, l.patterns[0]) || !has(root.overrides.rules([]int{0}, false), l.patterns[0]) {
+		t.Fatal("expected the root rules and its overrides to share the compiled patterns")
+	}
+
+	copied := *cfg
+	nested := l.newScope(&copied, t.TempDir())
+	if has(nested.rules, l.patterns[0]) {
+		t.Fatal("expected a nested config to compile its own patterns")
+	}
+
+	if !has(nested.overrides.rules([]int{0}, false), nested.rules.byKind[kindOf(&ast.BinaryExpr{})][0]) {
+		t.Fatal("expected a nested config's overrides to share its compiled patterns")
+	}
+}
+
+func TestProcessPath_GoAnalysisAnalyzers(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sample.go")
+
+	src := `package sample
+
+import "fmt"
+
+func handle(n int) {
+	fmt.Printf("%d\n", "text")
+	n = n
+	// @serenity-ignore printf: intentional
+	fmt.Printf("%s\n", 1)
+	fmt.Println(n)
+}
+`
+
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	newConfig := func() *rules.LinterOptions {
+		return &rules.LinterOptions{
+			Linter: rules.LinterRules{
+				Use:    true,
+				Issues: &rules.LinterIssuesOptions{},
+				Analyzers: map[string]*rules.LinterBaseRule{
+					"printf": {Severity: "error"},
+					"assign": {Severity: "warn"},
+				},
+			},
+		}
+	}
+
+	issues, err := New(false, false, newConfig(), 0, 0).ProcessPath(path)
+	if err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	byRule := make(map[string][]rules.Issue, 2)
+	for _, issue := range issues {
+		if issue.ID != rules.AnalyzerDiagnosticID {
+			t.Fatalf("unexpected issue: %+v", issue)
+		}
+
+		name := rules.IssueRuleName(issue)
+		byRule[name] = append(byRule[name], issue)
+	}
+
+	if got := byRule["printf"]; len(got) != 1 || got[0].Line != 6 || got[0].Severity != rules.SeverityError {
+		t.Fatalf("expected one unsuppressed printf diagnostic on line 6, got %+v", got)
+	}
+
+	if !strings.Contains(rules.FormatMessage(byRule["printf"][0]), "%d") {
+		t.Fatalf("expected the analyzer message to be kept, got %q", rules.FormatMessage(byRule["printf"][0]))
+	}
+
+	if got := byRule["assign"]; len(got) != 1 || got[0].Line != 7 || !got[0].IsFixable() {
+		t.Fatalf("expected one fixable self-assignment on line 7, got %+v", got)
+	}
+
+	issues, err = New(true, false, newConfig(), 0, 0).ProcessPath(path)
+	if err != nil {
+		t.Fatalf("ProcessPath with write failed: %v", err)
+	}
+
+	fixed, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read fixed file: %v", err)
+	}
+
+	if strings.Contains(string(fixed), "n = n") {
+		t.Fatalf("expected the self-assignment to be removed, got:\n%s", fixed)
+	}
+
+	for _, issue := range issues {
+		if rules.IssueRuleName(issue) == "assign" && !issue.WasFixed() {
+			t.Fatalf("expected the assign finding to be reported as fixed: %+v", issue)
+		}
+	}
+}
+
+// deprecatedCallsRule exports a fact for every function documented as
+// deprecated and reports calls to them from other packages.
+type deprecatedCallsRule struct{}
+
+type deprecatedFact struct {
+	Notice string `json:"notice"`
+}
+
+func (deprecatedCallsRule) Name() string { return "test-deprecated-calls" }
+
+func (deprecatedCallsRule) FactVersion() string { return "1" }
+
+func (deprecatedCallsRule) Targets() []ast.Node {
+	return []ast.Node{(*ast.FuncDecl)(nil), (*ast.CallExpr)(nil)}
+}
+
+func (r deprecatedCallsRule) Run(runner *rules.Runner, node ast.Node) {
+	switch n := node.(type) {
+	case *ast.FuncDecl:
+		if n.Doc == nil || n.Recv != nil {
+			return
+		}
+
+		for _, line := range strings.Split(n.Doc.Text(), "\n") {
+			if notice, ok := strings.CutPrefix(line, "Deprecated: "); ok {
+				runner.Facts.Export(r.Name(), n.Name.Name, deprecatedFact{Notice: notice})
+			}
+		}
+	case *ast.CallExpr:
+		sel, ok := n.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return
+		}
+
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return
+		}
+
+		path, ok := rules.ImportPath(runner.File, pkg.Name)
+		if !ok {
+			return
+		}
+
+		var fact deprecatedFact
+		if runner.Facts.Import(path, r.Name(), sel.Sel.Name, &fact) {
+			runner.Report(n.Pos(), rules.Issue{
+				ID:       rules.PluginIssueID,
+				Severity: rules.SeverityWarn,
+				ArgStr1:  rules.PackContext2(r.Name(), pkg.Name+"."+sel.Sel.Name+" is deprecated: "+fact.Notice),
+			})
+		}
+	}
+}
+
+func TestProcessPath_CrossPackageFacts(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SERENITY_CACHE_DIR", t.TempDir())
+
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.25\n",
+		"app.go": `package app
+
+import "example.com/app/client"
+
+func Run() { client.Dial() }
+`,
+		"client/client.go": `package client
+
+import "example.com/app/store"
+
+func Dial() { store.Open() }
+`,
+		"store/store.go": `package store
+
+// Open opens the store.
+//
+// Deprecated: use OpenContext.
+func Open() {}
+
+func OpenContext() {}
+`,
+	}
+
+	writeFiles(t, dir, files)
+
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use:    true,
+			Issues: &rules.LinterIssuesOptions{},
+		},
+		Performance: &rules.PerformanceOptions{
+			Use:     true,
+			Threads: utils.Ptr(4),
+			Caching: utils.Ptr(true),
+		},
+	}
+
+	run := func() []rules.Issue {
+		t.Helper()
+
+		l := New(false, false, cfg, 0, 0)
+		l.ActiveRules.Add(deprecatedCallsRule{})
+
+		issues, err := l.ProcessPath(dir)
+		if err != nil {
+			t.Fatalf("ProcessPath failed: %v", err)
+		}
+
+		return issues
+	}
+
+	for _, label := range []string{"cold", "cached"} {
+		issues := run()
+		if len(issues) != 1 {
+			t.Fatalf("%s run: expected one deprecated call, got %+v", label, issues)
+		}
+
+		if got := rules.FormatMessage(issues[0]); got != "store.Open is deprecated: use OpenContext." {
+			t.Fatalf("%s run: unexpected message %q", label, got)
+		}
+
+		if !strings.HasSuffix(filepath.ToSlash(issues[0].Path), "client/client.go") {
+			t.Fatalf("%s run: expected the issue in the dependent package, got %s", label, issues[0].Path)
+		}
+	}
+
+	undeprecated := "package store\n\nfunc Open() {}\n\nfunc OpenContext() {}\n"
+	if err := os.WriteFile(filepath.Join(dir, "store", "store.go"), []byte(undeprecated), 0o644); err != nil {
+		t.Fatalf("rewrite store: %v", err)
+	}
+
+	if issues := run(); len(issues) != 0 {
+		t.Fatalf("expected dependents to be re-analyzed when facts change, got %+v", issues)
+	}
+}
+
+func TestProcessPath_FactRulesOnlyEnabledBelowTheRoot(t *testing.T) {
+	t.Setenv("SERENITY_CONFIG_PATH", "")
+
+	sources := map[string]string{
+		"go.mod":           "module example.com/app\n\ngo 1.25\n",
+		"client/client.go": "package client\n\nimport \"example.com/app/store\"\n\nfunc Dial() { store.Open() }\n",
+		"store/store.go":   "package store\n\n// Open opens the store.\n//\n// Deprecated: use OpenContext.\nfunc Open() {}\n",
+	}
+
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"override", map[string]string{
+			"serenity.json": `{"linter": {"use": true}, "overrides": [{"files": ["app/**"], "rules": {}}]}`,
+		}},
+		{"nested config", map[string]string{
+			"serenity.json":     `{"linter": {"use": true}}`,
+			"app/serenity.json": `{"linter": {"issues": {"max": 100}}}`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			for name, src := range sources {
+				tt.files[filepath.Join("app", name)] = src
+			}
+
+			writeFiles(t, dir, tt.files)
+
+			t.Chdir(dir)
+
+			tree, err := config.LoadTree("")
+			if err != nil {
+				t.Fatalf("LoadTree failed: %v", err)
+			}
+
+			// The fact rule reaches the overrides and nested scopes, which add
+			// the plugin rules, but not the root rules built by New.
+			l := New(false, false, tree.Root(), 0, 0)
+			l.Configs = tree
+			l.Plugins = &plugin.Set{Rules: []rules.Rule{deprecatedCallsRule{}}}
+
+			issues, err := l.ProcessPath(dir)
+			if err != nil {
+				t.Fatalf("ProcessPath failed: %v", err)
+			}
+
+			if len(issues) != 1 || !strings.HasSuffix(filepath.ToSlash(issues[0].Path), "client/client.go") {
+				t.Fatalf("expected the deprecated call in client.go, got %+v", issues)
+			}
+		})
+	}
+}
+
+func TestProcessPath_ErrorNotWrappedFollowsDataFlow(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sample.go")
+
+	src := `package sample
+
+import (
+	"errors"
+	"os"
+)
+
+func count(n int) int {
+	total := n
+	return total
+}
+
+func open(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return nil
+}
+
+func build() error {
+	err := errors.New("missing")
+	return err
+}
+
+func pass(err error) error {
+	return err
+}
+
+func check(name string) error {
+	if err := os.Remove(name); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func retry(name string) error {
+	err := os.Remove(name)
+	if err != nil {
+		err = errors.New("could not remove")
+	}
+	return err
+}
+`
+
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use: true,
+			Rules: rules.LinterRulesGroup{
+				Errors: &rules.ErrorHandlingRulesGroup{
+					Use:             true,
+					ErrorNotWrapped: &rules.LinterBaseRule{Severity: "warn"},
+				},
+			},
+			Issues: &rules.LinterIssuesOptions{},
+		},
+	}
+
+	issues, err := New(false, false, cfg, 0, 0).ProcessPath(path)
+	if err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	var lines []uint32
+	for _, issue := range issues {
+		if issue.ID == rules.ErrorNotWrappedID {
+			lines = append(lines, issue.Line)
+		}
+	}
+
+	// open, check and retry return errors from calls; retry only on the
+	// path where the error was not replaced.
+	want := []uint32{16, 34, 45}
+	if fmt.Sprint(lines) != fmt.Sprint(want) {
+		t.Fatalf("expected error-not-wrapped on lines %v, got %v", want, lines)
+	}
+}
+
+func TestWalkPackages_SplitsByPackageClauseAndBuildTargets(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"foo.go":             "package foo\n\nfunc Foo() {}\n",
+		"foo_test.go":        "package foo\n\nfunc helper() {}\n",
+		"api_test.go":        "package foo_test\n\nfunc helper() {}\n",
+		"gen.go":             "//go:build ignore\n// +build ignore\n\npackage main\n\nfunc main() {}\n",
+		"sys_linux.go":       "package foo\n\nfunc sys() {}\n",
+		"sys_windows.go":     "package foo\n\nfunc sys() {}\n",
+		"integration.go":     "// Copyright notice.\n\n//go:build integration && !windows\n\npackage foo\n\nfunc slow() {}\n",
+		"_scratch.go":        "package scratch\n",
+		"legacy_plus.go":     "// +build linux,amd64\n\npackage foo\n\nfunc legacy() {}\n",
+		"arch_arm64_test.go": "package foo\n\nfunc arm() {}\n",
+	}
+
+	writeFiles(t, dir, files)
+
+	walk := func(targets ...rules.BuildTarget) map[string][]string {
+		t.Helper()
+
+		l := New(false, false, &rules.LinterOptions{
+			File: &rules.GoFileOptions{Targets: &targets},
+		}, 0, 0)
+
+		jobs := make(map[string][]string)
+
+		err := l.walkPackages([]string{dir}, make(chan struct{}), func(job PackageJob) bool {
+			names := make([]string, 0, len(job.files))
+			for _, path := range job.files {
+				names = append(names, filepath.Base(path))
+			}
+
+			key := job.pkgName
+			for n := 2; jobs[key] != nil; n++ {
+				key = job.pkgName + "#" + strconv.Itoa(n)
+			}
+			jobs[key] = names
+
+			return true
+		})
+		if err != nil {
+			t.Fatalf("walkPackages failed: %v", err)
+		}
+
+		return jobs
+	}
+
+	got := walk(rules.BuildTarget{GOOS: "linux", GOARCH: "amd64"})
+	want := map[string][]string{
+		"foo":      {"foo.go", "foo_test.go", "legacy_plus.go", "sys_linux.go"},
+		"foo_test": {"api_test.go"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("linux/amd64 jobs = %v, want %v", got, want)
+	}
+
+	got = walk(
+		rules.BuildTarget{GOOS: "linux", GOARCH: "arm64", Tags: []string{"integration"}},
+		rules.BuildTarget{GOOS: "windows", GOARCH: "amd64", Tags: []string{"integration"}},
+	)
+	want = map[string][]string{
+		"foo":      {"arch_arm64_test.go", "foo.go", "foo_test.go", "integration.go", "sys_linux.go"},
+		"foo#2":    {"foo.go", "foo_test.go", "sys_windows.go"},
+		"foo_test": {"api_test.go"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("matrix jobs = %v, want %v", got, want)
+	}
+}
+
+func TestProcessPath_MergesIssuesAcrossBuildTargets(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"shared.go":      "package foo\n\nfunc shared() (n int) {\n\treturn\n}\n",
+		"sys_linux.go":   "package foo\n\nfunc sys() (n int) {\n\treturn\n}\n",
+		"sys_darwin.go":  "package foo\n\nfunc sys() (n int) {\n\treturn\n}\n",
+		"sys_windows.go": "package foo\n\nfunc sys() (n int) {\n\treturn\n}\n",
+	}
+
+	writeFiles(t, dir, files)
+
+	cfg := &rules.LinterOptions{
+		File: &rules.GoFileOptions{
+			Targets: &[]rules.BuildTarget{
+				{GOOS: "linux", GOARCH: "amd64"},
+				{GOOS: "darwin", GOARCH: "arm64"},
+			},
+		},
+		Linter: rules.LinterRules{
+			Use: true,
+			Rules: rules.LinterRulesGroup{
+				BestPractices: &rules.BestPracticesRulesGroup{
+					Use: true,
+					NoBareReturns: &rules.LinterBaseRule{
+						Severity: "warn",
+					},
+				},
+			},
+			Issues: &rules.LinterIssuesOptions{},
+		},
+	}
+
+	l := New(false, false, cfg, 0, 0)
+
+	issues, err := l.ProcessPath(dir)
+	if err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, filepath.Base(issue.Path))
+	}
+	sort.Strings(got)
+
+	want := []string{"shared.go", "sys_darwin.go", "sys_linux.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("issues in %v, want %v", got, want)
+	}
+}
+
+func TestProcessPath_FixesFilesSharedByBuildTargets(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"shared.go":      "package foo\n\nimport fmt \"fmt\"\n\nvar _ = fmt.Sprint\n",
+		"sys_linux.go":   "package foo\n\nimport strings \"strings\"\n\nvar _ = strings.TrimSpace\n",
+		"sys_darwin.go":  "package foo\n\nimport strings \"strings\"\n\nvar _ = strings.TrimSpace\n",
+		"sys_windows.go": "package foo\n\nimport strings \"strings\"\n\nvar _ = strings.TrimSpace\n",
+	}
+
+	writeFiles(t, dir, files)
+
+	cfg := &rules.LinterOptions{
+		File: &rules.GoFileOptions{
+			Targets: &[]rules.BuildTarget{
+				{GOOS: "linux", GOARCH: "amd64"},
+				{GOOS: "darwin", GOARCH: "arm64"},
+				{GOOS: "windows", GOARCH: "amd64"},
+			},
+		},
+		Linter: rules.LinterRules{
+			Use: true,
+			Rules: rules.LinterRulesGroup{
+				Imports: &rules.ImportRulesGroup{
+					Use:                  true,
+					RedundantImportAlias: &rules.LinterBaseRule{Severity: "warn"},
+				},
+			},
+			Issues: &rules.LinterIssuesOptions{},
+		},
+	}
+
+	l := New(true, false, cfg, 0, 0)
+	l.Workers = 4
+
+	if _, err := l.ProcessPath(dir); err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	for name := range files {
+		src, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Contains(string(src), "fmt \"fmt\"") || strings.Contains(string(src), "strings \"strings\"") {
+			t.Fatalf("expected the alias in %s to be removed, got:\n%s", name, src)
+		}
+
+		if _, err := parser.ParseFile(token.NewFileSet(), name, src, 0); err != nil {
+			t.Fatalf("expected %s to stay valid: %v", name, err)
+		}
+	}
+}
+
+func TestSplitPackages_ReusesTheLayoutWhileFilesAreUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SERENITY_CACHE_DIR", t.TempDir())
+
+	for name, src := range map[string]string{
+		"foo.go":      "package foo\n",
+		"api_test.go": "package foo_test\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	l := New(false, false, &rules.LinterOptions{
+		Performance: &rules.PerformanceOptions{Use: true, Caching: utils.Ptr(true)},
+	}, 0, 0)
+
+	probe := func() ([]string, []packageInput) {
+		t.Helper()
+
+		files := []string{filepath.Join(dir, "api_test.go"), filepath.Join(dir, "foo.go")}
+		inputs := make([]packageInput, len(files))
+
+		for i, path := range files {
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			inputs[i] = packageProbeFromInfo(path, info)
+		}
+
+		return files, inputs
+	}
+
+	files, inputs := probe()
+	if !inputs[0].FastPathSupported {
+		t.Skip("file stamps unavailable on this platform")
+	}
+
+	if jobs := l.splitPackages(dir, files, inputs); len(jobs) != 2 {
+		t.Fatalf("expected 2 packages, got %d", len(jobs))
+	}
+
+	if groups, ok := l.Cache.loadLayout(inputs); !ok || len(groups) != 2 {
+		t.Fatalf("expected the layout to be cached, got %v, %v", groups, ok)
+	}
+
+	if err := os.WriteFile(files[0], []byte("package foo\n\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, inputs = probe()
+	if _, ok := l.Cache.loadLayout(inputs); ok {
+		t.Fatal("expected a changed file to invalidate the layout")
+	}
+
+	if jobs := l.splitPackages(dir, files, inputs); len(jobs) != 1 {
+		t.Fatalf("expected the files to form 1 package after the change, got %d", len(jobs))
+	}
+}
+
+// moduleRecorder records the module each file was analyzed under.
+type moduleRecorder struct {
+	mu      *sync.Mutex
+	modules map[string]string
+}
+
+func (moduleRecorder) Name() string { return "test-module-recorder" }
+
+func (moduleRecorder) Targets() []ast.Node { return []ast.Node{(*ast.File)(nil)} }
+
+func (r moduleRecorder) Run(runner *rules.Runner, node ast.Node) {
+	name := "-"
+	if runner.Module != nil {
+		name = runner.Module.Path + "@" + runner.Module.GoVersion
+	}
+
+	r.mu.Lock()
+	r.modules[filepath.Base(runner.Fset.Position(node.Pos()).Filename)] = name
+	r.mu.Unlock()
+}
+
+func TestProcessModules_LintsWorkspaceModulesWithTheirGoVersion(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"go.work":                "go 1.22\n\nuse (\n\t./api\n\t./legacy\n)\n",
+		"api/go.mod":             "module example.com/api\n\ngo 1.22\n",
+		"api/api.go":             "package api\n\nfunc Empty(s []int) bool { return len(s) == 0 }\n",
+		"api/v2/v2.go":           "package v2\n\nfunc Empty(s []int) bool { return len(s) == 0 }\n",
+		"legacy/go.mod":          "module example.com/legacy\n\ngo 1.18\n",
+		"legacy/legacy.go":       "package legacy\n\nfunc Empty(s []int) bool { return len(s) == 0 }\n",
+		"scratch/scratch.go":     "package scratch\n\nfunc Empty(s []int) bool { return len(s) == 0 }\n",
+		"api/tools/go.mod":       "module example.com/tools\n\ngo 1.25\n",
+		"api/tools/tools.go":     "package tools\n\nfunc Empty(s []int) bool { return len(s) == 0 }\n",
+		"legacy/internal/old.go": "package internal\n\nfunc Empty(s []int) bool { return len(s) == 0 }\n",
+	}
+
+	writeFiles(t, dir, files)
+
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use:    true,
+			Issues: &rules.LinterIssuesOptions{},
+			Patterns: []rules.PatternRuleOptions{
+				{Name: "empty-check", Pattern: "len($s) == 0", Severity: "warn", MinGoVersion: "1.21"},
+			},
+		},
+	}
+
+	recorder := moduleRecorder{mu: &sync.Mutex{}, modules: make(map[string]string)}
+
+	l := New(false, false, cfg, 0, 0)
+	l.ActiveRules.Add(recorder)
+
+	results, err := l.ProcessModules(dir)
+	if err != nil {
+		t.Fatalf("ProcessModules failed: %v", err)
+	}
+
+	got := make(map[string][]string)
+	for _, result := range results {
+		files := []string{}
+		for _, issue := range result.Issues {
+			files = append(files, filepath.Base(issue.Path))
+		}
+		sort.Strings(files)
+		got[result.Module.Path] = files
+	}
+
+	want := map[string][]string{
+		"example.com/api":    {"api.go", "v2.go"},
+		"example.com/legacy": {},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("issues by module = %v, want %v", got, want)
+	}
+
+	wantModules := map[string]string{
+		"api.go":    "example.com/api@1.22",
+		"v2.go":     "example.com/api@1.22",
+		"legacy.go": "example.com/legacy@1.18",
+		"old.go":    "example.com/legacy@1.18",
+	}
+	if !reflect.DeepEqual(recorder.modules, wantModules) {
+		t.Fatalf("modules seen by rules = %v, want %v", recorder.modules, wantModules)
+	}
+}
+
+func TestProcessModules_FindsTheWorkspaceAboveRoot(t *testing.T) {
+	dir := t.TempDir()
+	src := "package p\n\nfunc Empty(s []int) bool { return len(s) == 0 }\n"
+
+	writeFiles(t, dir, map[string]string{
+		"go.work":                  "go 1.22\n\nuse (\n\t./services/api\n\t./services/billing\n\t./tools\n)\n",
+		"services/api/go.mod":      "module example.com/api\n\ngo 1.22\n",
+		"services/api/api.go":      src,
+		"services/billing/go.mod":  "module example.com/billing\n\ngo 1.22\n",
+		"services/billing/bill.go": src,
+		"tools/go.mod":             "module example.com/tools\n\ngo 1.22\n",
+		"tools/tools.go":           src,
+	})
+
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use:      true,
+			Issues:   &rules.LinterIssuesOptions{},
+			Patterns: []rules.PatternRuleOptions{{Name: "empty-check", Pattern: "len($s) == 0", Severity: "warn"}},
+		},
+	}
+
+	modules := func() []string {
+		results, err := New(false, false, cfg, 0, 0).ProcessModules(filepath.Join(dir, "services"))
+		if err != nil {
+			t.Fatalf("ProcessModules failed: %v", err)
+		}
+
+		var paths []string
+		for _, result := range results {
+			if result.Module != nil {
+				paths = append(paths, result.Module.Path)
+			}
+		}
+
+		return paths
+	}
+
+	t.Setenv("GOWORK", "")
+
+	if got, want := modules(), []string{"example.com/api", "example.com/billing"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("modules = %v, want the workspace modules under the root %v", got, want)
+	}
+
+	t.Setenv("GOWORK", "off")
+
+	if got := modules(); len(got) != 0 {
+		t.Fatalf("modules = %v, want a single result outside any module with GOWORK=off", got)
+	}
+}
+
+func TestProcessPath_SkipsRulesNeedingANewerGo(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFiles(t, dir, map[string]string{
+		"old/go.mod": "module example.com/old\n\ngo 1.12\n",
+		"old/old.go": "package old\n\nimport \"os\"\n\nfunc Read() error {\n\t_, err := os.Open(\"x\")\n\treturn err\n}\n",
+		"new/go.mod": "module example.com/new\n\ngo 1.13\n",
+		"new/new.go": "package new\n\nimport \"os\"\n\nfunc Read() error {\n\t_, err := os.Open(\"x\")\n\treturn err\n}\n",
+	})
+
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use: true,
+			Rules: rules.LinterRulesGroup{
+				Errors: &rules.ErrorHandlingRulesGroup{
+					Use:             true,
+					ErrorNotWrapped: &rules.LinterBaseRule{Severity: "warn"},
+				},
+			},
+			Issues: &rules.LinterIssuesOptions{},
+		},
+	}
+
+	issues, err := New(false, false, cfg, 0, 0).ProcessPath(dir)
+	if err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, filepath.Base(issue.Path))
+	}
+
+	if !reflect.DeepEqual(got, []string{"new.go"}) {
+		t.Fatalf("error-not-wrapped reported in %v, want only the module on go 1.13", got)
+	}
+}
+
+func TestProcessPath_NestedConfigs(t *testing.T) {
+	t.Setenv("SERENITY_CONFIG_PATH", "")
+	t.Setenv("SERENITY_CACHE_DIR", t.TempDir())
+
+	dir := t.TempDir()
+	src := "package p\n\nfunc F() (n int) {\n\treturn\n}\n"
+
+	files := map[string]string{
+		"serenity.json":                   `{"linter": {"use": true, "rules": {"recommended": false}}, "performance": {"use": true, "caching": true}}`,
+		"services/payments/serenity.json": `{"linter": {"rules": {"bestPractices": {"use": true, "noBareReturns": {"severity": "error"}}}}}`,
+		"services/payments/pay.go":        src,
+		"services/payments/api/api.go":    src,
+		"tools/tool.go":                   src,
+	}
+
+	writeFiles(t, dir, files)
+
+	t.Chdir(dir)
+
+	run := func() []string {
+		t.Helper()
+
+		tree, err := config.LoadTree("")
+		if err != nil {
+			t.Fatalf("LoadTree failed: %v", err)
+		}
+
+		l := New(false, false, tree.Root(), 0, 0)
+		l.Configs = tree
+
+		issues, err := l.ProcessPath(dir)
+		if err != nil {
+			t.Fatalf("ProcessPath failed: %v", err)
+		}
+
+		var got []string
+		for _, issue := range issues {
+			rel, _ := filepath.Rel(dir, issue.Path)
+			got = append(got, filepath.ToSlash(rel)+":"+rules.IssueRuleName(issue))
+		}
+		sort.Strings(got)
+
+		return got
+	}
+
+	want := []string{"services/payments/api/api.go:no-bare-returns", "services/payments/pay.go:no-bare-returns"}
+	for _, pass := range []string{"cold", "cached"} {
+		if got := run(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s run: issues = %v, want %v", pass, got, want)
+		}
+	}
+
+	writeFiles(t, dir, map[string]string{
+		"services/payments/api/serenity.json": `{"root": true, "linter": {"use": true, "rules": {"recommended": false}}}`,
+	})
+
+	want = []string{"services/payments/pay.go:no-bare-returns"}
+	if got := run(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("after root config: issues = %v, want %v", got, want)
+	}
+}
+
+func TestProcessPath_NestedConfigErrorsFailTheRun(t *testing.T) {
+	t.Setenv("SERENITY_CONFIG_PATH", "")
+
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"serenity.json":            `{"linter": {"use": true}}`,
+		"services/serenity.json":   `{"linter": {"use": true,`,
+		"services/payments/pay.go": "package p\n",
+	}
+
+	writeFiles(t, dir, files)
+
+	t.Chdir(dir)
+
+	tree, err := config.LoadTree("")
+	if err != nil {
+		t.Fatalf("LoadTree failed: %v", err)
+	}
+
+	l := New(false, false, tree.Root(), 0, 0)
+	l.Configs = tree
+
+	if _, err := l.ProcessPath(dir); err == nil || !strings.Contains(err.Error(), filepath.Join("services", "serenity.json")) {
+		t.Fatalf("expected the broken nested config to fail the run, got %v", err)
+	}
+
+	if _, err := l.ProcessPath(filepath.Join(dir, "services", "payments", "pay.go")); err == nil {
+		t.Fatal("expected the broken nested config to fail a single-file run")
+	}
+}
+
+func TestProcessPath_OverridesRulesPerFile(t *testing.T) {
+	t.Setenv("SERENITY_CONFIG_PATH", "")
+
+	dir := t.TempDir()
+	magic := "package p\n\nfunc F() int {\n\treturn 42\n}\n"
+	unwrapped := "package p\n\nimport \"os\"\n\nfunc Open(name string) error {\n\tf, err := os.Open(name)\n\tif err != nil {\n\t\treturn err\n\t}\n\n\treturn f.Close()\n}\n"
+
+	files := map[string]string{
+		"serenity.json": `{"linter": {"use": true, "rules": {"recommended": false,
+			"bestPractices": {"use": true, "noMagicNumbers": {"severity": "warn"}},
+			"complexity": {"use": true, "maxFuncLines": {"severity": "warn", "max": 3}}}},
+			"overrides": [
+				{"files": ["**/*_test.go", "cmd/**"], "rules": {
+					"bestPractices": {"noMagicNumbers": {"severity": "off"}},
+					"complexity": {"maxFuncLines": {"severity": "off"}}}},
+				{"files": ["internal/api/**"], "rules": {
+					"errors": {"use": true, "errorNotWrapped": {"severity": "error"}}}}
+			]}`,
+		"p.go":                   magic,
+		"p_test.go":              "package p\n\nfunc G() int {\n\treturn 42\n}\n",
+		"cmd/tool/main.go":       magic,
+		"internal/api/api.go":    unwrapped,
+		"internal/store/open.go": unwrapped,
+	}
+
+	writeFiles(t, dir, files)
+
+	t.Chdir(dir)
+
+	tree, err := config.LoadTree("")
+	if err != nil {
+		t.Fatalf("LoadTree failed: %v", err)
+	}
+
+	l := New(false, false, tree.Root(), 0, 0)
+	l.Configs = tree
+
+	issues, err := l.ProcessPath(dir)
+	if err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	var got []string
+	for _, issue := range issues {
+		rel, _ := filepath.Rel(dir, issue.Path)
+		got = append(got, filepath.ToSlash(rel)+":"+rules.IssueRuleName(issue))
+	}
+	sort.Strings(got)
+
+	want := []string{
+		"internal/api/api.go:error-not-wrapped",
+		"internal/api/api.go:max-func-lines",
+		"internal/store/open.go:max-func-lines",
+		"p.go:no-magic-numbers",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("issues = %v, want %v", got, want)
+	}
+
+	// Globs stay relative to the config file when a subdirectory is checked
+	// with a relative path from below it.
+	t.Chdir(filepath.Join(dir, "cmd"))
+
+	tree, err = config.LoadTree("")
+	if err != nil {
+		t.Fatalf("LoadTree failed: %v", err)
+	}
+
+	l = New(false, false, tree.Root(), 0, 0)
+	l.Configs = tree
+
+	issues, err = l.ProcessPath("./tool")
+	if err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	if len(issues) != 0 {
+		t.Fatalf("expected the cmd/** override to apply to ./tool, got %+v", issues)
+	}
+}
+
+func TestProcessPath_StatsRecordPhasesAndRules(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	for name, src := range map[string]string{
+		"a.go": "package sample\n\nfunc a() (err error) {\n\treturn\n}\n",
+		"b.go": "package sample\n\nfunc b() {}\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatalf("write fixture: %v", err)
+		}
+	}
+
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use: true,
+			Rules: rules.LinterRulesGroup{
+				BestPractices: &rules.BestPracticesRulesGroup{
+					Use: true,
+					NoBareReturns: &rules.LinterBaseRule{
+						Severity: "warn",
+					},
+				},
+			},
+			Issues: &rules.LinterIssuesOptions{},
+		},
+	}
+
+	l := New(false, false, cfg, 0, 0)
+	l.Stats = NewRunStats()
+
+	if _, err := l.ProcessPath(dir); err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	report := l.StatsReport()
+
+	if report.Packages != 1 || report.Files != 2 {
+		t.Fatalf("expected 1 package of 2 files, got %d of %d", report.Packages, report.Files)
+	}
+
+	if report.Cache != nil {
+		t.Fatalf("expected no cache stats without the cache, got %+v", report.Cache)
+	}
+
+	if report.Phases[PhaseParse].Time <= 0 || report.Phases[PhaseRules].Time <= 0 {
+		t.Fatalf("expected parse and rules to be timed, got %+v", report.Phases)
+	}
+
+	if len(report.Rules) != 1 || report.Rules[0].Name != "no-bare-returns" || report.Rules[0].Calls == 0 {
+		t.Fatalf("expected no-bare-returns to be timed, got %+v", report.Rules)
+	}
+}
+
+func TestProcessPath_AnalyzerFixesWaitForRuleFixes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sample.go")
+
+	writeFiles(t, dir, map[string]string{
+		"sample.go": "package sample\n\nfunc count(n int) int {\n\tn = n\n\tn += 1\n\treturn n\n}\n",
+	})
+
+	newConfig := func() *rules.LinterOptions {
+		return &rules.LinterOptions{
+			Linter: rules.LinterRules{
+				Use:    true,
+				Issues: &rules.LinterIssuesOptions{},
+				Rules: rules.LinterRulesGroup{
+					Style: &rules.StyleRulesGroup{
+						Use:          true,
+						PreferIncDec: &rules.LinterBaseRule{Severity: "warn"},
+					},
+				},
+				Analyzers: map[string]*rules.LinterBaseRule{"assign": {}},
+			},
+		}
+	}
+
+	issues, err := New(true, false, newConfig(), 0, 0).ProcessPath(path)
+	if err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	for _, issue := range issues {
+		if rules.IssueRuleName(issue) == "assign" && issue.WasFixed() {
+			t.Fatalf("expected the assign fix to wait for the next run, got %+v", issue)
+		}
+	}
+
+	src, _ := os.ReadFile(path)
+	if !strings.Contains(string(src), "n++") || !strings.Contains(string(src), "n = n") {
+		t.Fatalf("expected only the rule fix to be applied, got:\n%s", src)
+	}
+
+	if _, err := New(true, false, newConfig(), 0, 0).ProcessPath(path); err != nil {
+		t.Fatalf("second ProcessPath failed: %v", err)
+	}
+
+	src, _ = os.ReadFile(path)
+	if strings.Contains(string(src), "n = n") {
+		t.Fatalf("expected the assign fix on the next run, got:\n%s", src)
+	}
+
+	fset := token.NewFileSet()
+	file := fset.AddFile(path, -1, 10)
+	if _, ok := textEdits(path, 10, fset, []analysis.TextEdit{{Pos: file.Pos(2), End: file.Pos(1)}}); ok {
+		t.Fatal("expected an edit ending before it starts to be rejected")
+	}
+	if _, ok := textEdits("other.go", 10, fset, []analysis.TextEdit{{Pos: file.Pos(1)}}); ok {
+		t.Fatal("expected an edit in another file to be rejected")
+	}
+}
+
+func TestProcessPath_CachesAnalyzerFindingsByImportedFacts(t *testing.T) {
+	t.Setenv("SERENITY_CACHE_DIR", t.TempDir())
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":     "module example.com/app\n\ngo 1.25\n",
+		"log/log.go": "package log\n\nimport \"fmt\"\n\nfunc Logf(format string, args ...any) {\n\tfmt.Printf(format, args...)\n}\n",
+		"main.go":    "package main\n\nimport \"example.com/app/log\"\n\nfunc main() {\n\tlog.Logf(\"%d\", \"text\")\n}\n",
+	})
+
+	t.Chdir(dir)
+
+	caching := true
+	run := func() ([]rules.Issue, int64) {
+		t.Helper()
+
+		cfg := &rules.LinterOptions{
+			Linter: rules.LinterRules{
+				Use:       true,
+				Issues:    &rules.LinterIssuesOptions{},
+				Analyzers: map[string]*rules.LinterBaseRule{"printf": {}},
+			},
+			Performance: &rules.PerformanceOptions{Use: true, Caching: &caching},
+		}
+
+		l := New(false, false, cfg, 0, 0)
+		issues, err := l.ProcessPath(dir)
+		if err != nil {
+			t.Fatalf("ProcessPath failed: %v", err)
+		}
+
+		return issues, l.Cache.run.hits.Load()
+	}
+
+	first, _ := run()
+	if len(first) != 1 {
+		t.Fatalf("expected one printf diagnostic, got %+v", first)
+	}
+
+	second, hits := run()
+	if hits != 2 || len(second) != 1 || rules.FormatMessage(second[0]) != rules.FormatMessage(first[0]) {
+		t.Fatalf("expected both packages and the finding to come from the cache, got %d hits and %+v", hits, second)
+	}
+
+	writeFiles(t, dir, map[string]string{
+		"log/log.go": "package log\n\nfunc Logf(format string, args ...any) {}\n",
+	})
+
+	third, hits := run()
+	if hits != 0 || len(third) != 0 {
+		t.Fatalf("expected the importer to be checked again once Logf stopped wrapping Printf, got %d hits and %+v", hits, third)
+	}
+}
+
+func TestNew_OnlyAnalyzersWithFixesMakeRunsMutating(t *testing.T) {
+	newConfig := func(analyzer string) *rules.LinterOptions {
+		return &rules.LinterOptions{
+			Linter: rules.LinterRules{
+				Use:       true,
+				Analyzers: map[string]*rules.LinterBaseRule{analyzer: {}},
+			},
+		}
+	}
+
+	if l := New(true, false, newConfig("nilness"), 5, 0); l.ActiveRules.HasAutofixRules || l.MaxIssues != 5 {
+		t.Fatalf("expected an analyzer without fixes to keep the issue limit, got %d", l.MaxIssues)
+	}
+
+	if l := New(true, false, newConfig("assign"), 5, 0); !l.ActiveRules.HasAutofixRules || l.MaxIssues != 0 {
+		t.Fatalf("expected an analyzer with fixes to make --write runs mutating, got %d", l.MaxIssues)
+	}
+}
+
+// writeFiles writes files, keyed by their slash-separated path under dir,
+// creating the directories they need.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, src := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+}
+
+func TestProcessPath_AnalyzerFactsReachImporters(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":     "module example.com/app\n\ngo 1.25\n",
+		"log/log.go": "package log\n\nimport \"fmt\"\n\nfunc Logf(format string, args ...any) {\n\tfmt.Printf(format, args...)\n}\n",
+		"main.go":    "package main\n\nimport \"example.com/app/log\"\n\nfunc main() {\n\tlog.Logf(\"%d\", \"text\")\n}\n",
+	})
+
+	t.Chdir(dir)
+
+	cfg := &rules.LinterOptions{
+		Linter: rules.LinterRules{
+			Use:       true,
+			Issues:    &rules.LinterIssuesOptions{},
+			Analyzers: map[string]*rules.LinterBaseRule{"printf": {}},
+		},
+	}
+
+	issues, err := New(false, false, cfg, 0, 0).ProcessPath(dir)
+	if err != nil {
+		t.Fatalf("ProcessPath failed: %v", err)
+	}
+
+	if len(issues) != 1 || filepath.Base(issues[0].Path) != "main.go" || issues[0].Line != 6 {
+		t.Fatalf("expected printf to know log.Logf wraps fmt.Printf, got %+v", issues)
+	}
+}
@@ -0,0 +1,166 @@
+package linter
+
+import (
+	"go/format"
+	"go/token"
+	"path/filepath"
+	"sort"
+
+	"github.com/serenitysz/serenity/internal/analyzers"
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/render"
+	"github.com/serenitysz/serenity/internal/rules"
+	"golang.org/x/tools/go/analysis"
+)
+
+// runAnalyzers returns the findings of the analyzers in the package of params
+// by file.
+func (l *Linter) runAnalyzers(params AnalysisParams) map[string][]analyzers.Finding {
+	if l.Analyzers == nil || len(params.pkgPaths) == 0 {
+		return nil
+	}
+
+	path := packageImportPath(params.module, filepath.Dir(params.pkgPaths[0]))
+
+	return findingsByPath(l.Analyzers.Run(params.fset, path, params.pkgFiles, params.facts))
+}
+
+func findingsByPath(findings []analyzers.Finding) map[string][]analyzers.Finding {
+	if len(findings) == 0 {
+		return nil
+	}
+
+	byPath := make(map[string][]analyzers.Finding, 4)
+	for _, finding := range findings {
+		byPath[finding.Issue.Path] = append(byPath[finding.Issue.Path], finding)
+	}
+
+	return byPath
+}
+
+type textEdit struct {
+	start, end int
+	text       []byte
+}
+
+// applyAnalyzerFixes applies the suggested fixes of unsuppressed findings to
+// src, the contents of path, and marks the findings it applied as fixed. A
+// fix is skipped when one of its edits overlaps an edit already accepted,
+// and reported when its edits do not fit the file.
+func (l *Linter) applyAnalyzerFixes(path string, src []byte, fset *token.FileSet, findings []analyzers.Finding, suppressions []rules.Suppression) error {
+	accepted := make([]textEdit, 0, len(findings))
+	fixed := make([]int, 0, len(findings))
+
+	for i, finding := range findings {
+		if len(finding.Edits) == 0 {
+			continue
+		}
+
+		if len(rules.FilterSuppressedIssues([]rules.Issue{finding.Issue}, suppressions)) == 0 {
+			continue
+		}
+
+		edits, ok := textEdits(path, len(src), fset, finding.Edits)
+		if !ok {
+			render.Warnf("%s:%d  not applying the fix of analyzer %s: its edits fall outside the file", path, finding.Issue.Line, rules.IssueRuleName(finding.Issue))
+			continue
+		}
+
+		if overlapsAny(edits, accepted) {
+			continue
+		}
+
+		accepted = append(accepted, edits...)
+		fixed = append(fixed, i)
+	}
+
+	if len(accepted) == 0 {
+		return nil
+	}
+
+	sort.Slice(accepted, func(i, j int) bool {
+		return accepted[i].start > accepted[j].start
+	})
+
+	for _, edit := range accepted {
+		next := make([]byte, 0, len(src)-(edit.end-edit.start)+len(edit.text))
+		next = append(next, src[:edit.start]...)
+		next = append(next, edit.text...)
+		src = append(next, src[edit.end:]...)
+	}
+
+	formatted, err := format.Source(src)
+	if err != nil {
+		return exception.InternalError("analyzer fixes left %q invalid: %w", path, err)
+	}
+
+	if err := l.Journal.Record(path); err != nil {
+		return exception.InternalError("could not back up %q before applying fixes: %w", path, err)
+	}
+
+	if err := writeFileAtomic(path, formatted); err != nil {
+		return exception.InternalError("could not write %q after applying fixes: %w", path, err)
+	}
+
+	for _, i := range fixed {
+		findings[i].Issue.Flags |= rules.IssueFixedFlags
+	}
+
+	return nil
+}
+
+// textEdits converts the edits of a fix to offsets in path, reporting false
+// when one is in another file or out of its bounds.
+func textEdits(path string, size int, fset *token.FileSet, edits []analysis.TextEdit) ([]textEdit, bool) {
+	out := make([]textEdit, 0, len(edits))
+
+	for _, edit := range edits {
+		end := edit.End
+		if !end.IsValid() {
+			end = edit.Pos
+		}
+
+		start, stop := fset.Position(edit.Pos), fset.Position(end)
+		if start.Filename != path || stop.Filename != path {
+			return nil, false
+		}
+
+		if start.Offset < 0 || stop.Offset > size || start.Offset > stop.Offset {
+			return nil, false
+		}
+
+		out = append(out, textEdit{start: start.Offset, end: stop.Offset, text: edit.NewText})
+	}
+
+	return out, true
+}
+
+// skippedAnalyzerFixes counts the findings with fixes that were not applied
+// because rules already rewrote the file.
+func skippedAnalyzerFixes(findings []analyzers.Finding, suppressions []rules.Suppression) int {
+	skipped := 0
+
+	for _, finding := range findings {
+		if len(finding.Edits) > 0 && len(rules.FilterSuppressedIssues([]rules.Issue{finding.Issue}, suppressions)) > 0 {
+			skipped++
+		}
+	}
+
+	return skipped
+}
+
+func overlapsAny(edits, accepted []textEdit) bool {
+	for _, edit := range edits {
+		for _, other := range accepted {
+			if edit.start < other.end && other.start < edit.end {
+				return true
+			}
+
+			if edit.start == edit.end && edit.start == other.start {
+				return true
+			}
+		}
+	}
+
+	return false
+}
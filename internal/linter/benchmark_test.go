@@ -2,6 +2,9 @@ package linter
 
 import (
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -274,7 +277,7 @@ func BenchmarkCacheMatrix(b *testing.B) {
 
 		var payload []byte
 		for range b.N {
-			payload = encodeCache(fixture.loadedInputs, fixture.issues, fixture.store.configHash)
+			payload = encodeCache(fixture.loadedInputs, fixture.issues, fixture.store.configHash, "")
 		}
 
 		if len(payload) == 0 {
@@ -293,7 +296,7 @@ func BenchmarkCacheMatrix(b *testing.B) {
 		var header cacheHeader
 		var err error
 		for range b.N {
-			header, err = validateCacheHeader(fixture.data, clonePackageInputs(fixture.probedInputs), fixture.store.configHash)
+			header, err = validateCacheHeader(fixture.data, clonePackageInputs(fixture.probedInputs), fixture.store.configHash, "")
 			if err != nil {
 				b.Fatalf("validateCacheHeader failed: %v", err)
 			}
@@ -359,7 +362,7 @@ func BenchmarkCacheMatrix(b *testing.B) {
 		var issues []rules.Issue
 		for range b.N {
 			var ok bool
-			issues, ok = decodeCachedIssues(fixture.data, fixture.header, fixture.probedInputs, 0)
+			issues, ok = decodeCachedIssues(fixture.data, fixture.header, fixture.probedInputs, "", 0)
 			if !ok {
 				b.Fatal("expected decode success")
 			}
@@ -381,7 +384,7 @@ func BenchmarkCacheMatrix(b *testing.B) {
 		var issues []rules.Issue
 		for range b.N {
 			var ok bool
-			issues, ok = decodeCachedIssues(fixture.data, fixture.header, fixture.probedInputs, 32)
+			issues, ok = decodeCachedIssues(fixture.data, fixture.header, fixture.probedInputs, "", 32)
 			if !ok {
 				b.Fatal("expected decode success")
 			}
@@ -432,7 +435,7 @@ func BenchmarkWalkPackagesMatrix(b *testing.B) {
 						jobCount = 0
 						fileCount = 0
 
-						if err := l.walkPackages(corpus.root, nil, func(job PackageJob) bool {
+						if err := l.walkPackages([]string{corpus.root}, nil, func(job PackageJob) bool {
 							jobCount++
 							fileCount += len(job.files)
 							return true
@@ -449,6 +452,83 @@ func BenchmarkWalkPackagesMatrix(b *testing.B) {
 	}
 }
 
+var dispatchBenchmarkTargets = []ast.Node{
+	(*ast.FuncDecl)(nil),
+	(*ast.CallExpr)(nil),
+	(*ast.Ident)(nil),
+	(*ast.SelectorExpr)(nil),
+	(*ast.AssignStmt)(nil),
+	(*ast.IfStmt)(nil),
+}
+
+func prepareDispatchNodes(tb testing.TB) []ast.Node {
+	tb.Helper()
+
+	spec := benchmarkCorpusSpec{packages: 1, filesPerPkg: 1, funcsPerFile: 12, lineLength: 120}
+	file, err := parser.ParseFile(token.NewFileSet(), "dispatch.go", benchmarkFileSource(spec, 0, 0), parser.ParseComments)
+	if err != nil {
+		tb.Fatalf("parse dispatch fixture: %v", err)
+	}
+
+	var nodes []ast.Node
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n != nil {
+			nodes = append(nodes, n)
+		}
+		return true
+	})
+
+	return nodes
+}
+
+func BenchmarkActiveRulesDispatch(b *testing.B) {
+	nodes := prepareDispatchNodes(b)
+
+	b.Run("lookup/strict", func(b *testing.B) {
+		active := BuildActiveRules(newBenchmarkConfig(benchmarkRuleModeStrict, 1, false))
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		dispatched := 0
+		for range b.N {
+			for _, node := range nodes {
+				dispatched += len(active.byKind[kindOf(node)])
+			}
+		}
+
+		b.StopTimer()
+		b.ReportMetric(float64(len(nodes)), "nodes/op")
+
+		if dispatched == 0 {
+			b.Fatal("expected nodes to be dispatched to rules")
+		}
+	})
+
+	b.Run("run/noop", func(b *testing.B) {
+		rule := &dispatchCountRule{targets: dispatchBenchmarkTargets}
+		var active ActiveRules
+		active.Add(rule)
+		runner := rules.Runner{}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for range b.N {
+			for _, node := range nodes {
+				active.Run(&runner, node)
+			}
+		}
+
+		b.StopTimer()
+		b.ReportMetric(float64(len(nodes)), "nodes/op")
+
+		if rule.calls == 0 {
+			b.Fatal("expected the rule to be dispatched")
+		}
+	})
+}
+
 func runProcessPathScenario(b *testing.B, corpus benchmarkCorpus, scenario processBenchmarkScenario) {
 	b.Helper()
 	b.ReportAllocs()
@@ -511,7 +591,7 @@ func prepareAnalyzeFixture(b *testing.B, spec benchmarkCorpusSpec, scenario anal
 		b.Fatalf("loadPackageInputs failed: %v", err)
 	}
 
-	pkgFiles, pkgPaths, fset, suppressions, complete := l.parsePackageInputs(inputs)
+	pkgFiles, pkgPaths, fset, suppressions, complete := l.rootScope().parsePackageInputs(inputs)
 	if !complete {
 		b.Fatal("expected benchmark package to parse without errors")
 	}
@@ -521,7 +601,7 @@ func prepareAnalyzeFixture(b *testing.B, spec benchmarkCorpusSpec, scenario anal
 		pkgPaths:     pkgPaths,
 		fset:         fset,
 		maxIssues:    scenario.maxIssues,
-		rules:        l.ActiveRules,
+		config:       l.rootScope(),
 		suppressions: suppressions,
 	}
 	if scenario.maxIssues > 0 {
@@ -585,12 +665,12 @@ func prepareCacheFixture(b *testing.B, spec benchmarkCorpusSpec) cacheFixture {
 		b.Fatalf("loadPackageInputs failed: %v", err)
 	}
 
-	pkgFiles, pkgPaths, fset, suppressions, complete := l.parsePackageInputs(loadedInputs)
+	pkgFiles, pkgPaths, fset, suppressions, complete := l.rootScope().parsePackageInputs(loadedInputs)
 	if !complete {
 		b.Fatal("expected cache benchmark package to parse without errors")
 	}
 
-	issues, err := l.analyzePackage(pkgFiles, pkgPaths, fset, suppressions, 0, nil)
+	issues, err := l.analyzePackage(pkgFiles, pkgPaths, fset, suppressions, 0, nil, packageScope{})
 	if err != nil {
 		b.Fatalf("analyzePackage failed: %v", err)
 	}
@@ -607,17 +687,14 @@ func prepareCacheFixture(b *testing.B, spec benchmarkCorpusSpec) cacheFixture {
 		b.Fatalf("probePackageInputs failed: %v", err)
 	}
 
-	entryPath, err := l.Cache.entryPath(probedInputs)
-	if err != nil {
-		b.Fatalf("entryPath failed: %v", err)
-	}
+	entryPath := l.Cache.local.(*localCacheBackend).path(l.Cache.entryKey(probedInputs) + ".bin")
 
 	data, err := os.ReadFile(entryPath)
 	if err != nil {
 		b.Fatalf("read cache entry failed: %v", err)
 	}
 
-	header, err := validateCacheHeader(data, clonePackageInputs(probedInputs), l.Cache.configHash)
+	header, err := validateCacheHeader(data, clonePackageInputs(probedInputs), l.Cache.configHash, "")
 	if err != nil {
 		b.Fatalf("validateCacheHeader failed: %v", err)
 	}
@@ -0,0 +1,465 @@
+package linter
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/build"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/serenitysz/serenity/internal/rules"
+)
+
+// buildTarget decides which files of a directory belong to a build, the way
+// the go command does for one GOOS/GOARCH and set of -tags.
+type buildTarget struct {
+	goos   string
+	goarch string
+	tags   map[string]struct{}
+}
+
+func newBuildTargets(config []rules.BuildTarget) []buildTarget {
+	targets := make([]buildTarget, 0, len(config))
+
+	for _, target := range config {
+		t := buildTarget{
+			goos:   target.GOOS,
+			goarch: target.GOARCH,
+			tags:   make(map[string]struct{}, len(target.Tags)),
+		}
+
+		if t.goos == "" {
+			t.goos = runtime.GOOS
+		}
+		if t.goarch == "" {
+			t.goarch = runtime.GOARCH
+		}
+
+		for _, tag := range target.Tags {
+			t.tags[tag] = struct{}{}
+		}
+
+		targets = append(targets, t)
+	}
+
+	return targets
+}
+
+func (t buildTarget) matches(name string, header fileHeader) bool {
+	return t.matchFileName(name) && (header.constraint == nil || header.constraint.Eval(t.hasTag))
+}
+
+// matchFileName applies the _GOOS, _GOARCH and _GOOS_GOARCH file name
+// suffixes.
+func (t buildTarget) matchFileName(name string) bool {
+	name = strings.TrimSuffix(name, ".go")
+	name = strings.TrimSuffix(name, "_test")
+
+	i := strings.IndexByte(name, '_')
+	if i < 0 {
+		return true
+	}
+
+	parts := strings.Split(name[i:], "_")
+	n := len(parts)
+
+	if n >= 2 && knownOS[parts[n-2]] && knownArch[parts[n-1]] {
+		return t.hasTag(parts[n-2]) && t.hasTag(parts[n-1])
+	}
+
+	if knownOS[parts[n-1]] || knownArch[parts[n-1]] {
+		return t.hasTag(parts[n-1])
+	}
+
+	return true
+}
+
+func (t buildTarget) hasTag(tag string) bool {
+	switch tag {
+	case t.goos, t.goarch, "gc":
+		return true
+	case "unix":
+		return unixOS[t.goos]
+	case "linux":
+		return t.goos == "android"
+	case "solaris":
+		return t.goos == "illumos"
+	case "darwin":
+		return t.goos == "ios"
+	case "cgo":
+		if t.goos == runtime.GOOS && t.goarch == runtime.GOARCH && build.Default.CgoEnabled {
+			return true
+		}
+	}
+
+	if _, ok := t.tags[tag]; ok {
+		return true
+	}
+
+	return slices.Contains(build.Default.ReleaseTags, tag)
+}
+
+// fileHeader is what decides which package and builds a file belongs to.
+type fileHeader struct {
+	pkgName    string
+	constraint constraint.Expr // nil when the file has no build constraint
+}
+
+// headerReadSize covers the license comment and constraints of almost every
+// file, so only the start of each file is read while walking.
+const headerReadSize = 4096
+
+func readFileHeader(path string) (fileHeader, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return fileHeader{}, false
+	}
+
+	buf := make([]byte, headerReadSize)
+	n, err := io.ReadFull(f, buf)
+	_ = f.Close()
+
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return fileHeader{}, false
+	}
+
+	if header, ok := parseFileHeader(path, buf[:n], n < len(buf)); ok {
+		return header, true
+	}
+
+	if n < len(buf) {
+		return fileHeader{}, false
+	}
+
+	// The package clause is past the first block; read the whole file.
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return fileHeader{}, false
+	}
+
+	return parseFileHeader(path, src, true)
+}
+
+func parseFileHeader(path string, src []byte, complete bool) (fileHeader, bool) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, path, src, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil || file.Name == nil {
+		return fileHeader{}, false
+	}
+
+	// A name running up to the end of a partial read may be cut short.
+	if !complete && fset.Position(file.Name.End()).Offset >= len(src) {
+		return fileHeader{}, false
+	}
+
+	return fileHeader{pkgName: file.Name.Name, constraint: fileConstraint(file)}, true
+}
+
+// fileConstraint returns the //go:build expression of file, or the
+// conjunction of its // +build lines when it has none.
+func fileConstraint(file *ast.File) constraint.Expr {
+	var plusBuild constraint.Expr
+
+	for _, group := range file.Comments {
+		if group.End() >= file.Package {
+			break
+		}
+
+		for _, comment := range group.List {
+			switch {
+			case constraint.IsGoBuild(comment.Text):
+				if expr, err := constraint.Parse(comment.Text); err == nil {
+					return expr
+				}
+			case constraint.IsPlusBuild(comment.Text):
+				expr, err := constraint.Parse(comment.Text)
+				if err != nil {
+					continue
+				}
+
+				if plusBuild == nil {
+					plusBuild = expr
+				} else {
+					plusBuild = &constraint.AndExpr{X: plusBuild, Y: expr}
+				}
+			}
+		}
+	}
+
+	return plusBuild
+}
+
+// packageGroup is the package name and files, by index, of one job of a
+// directory.
+type packageGroup struct {
+	Name  string `json:"name"`
+	Files []int  `json:"files"`
+}
+
+// splitPackages groups the Go files of dir by package clause and returns one
+// job for every distinct set of files the build targets select. Inputs, when
+// set, are parallel to files and let the grouping be reused from the cache
+// while no file changed, sparing a read of every file header.
+func (l *Linter) splitPackages(dir string, files []string, inputs []packageInput) []PackageJob {
+	groups, ok := l.Cache.loadLayout(inputs)
+	if !ok {
+		groups = l.groupPackages(files)
+		l.Cache.saveLayout(inputs, groups)
+	}
+
+	jobs := make([]PackageJob, 0, len(groups))
+	for _, group := range groups {
+		jobs = append(jobs, newPackageJob(dir, group.Name, group.Files, files, inputs))
+	}
+
+	return jobs
+}
+
+// groupPackages reads the header of files to group them. Files whose header
+// cannot be read stay with the first package so the parse error is still
+// reported.
+func (l *Linter) groupPackages(files []string) []packageGroup {
+	targets := l.Targets
+	if len(targets) == 0 {
+		targets = newBuildTargets([]rules.BuildTarget{{}})
+	}
+
+	headers := make([]fileHeader, len(files))
+	var unreadable []int
+
+	for i, path := range files {
+		header, ok := readFileHeader(path)
+		if !ok {
+			unreadable = append(unreadable, i)
+			continue
+		}
+
+		headers[i] = header
+	}
+
+	var groups []packageGroup
+	seen := make(map[string]struct{}, 2)
+
+	for _, target := range targets {
+		byName := make(map[string][]int, 2)
+		names := make([]string, 0, 2)
+
+		for i, path := range files {
+			if headers[i].pkgName == "" || !target.matches(filepath.Base(path), headers[i]) {
+				continue
+			}
+
+			name := headers[i].pkgName
+			if _, ok := byName[name]; !ok {
+				names = append(names, name)
+			}
+			byName[name] = append(byName[name], i)
+		}
+
+		// External test packages come after the package they test.
+		sort.SliceStable(names, func(i, j int) bool {
+			return !strings.HasSuffix(names[i], "_test") && strings.HasSuffix(names[j], "_test")
+		})
+
+		if len(names) == 0 && len(unreadable) > 0 {
+			names = append(names, "")
+		}
+
+		if len(names) > 0 && len(unreadable) > 0 {
+			first := names[0]
+			byName[first] = append(byName[first], unreadable...)
+			sort.Ints(byName[first])
+		}
+
+		for _, name := range names {
+			indexes := byName[name]
+
+			key := make([]byte, 0, len(name)+len(indexes)*4)
+			key = append(key, name...)
+			for _, i := range indexes {
+				key = append(key, 0, byte(i>>16), byte(i>>8), byte(i))
+			}
+
+			if _, ok := seen[string(key)]; ok {
+				continue
+			}
+			seen[string(key)] = struct{}{}
+
+			groups = append(groups, packageGroup{Name: name, Files: indexes})
+		}
+	}
+
+	return groups
+}
+
+// cachedLayout is the grouping of a directory's files, valid while their
+// stamps are unchanged.
+type cachedLayout struct {
+	Stamps []layoutStamp  `json:"stamps"`
+	Groups []packageGroup `json:"groups"`
+}
+
+type layoutStamp struct {
+	Size       int64  `json:"size"`
+	ModTime    int64  `json:"modTime"`
+	ChangeTime int64  `json:"changeTime"`
+	Device     uint64 `json:"device"`
+	Inode      uint64 `json:"inode"`
+}
+
+func stampOf(input packageInput) layoutStamp {
+	return layoutStamp{
+		Size:       input.Size,
+		ModTime:    input.ModTimeUnixNano,
+		ChangeTime: input.ChangeTimeUnixNano,
+		Device:     input.Device,
+		Inode:      input.Inode,
+	}
+}
+
+// layoutKey is the key of the layout of inputs. Build targets are part of the
+// config the key derives from.
+func (c *cacheStore) layoutKey(inputs []packageInput) string {
+	return c.derive("layout", "v1").entryKey(inputs) + ".bin"
+}
+
+func (c *cacheStore) loadLayout(inputs []packageInput) ([]packageGroup, bool) {
+	if !c.enabledForRun() || len(inputs) == 0 {
+		return nil, false
+	}
+
+	for _, input := range inputs {
+		if !input.FastPathSupported {
+			return nil, false
+		}
+	}
+
+	data, err := c.local.Get(c.layoutKey(inputs))
+	if err != nil {
+		return nil, false
+	}
+
+	var layout cachedLayout
+	if err := json.Unmarshal(data, &layout); err != nil || len(layout.Stamps) != len(inputs) {
+		return nil, false
+	}
+
+	for i, input := range inputs {
+		if layout.Stamps[i] != stampOf(input) {
+			return nil, false
+		}
+	}
+
+	for _, group := range layout.Groups {
+		for _, i := range group.Files {
+			if i < 0 || i >= len(inputs) {
+				return nil, false
+			}
+		}
+	}
+
+	return layout.Groups, true
+}
+
+func (c *cacheStore) saveLayout(inputs []packageInput, groups []packageGroup) {
+	if !c.enabledForRun() || len(inputs) == 0 {
+		return
+	}
+
+	layout := cachedLayout{Stamps: make([]layoutStamp, len(inputs)), Groups: groups}
+	for i, input := range inputs {
+		layout.Stamps[i] = stampOf(input)
+	}
+
+	data, err := json.Marshal(layout)
+	if err != nil {
+		return
+	}
+
+	if c.local.Put(c.layoutKey(inputs), data) == nil {
+		c.run.written.Add(int64(len(data)))
+	}
+}
+
+// lockDir serializes the jobs of dir when several build targets select its
+// files and fixes are applied, so two workers never rewrite a file at once.
+// The returned function unlocks it.
+func (l *Linter) lockDir(dir string) func() {
+	mu, _ := l.dirLocks.LoadOrStore(dir, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+
+	return mu.(*sync.Mutex).Unlock
+}
+
+func newPackageJob(dir, name string, indexes []int, files []string, inputs []packageInput) PackageJob {
+	job := PackageJob{dirPath: dir, pkgName: name, files: make([]string, 0, len(indexes))}
+
+	if len(inputs) > 0 {
+		job.inputs = make([]packageInput, 0, len(indexes))
+	}
+
+	for _, i := range indexes {
+		job.files = append(job.files, files[i])
+
+		if len(inputs) > 0 {
+			job.inputs = append(job.inputs, inputs[i])
+		}
+	}
+
+	if len(job.inputs) > 1 {
+		sort.Slice(job.inputs, func(i, j int) bool {
+			return job.inputs[i].NormalizedPath < job.inputs[j].NormalizedPath
+		})
+	}
+
+	return job
+}
+
+// dedupeIssues drops the copies reported for files shared by the jobs of
+// several build targets, keeping the first.
+func dedupeIssues(issues []rules.Issue) []rules.Issue {
+	seen := make(map[rules.Issue]struct{}, len(issues))
+	out := issues[:0]
+
+	for _, issue := range issues {
+		if _, ok := seen[issue]; ok {
+			continue
+		}
+
+		seen[issue] = struct{}{}
+		out = append(out, issue)
+	}
+
+	return out
+}
+
+var knownOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+	"hurd": true, "illumos": true, "ios": true, "js": true, "linux": true, "nacl": true,
+	"netbsd": true, "openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+	"windows": true, "zos": true,
+}
+
+var unixOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+	"hurd": true, "illumos": true, "ios": true, "linux": true, "netbsd": true,
+	"openbsd": true, "solaris": true,
+}
+
+var knownArch = map[string]bool{
+	"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true,
+	"arm64be": true, "loong64": true, "mips": true, "mipsle": true, "mips64": true,
+	"mips64le": true, "mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+	"ppc64le": true, "riscv": true, "riscv64": true, "s390": true, "s390x": true,
+	"sparc": true, "sparc64": true, "wasm": true,
+}
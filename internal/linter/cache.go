@@ -6,12 +6,16 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 	"unsafe"
 
+	"github.com/serenitysz/serenity/internal/render"
 	"github.com/serenitysz/serenity/internal/rules"
 	"github.com/serenitysz/serenity/internal/version"
 )
@@ -24,11 +28,30 @@ const (
 	cacheSampleWindows = 4
 )
 
+// errFixesPending is why a run applying fixes does not reuse an entry with
+// fixable issues.
+var errFixesPending = errors.New("entry has fixes to apply")
+
 type cacheStore struct {
 	enabled    bool
 	dir        string
 	configHash string
 	mutating   bool
+	maxSize    int64
+
+	// root is the directory of the module linted. Entries have paths relative
+	// to it, so they match wherever the module is checked out.
+	root   string
+	local  CacheBackend
+	remote CacheBackend // Keyed by content, nil without a remote cache
+
+	// Set by withExpiry: the next until= date the entry depends on, as saved
+	// beside it, and the store it was derived from.
+	expiry     string
+	expiryBase *cacheStore
+
+	// Shared by the stores derived from this one.
+	run *cacheCounters
 }
 
 type packageInput struct {
@@ -66,22 +89,49 @@ func newCacheStore(cfg *rules.LinterOptions, mutating, unsafe bool) *cacheStore
 		return &cacheStore{}
 	}
 
-	dir, err := resolveCacheDir()
+	dir, err := ResolveCacheDir(cfg)
 	if err != nil || dir == "" {
 		return &cacheStore{}
 	}
 
-	return &cacheStore{
+	maxSize := int64(defaultCacheMaxSize)
+	if perf.CacheMaxSize != nil {
+		maxSize = *perf.CacheMaxSize
+	}
+
+	store := &cacheStore{
 		enabled:    true,
 		dir:        dir,
 		configHash: cacheConfigHash(cfg),
 		mutating:   mutating,
+		maxSize:    maxSize,
+		local:      NewLocalCacheBackend(dir),
+		run:        &cacheCounters{},
 	}
+
+	remote := perf.RemoteCache
+	if url := os.Getenv("SERENITY_REMOTE_CACHE"); url != "" {
+		remote = url
+	}
+
+	if remote != "" {
+		store.remote = NewHTTPCacheBackend(remote, os.Getenv("SERENITY_REMOTE_CACHE_TOKEN"))
+	}
+
+	return store
 }
 
-func resolveCacheDir() (string, error) {
+// ResolveCacheDir returns the directory of the lint cache: SERENITY_CACHE_DIR,
+// performance.cacheDir or the user cache directory, in that order. The first
+// two get a subdirectory of their own, so a cache set to a project directory
+// never mixes its files with the project's.
+func ResolveCacheDir(cfg *rules.LinterOptions) (string, error) {
 	if dir := os.Getenv("SERENITY_CACHE_DIR"); dir != "" {
-		return dir, nil
+		return filepath.Join(dir, cacheSubdir), nil
+	}
+
+	if cfg != nil && cfg.Performance != nil && cfg.Performance.CacheDir != "" {
+		return filepath.Join(cfg.Performance.CacheDir, cacheSubdir), nil
 	}
 
 	base, err := os.UserCacheDir()
@@ -116,47 +166,200 @@ func cacheConfigHash(cfg *rules.LinterOptions) string {
 	return hex.EncodeToString(sum[:])
 }
 
+func (c *cacheStore) extendConfigHash(extra string) {
+	if !c.enabledForRun() || extra == "" {
+		return
+	}
+
+	sum := sha256.Sum256([]byte(c.configHash + ":" + extra))
+	c.configHash = hex.EncodeToString(sum[:])
+}
+
 func (c *cacheStore) enabledForRun() bool {
 	return c != nil && c.enabled && c.dir != ""
 }
 
-func (c *cacheStore) entryPath(inputs []packageInput) (string, error) {
+// withFacts returns a store whose entries also depend on digest, the facts a
+// package imported, so a change in a dependency's facts misses the cache.
+func (c *cacheStore) withFacts(digest string) *cacheStore {
+	return c.derive("facts", digest)
+}
+
+// withModule returns a store whose entries also depend on the module path and
+// go version, which decide the rules a package runs with.
+func (c *cacheStore) withModule(mod *rules.Module) *cacheStore {
+	if mod == nil || !c.enabledForRun() {
+		return c
+	}
+
+	derived := c.derive("module", mod.Path+"@"+mod.GoVersion)
+	derived.root = mod.Dir
+
+	return derived
+}
+
+// withExpiry returns the store of the entry for inputs, keyed by the next
+// until= date of its suppressions, which is saved beside it. Results only
+// change once that date passes, and from then on the key is one no entry is
+// saved under, so the package is linted again.
+func (c *cacheStore) withExpiry(inputs []packageInput, today string) *cacheStore {
+	if !c.enabledForRun() || len(inputs) == 0 {
+		return c
+	}
+
+	var expiry string
+	if data, err := c.local.Get(c.expiryKey(inputs)); err == nil {
+		expiry = string(data)
+	}
+
+	key := expiry
+	if expiry != "" && expiry < today {
+		key = expiry + " passed on " + today
+	}
+
+	derived := *c.derive("until", key)
+	derived.expiry = expiry
+	derived.expiryBase = c
+
+	return &derived
+}
+
+// untilNext returns the store to save the entry for inputs in, keyed by next,
+// the earliest until= date still to pass, and records it beside the entry.
+// It returns nil, saving nothing, when the date cannot be recorded.
+func (c *cacheStore) untilNext(inputs []packageInput, next string) *cacheStore {
+	base := c.expiryBase
+	if base == nil {
+		return c
+	}
+
+	if next != c.expiry {
+		if err := base.local.Put(base.expiryKey(inputs), []byte(next)); err != nil {
+			return nil
+		}
+	}
+
+	derived := *base.derive("until", next)
+	derived.expiry = next
+	derived.expiryBase = base
+
+	return &derived
+}
+
+func (c *cacheStore) expiryKey(inputs []packageInput) string {
+	return c.derive("expiry", "v1").entryKey(inputs) + ".bin"
+}
+
+func (c *cacheStore) derive(kind, value string) *cacheStore {
+	if !c.enabledForRun() || value == "" {
+		return c
+	}
+
+	clone := *c
+	sum := sha256.Sum256([]byte(c.configHash + ":" + kind + ":" + value))
+	clone.configHash = hex.EncodeToString(sum[:])
+
+	return &clone
+}
+
+func (c *cacheStore) entryKey(inputs []packageInput) string {
 	key := sha256.New()
 
 	_, _ = key.Write([]byte(c.configHash))
 	_, _ = key.Write([]byte{0})
 
 	for _, input := range inputs {
-		_, _ = key.Write([]byte(input.NormalizedPath))
+		_, _ = key.Write([]byte(moduleRelative(c.root, input.NormalizedPath)))
 		_, _ = key.Write([]byte{0})
 	}
 
-	sum := hex.EncodeToString(key.Sum(nil))
-	dir := filepath.Join(c.dir, sum[:2])
+	return hex.EncodeToString(key.Sum(nil))
+}
 
-	return filepath.Join(dir, sum+".bin"), nil
+// contentKey is the key of the entry for inputs in the remote cache. Unlike
+// entryKey it depends on their content, so machines sharing the cache do not
+// overwrite each other's entries. The sources must be loaded.
+func (c *cacheStore) contentKey(inputs []packageInput) string {
+	key := sha256.New()
+
+	_, _ = key.Write([]byte(c.configHash))
+	_, _ = key.Write([]byte{0})
+
+	for _, input := range inputs {
+		_, _ = key.Write([]byte(moduleRelative(c.root, input.NormalizedPath)))
+		_, _ = key.Write([]byte{0})
+		_, _ = key.Write(input.Hash[:])
+	}
+
+	return hex.EncodeToString(key.Sum(nil))
 }
 
-func (c *cacheStore) load(inputs []packageInput, limit int) ([]rules.Issue, bool) {
-	data, header, ok := c.loadValidated(inputs)
-	if !ok {
+// loadFacts returns the facts saved with the cache entry for inputs. Facts
+// are only valid alongside a validated entry.
+func (c *cacheStore) loadFacts(inputs []packageInput) (rules.PackageFacts, bool) {
+	if !c.enabledForRun() || len(inputs) == 0 {
 		return nil, false
 	}
 
-	if !c.canReuse(header) {
+	defer c.run.loadedSince(time.Now())
+
+	key := c.entryKey(inputs) + ".json"
+
+	data, err := c.local.Get(key)
+	if err != nil && c.remote != nil && loadPackageSources(inputs) == nil {
+		if data, err = c.remote.Get(c.contentKey(inputs) + ".json"); err == nil {
+			_ = c.local.Put(key, data)
+		}
+	}
+	if err != nil {
+		return nil, false
+	}
+
+	var facts rules.PackageFacts
+	if err := json.Unmarshal(data, &facts); err != nil {
 		return nil, false
 	}
 
-	return decodeCachedIssues(data, header, inputs, limit)
+	return facts, true
 }
 
-func (c *cacheStore) loadRaw(inputs []packageInput) (*cachedBatch, bool) {
-	data, header, ok := c.loadValidated(inputs)
-	if !ok {
+func (c *cacheStore) saveFacts(inputs []packageInput, facts rules.PackageFacts) error {
+	if !c.enabledForRun() || len(inputs) == 0 {
+		return nil
+	}
+
+	defer c.run.savedSince(time.Now())
+
+	data, err := json.Marshal(facts)
+	if err != nil {
+		return err
+	}
+
+	if err := c.local.Put(c.entryKey(inputs)+".json", data); err != nil {
+		return err
+	}
+
+	c.run.written.Add(int64(len(data)))
+
+	return c.putRemote(inputs, ".json", data)
+}
+
+func (c *cacheStore) load(inputs []packageInput, limit int) ([]rules.Issue, bool) {
+	defer c.run.loadedSince(time.Now())
+
+	data, header, err := c.loadValidated(inputs)
+	if !c.record(inputs, err) {
 		return nil, false
 	}
 
-	if !c.canReuse(header) {
+	return decodeCachedIssues(data, header, inputs, c.root, limit)
+}
+
+func (c *cacheStore) loadRaw(inputs []packageInput) (*cachedBatch, bool) {
+	defer c.run.loadedSince(time.Now())
+
+	data, header, err := c.loadValidated(inputs)
+	if !c.record(inputs, err) {
 		return nil, false
 	}
 
@@ -165,34 +368,92 @@ func (c *cacheStore) loadRaw(inputs []packageInput) (*cachedBatch, bool) {
 		issueCount: header.IssueCount,
 		issueStart: header.IssueStart,
 		inputs:     inputs,
+		root:       c.root,
 	}, true
 }
 
-func (c *cacheStore) canReuse(header cacheHeader) bool {
-	return !c.mutating || header.FixableCount == 0
+func (c *cacheStore) canReuse(header cacheHeader) error {
+	if c.mutating && header.FixableCount > 0 {
+		return errFixesPending
+	}
+
+	return nil
 }
 
-func (c *cacheStore) loadValidated(inputs []packageInput) ([]byte, cacheHeader, bool) {
+// loadValidated returns the entry of inputs, or why there is none to reuse.
+func (c *cacheStore) loadValidated(inputs []packageInput) ([]byte, cacheHeader, error) {
 	if !c.enabledForRun() || len(inputs) == 0 {
-		return nil, cacheHeader{}, false
+		return nil, cacheHeader{}, ErrCacheMiss
 	}
 
-	path, err := c.entryPath(inputs)
-	if err != nil {
-		return nil, cacheHeader{}, false
+	key := c.entryKey(inputs) + ".bin"
+
+	data, err := c.local.Get(key)
+	if err == nil {
+		var header cacheHeader
+		if header, err = validateCacheHeader(data, inputs, c.configHash, c.root); err == nil {
+			if err = c.canReuse(header); err == nil {
+				return data, header, nil
+			}
+		}
 	}
 
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, cacheHeader{}, false
+	if c.remote == nil || errors.Is(err, errFixesPending) {
+		return nil, cacheHeader{}, err
+	}
+
+	if err := loadPackageSources(inputs); err != nil {
+		return nil, cacheHeader{}, err
+	}
+
+	data, remoteErr := c.remote.Get(c.contentKey(inputs) + ".bin")
+	if errors.Is(remoteErr, ErrCacheMiss) {
+		return nil, cacheHeader{}, err
+	}
+	if remoteErr != nil {
+		return nil, cacheHeader{}, remoteErr
 	}
 
-	header, err := validateCacheHeader(data, inputs, c.configHash)
+	header, err := validateCacheHeader(data, inputs, c.configHash, c.root)
+	if err == nil {
+		err = c.canReuse(header)
+	}
 	if err != nil {
-		return nil, cacheHeader{}, false
+		return nil, cacheHeader{}, fmt.Errorf("remote cache: %w", err)
+	}
+
+	// The entry was written on another machine; with the local file stamps
+	// the next runs validate it without reading the sources.
+	data, header = restampCache(data, header, inputs, c.configHash, c.root)
+
+	if err := c.local.Put(key, data); err == nil {
+		c.run.written.Add(int64(len(data)))
 	}
 
-	return data, header, true
+	return data, header, nil
+}
+
+// record counts a lookup of inputs in the run's stats and returns whether
+// it hit, logging why it did not with -v.
+func (c *cacheStore) record(inputs []packageInput, err error) bool {
+	if !c.enabledForRun() {
+		return err == nil
+	}
+
+	dir := filepath.Dir(inputs[0].Path)
+
+	if err == nil {
+		c.run.hits.Add(1)
+		render.Debugf("cache hit for %s", dir)
+	} else if errors.Is(err, ErrCacheMiss) {
+		c.run.misses.Add(1)
+		render.Debugf("cache miss for %s: no entry", dir)
+	} else {
+		c.run.misses.Add(1)
+		render.Debugf("cache miss for %s: %v", dir, err)
+	}
+
+	return err == nil
 }
 
 func (c *cacheStore) save(inputs []packageInput, issues []rules.Issue) error {
@@ -200,39 +461,54 @@ func (c *cacheStore) save(inputs []packageInput, issues []rules.Issue) error {
 		return nil
 	}
 
-	path, err := c.entryPath(inputs)
-	if err != nil {
-		return err
-	}
+	defer c.run.savedSince(time.Now())
+
+	payload := encodeCache(inputs, issues, c.configHash, c.root)
 
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+	if err := c.local.Put(c.entryKey(inputs)+".bin", payload); err != nil {
 		return err
 	}
 
-	payload := encodeCache(inputs, issues, c.configHash)
+	c.run.writes.Add(1)
+	c.run.written.Add(int64(len(payload)))
 
-	tmp, err := os.CreateTemp(filepath.Dir(path), "*.tmp")
-	if err != nil {
+	return c.putRemote(inputs, ".bin", payload)
+}
+
+func (c *cacheStore) putRemote(inputs []packageInput, ext string, data []byte) error {
+	if c.remote == nil {
+		return nil
+	}
+
+	if err := loadPackageSources(inputs); err != nil {
 		return err
 	}
 
-	_, writeErr := tmp.Write(payload)
-	closeErr := tmp.Close()
-	if writeErr != nil {
-		_ = os.Remove(tmp.Name())
-		return writeErr
+	return c.remote.Put(c.contentKey(inputs)+ext, data)
+}
+
+// moduleRelative returns path relative to root with forward slashes, or path
+// itself when root is empty or does not contain it.
+func moduleRelative(root, path string) string {
+	if root == "" {
+		return path
 	}
-	if closeErr != nil {
-		_ = os.Remove(tmp.Name())
-		return closeErr
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
 	}
 
-	if err := os.Rename(tmp.Name(), path); err != nil {
-		_ = os.Remove(tmp.Name())
-		return err
+	return filepath.ToSlash(rel)
+}
+
+// moduleAbsolute undoes moduleRelative.
+func moduleAbsolute(root, path string) string {
+	if root == "" || filepath.IsAbs(path) {
+		return path
 	}
 
-	return nil
+	return filepath.Join(root, filepath.FromSlash(path))
 }
 
 func probePackageInputs(paths []string) ([]packageInput, error) {
@@ -317,26 +593,15 @@ func matchesFastPath(input packageInput, cached packageInput) bool {
 		input.Inode == cached.Inode
 }
 
-func encodeCache(inputs []packageInput, issues []rules.Issue, configHash string) []byte {
+func encodeCache(inputs []packageInput, issues []rules.Issue, configHash, root string) []byte {
 	buf := make([]byte, 0, estimateCacheSize(inputs, issues, configHash))
 	pathIndex := make(map[string]uint64, len(inputs))
 
-	buf = append(buf, cacheMagic...)
-	buf = appendString(buf, configHash)
-	buf = appendUvarint(buf, uint64(len(inputs)))
-
 	for i, input := range inputs {
 		pathIndex[input.NormalizedPath] = uint64(i + 1)
-		buf = appendString(buf, input.NormalizedPath)
-		buf = appendVarint(buf, input.Size)
-		buf = appendVarint(buf, input.ModTimeUnixNano)
-		buf = appendVarint(buf, input.ChangeTimeUnixNano)
-		buf = appendUvarint(buf, input.Device)
-		buf = appendUvarint(buf, input.Inode)
-		buf = append(buf, input.Hash[:]...)
-		buf = append(buf, input.SampleHash[:]...)
 	}
 
+	buf = appendCacheInputs(buf, inputs, configHash, root)
 	buf = appendUvarint(buf, uint64(len(issues)))
 	buf = appendUvarint(buf, uint64(countFixableIssues(issues)))
 
@@ -346,7 +611,7 @@ func encodeCache(inputs []packageInput, issues []rules.Issue, configHash string)
 
 		buf = appendUvarint(buf, ref)
 		if ref == 0 {
-			buf = appendString(buf, normalizedPath)
+			buf = appendString(buf, moduleRelative(root, normalizedPath))
 		}
 
 		buf = appendUvarint(buf, uint64(issue.Line))
@@ -361,7 +626,39 @@ func encodeCache(inputs []packageInput, issues []rules.Issue, configHash string)
 	return buf
 }
 
-func validateCacheHeader(data []byte, inputs []packageInput, configHash string) (cacheHeader, error) {
+func appendCacheInputs(buf []byte, inputs []packageInput, configHash, root string) []byte {
+	buf = append(buf, cacheMagic...)
+	buf = appendString(buf, configHash)
+	buf = appendUvarint(buf, uint64(len(inputs)))
+
+	for _, input := range inputs {
+		buf = appendString(buf, moduleRelative(root, input.NormalizedPath))
+		buf = appendVarint(buf, input.Size)
+		buf = appendVarint(buf, input.ModTimeUnixNano)
+		buf = appendVarint(buf, input.ChangeTimeUnixNano)
+		buf = appendUvarint(buf, input.Device)
+		buf = appendUvarint(buf, input.Inode)
+		buf = append(buf, input.Hash[:]...)
+		buf = append(buf, input.SampleHash[:]...)
+	}
+
+	return buf
+}
+
+// restampCache replaces the file stamps of a validated entry with those of
+// inputs, keeping its issues.
+func restampCache(data []byte, header cacheHeader, inputs []packageInput, configHash, root string) ([]byte, cacheHeader) {
+	buf := make([]byte, 0, len(data)+len(inputs)*16)
+	buf = appendCacheInputs(buf, inputs, configHash, root)
+	buf = appendUvarint(buf, uint64(header.IssueCount))
+	buf = appendUvarint(buf, uint64(header.FixableCount))
+
+	header.IssueStart, buf = len(buf), append(buf, data[header.IssueStart:]...)
+
+	return buf, header
+}
+
+func validateCacheHeader(data []byte, inputs []packageInput, configHash, root string) (cacheHeader, error) {
 	dec := cacheDecoder{data: data}
 	header := cacheHeader{}
 
@@ -396,7 +693,7 @@ func validateCacheHeader(data []byte, inputs []packageInput, configHash string)
 		if err != nil {
 			return header, err
 		}
-		if path != input.NormalizedPath {
+		if path != moduleRelative(root, input.NormalizedPath) {
 			return header, errors.New("unexpected cache path")
 		}
 		size, err := dec.readVarint()
@@ -450,7 +747,7 @@ func validateCacheHeader(data []byte, inputs []packageInput, configHash string)
 			return header, err
 		}
 		if input.Hash != hash {
-			return header, errors.New("stale cache content")
+			return header, fmt.Errorf("stale cache content of %s", path)
 		}
 	}
 
@@ -470,7 +767,7 @@ func validateCacheHeader(data []byte, inputs []packageInput, configHash string)
 	return header, nil
 }
 
-func decodeCachedIssues(data []byte, header cacheHeader, inputs []packageInput, limit int) ([]rules.Issue, bool) {
+func decodeCachedIssues(data []byte, header cacheHeader, inputs []packageInput, root string, limit int) ([]rules.Issue, bool) {
 	dec := cacheDecoder{data: data, off: header.IssueStart}
 	target := header.IssueCount
 	if limit > 0 && limit < target {
@@ -496,6 +793,7 @@ func decodeCachedIssues(data []byte, header cacheHeader, inputs []packageInput,
 			if err != nil {
 				return nil, false
 			}
+			path = moduleAbsolute(root, path)
 		}
 
 		line, err := dec.readUvarint()
@@ -575,6 +873,7 @@ func decodeCachedIssuesInto(dst []rules.Issue, batch *cachedBatch) (int, bool) {
 			if err != nil {
 				return count, false
 			}
+			path = moduleAbsolute(batch.root, path)
 		}
 
 		line, err := dec.readUvarint()
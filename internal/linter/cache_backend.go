@@ -0,0 +1,227 @@
+package linter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	remoteCacheTimeout  = 10 * time.Second
+	maxRemoteCacheEntry = 64 << 20
+)
+
+// ErrCacheMiss is returned by a CacheBackend that has no entry for a key.
+var ErrCacheMiss = errors.New("cache miss")
+
+// CacheBackend stores cache entries by key: a hex digest followed by ".bin"
+// for the issues of a package, or ".json" for the facts it exported. Entries
+// are validated when read, so a backend may lose or return stale ones.
+type CacheBackend interface {
+	Get(key string) ([]byte, error)
+	Put(key string, data []byte) error
+}
+
+type localCacheBackend struct {
+	dir string
+
+	// Set once dir is known to exist with the cache marker.
+	ready atomic.Bool
+}
+
+// NewLocalCacheBackend keeps entries in dir, sharded by the first byte of
+// their key, with the facts apart.
+func NewLocalCacheBackend(dir string) CacheBackend {
+	return &localCacheBackend{dir: dir}
+}
+
+func (b *localCacheBackend) path(key string) string {
+	if strings.HasSuffix(key, ".json") {
+		return filepath.Join(b.dir, "facts", key[:2], key)
+	}
+
+	return filepath.Join(b.dir, key[:2], key)
+}
+
+func (b *localCacheBackend) Get(key string) ([]byte, error) {
+	path := b.path(key)
+
+	data, modTime, err := readCacheEntry(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrCacheMiss
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Eviction goes by modification time, so a used entry is made recent
+	// again. Like the go build cache, it is only done once in a while to
+	// spare a write on every hit.
+	if now := time.Now(); now.Sub(modTime) > cacheTouchInterval {
+		_ = os.Chtimes(path, now, now)
+	}
+
+	return data, nil
+}
+
+func (b *localCacheBackend) Put(key string, data []byte) error {
+	if !b.ready.Load() {
+		if err := ensureCacheDir(b.dir); err != nil {
+			return err
+		}
+
+		b.ready.Store(true)
+	}
+
+	path := b.path(key)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil {
+		_ = os.Remove(tmp.Name())
+		return writeErr
+	}
+	if closeErr != nil {
+		_ = os.Remove(tmp.Name())
+		return closeErr
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return nil
+}
+
+func readCacheEntry(path string) ([]byte, time.Time, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	data := make([]byte, info.Size())
+	if _, err := io.ReadFull(file, data); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return data, info.ModTime(), nil
+}
+
+type httpCacheBackend struct {
+	url    string
+	token  string
+	client *http.Client
+
+	// Set after a request fails to reach the server, so a run does not wait
+	// on it for every package.
+	down atomic.Bool
+}
+
+// NewHTTPCacheBackend reads entries with GET and writes them with PUT at
+// url/<key>, sending token as a bearer token when set.
+func NewHTTPCacheBackend(url, token string) CacheBackend {
+	return &httpCacheBackend{
+		url:    strings.TrimSuffix(url, "/"),
+		token:  token,
+		client: &http.Client{Timeout: remoteCacheTimeout},
+	}
+}
+
+func (b *httpCacheBackend) Get(key string) ([]byte, error) {
+	resp, err := b.do(http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrCacheMiss
+	default:
+		return nil, fmt.Errorf("remote cache: GET %s: %s", key, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteCacheEntry+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxRemoteCacheEntry {
+		return nil, fmt.Errorf("remote cache: GET %s: entry larger than %d bytes", key, maxRemoteCacheEntry)
+	}
+
+	return data, nil
+}
+
+func (b *httpCacheBackend) Put(key string, data []byte) error {
+	resp, err := b.do(http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("remote cache: PUT %s: %s", key, resp.Status)
+	}
+
+	return nil
+}
+
+func (b *httpCacheBackend) do(method, key string, data []byte) (*http.Response, error) {
+	if b.down.Load() {
+		return nil, errors.New("remote cache unreachable")
+	}
+
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, b.url+"/"+key, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if data != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	if b.token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		b.down.Store(true)
+		return nil, err
+	}
+
+	return resp, nil
+}
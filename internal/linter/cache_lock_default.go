@@ -0,0 +1,49 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package linter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	cacheLockWait  = 30 * time.Second
+	staleCacheLock = 5 * time.Minute
+)
+
+// lockCache takes an exclusive lock on the cache in dir, waiting for other
+// runs to release it. Without flock, the lock is a file only one run can
+// create; one older than staleCacheLock was left by a run that crashed.
+func lockCache(dir string) (func(), error) {
+	path := filepath.Join(dir, cacheLockFile)
+	deadline := time.Now().Add(cacheLockWait)
+
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_ = file.Close()
+
+			return func() {
+				_ = os.Remove(path)
+			}, nil
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleCacheLock {
+			_ = os.Remove(path)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, errors.New("timed out waiting for the cache lock")
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// lockCache takes an exclusive lock on the cache in dir, waiting for other
+// runs to release it. The lock goes away with the process if it crashes.
+func lockCache(dir string) (func(), error) {
+	file, err := os.OpenFile(filepath.Join(dir, cacheLockFile), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return func() {
+		_ = file.Close()
+	}, nil
+}
@@ -0,0 +1,435 @@
+package linter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Files kept in the cache directory next to the entries.
+const (
+	cacheStateFile  = "stats.json"
+	cacheLockFile   = ".lock"
+	cacheMarkerFile = "CACHEDIR.TAG"
+)
+
+// cacheSubdir is created in a cache directory set by the user.
+const cacheSubdir = "serenity-lint-cache"
+
+// cacheMarker marks a directory created by the cache, the only kind cleaned
+// or evicted. It follows the Cache Directory Tagging Specification, so
+// backup tools skip the cache too.
+const cacheMarkerSignature = "Signature: 8a477f597d28d172789f06886806bc55"
+
+const (
+	defaultCacheMaxSize = 512 << 20
+
+	// A hit makes an entry recent again at most once per interval, as the go
+	// command does for its build cache.
+	cacheTouchInterval = time.Hour
+
+	// Temporary files older than this were left by a run that crashed.
+	staleCacheTemp = time.Hour
+)
+
+type cacheCounters struct {
+	hits    atomic.Int64
+	misses  atomic.Int64
+	writes  atomic.Int64
+	written atomic.Int64
+
+	// Nanoseconds spent reading and writing entries.
+	loading atomic.Int64
+	saving  atomic.Int64
+}
+
+func (r *cacheCounters) loadedSince(start time.Time) {
+	if r != nil {
+		r.loading.Add(int64(time.Since(start)))
+	}
+}
+
+func (r *cacheCounters) savedSince(start time.Time) {
+	if r != nil {
+		r.saving.Add(int64(time.Since(start)))
+	}
+}
+
+// CacheRun is what a run did with the cache.
+type CacheRun struct {
+	Hits     int64     `json:"hits"`
+	Misses   int64     `json:"misses"`
+	Writes   int64     `json:"writes"`
+	Evicted  int       `json:"evicted,omitempty"`
+	Finished time.Time `json:"finished"`
+}
+
+// HitRate is the share of lookups that found a reusable entry, from 0 to 1.
+func (r *CacheRun) HitRate() float64 {
+	if total := r.Hits + r.Misses; total > 0 {
+		return float64(r.Hits) / float64(total)
+	}
+
+	return 0
+}
+
+// cacheState is stored in the cache directory and only changed under its
+// lock.
+type cacheState struct {
+	// Size is an upper bound of the bytes the entries take: what runs wrote
+	// since the cache was last measured counts even when it replaced an entry.
+	Size    int64     `json:"size"`
+	LastRun *CacheRun `json:"lastRun,omitempty"`
+}
+
+type CacheStats struct {
+	Dir     string
+	Entries int
+	Bytes   int64
+	LastRun *CacheRun // nil until a run used the cache
+}
+
+type CacheCleanup struct {
+	Entries int
+	Bytes   int64
+}
+
+// cacheEntry is a result file and the facts saved with it, which share a key.
+type cacheEntry struct {
+	paths []string
+	size  int64
+	used  time.Time // The latest modification time of its files
+}
+
+func ReadCacheStats(dir string) (*CacheStats, error) {
+	entries, _, err := scanCache(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &CacheStats{Dir: dir, Entries: len(entries)}
+
+	for _, entry := range entries {
+		stats.Bytes += entry.size
+	}
+
+	if state, ok := readCacheState(dir); ok {
+		stats.LastRun = state.LastRun
+	}
+
+	return stats, nil
+}
+
+// CleanCache removes the entries of the cache in dir no run used for
+// olderThan, or all of them when it is zero.
+func CleanCache(dir string, olderThan time.Duration) (*CacheCleanup, error) {
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return &CacheCleanup{}, nil
+	}
+
+	if err := checkCacheMarker(dir); err != nil {
+		return nil, err
+	}
+
+	unlock, err := lockCache(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	entries, stale, err := scanCache(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	removeCacheFiles(stale)
+
+	cleanup := &CacheCleanup{}
+	cutoff := time.Now().Add(-olderThan)
+	state, _ := readCacheState(dir)
+	state.Size = 0
+
+	for _, entry := range entries {
+		if olderThan > 0 && entry.used.After(cutoff) {
+			state.Size += entry.size
+			continue
+		}
+
+		removeCacheFiles(entry.paths)
+		cleanup.Entries++
+		cleanup.Bytes += entry.size
+	}
+
+	if err := writeCacheState(dir, state); err != nil {
+		return nil, err
+	}
+
+	return cleanup, nil
+}
+
+// finish records the run in the cache directory and, when the cache outgrew
+// its max size, evicts the least recently used entries. Concurrent runs take
+// turns through the cache lock.
+func (c *cacheStore) finish() error {
+	if !c.enabledForRun() {
+		return nil
+	}
+
+	run := &CacheRun{
+		Hits:   c.run.hits.Load(),
+		Misses: c.run.misses.Load(),
+		Writes: c.run.writes.Load(),
+	}
+
+	if run.Hits+run.Misses+run.Writes == 0 {
+		return nil
+	}
+
+	if err := ensureCacheDir(c.dir); err != nil {
+		return err
+	}
+
+	if err := checkCacheMarker(c.dir); err != nil {
+		return err
+	}
+
+	unlock, err := lockCache(c.dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state, known := readCacheState(c.dir)
+	state.Size += c.run.written.Load()
+
+	maxSize := c.maxSize
+	if maxSize <= 0 {
+		maxSize = math.MaxInt64
+	}
+
+	// The size is only measured when the estimate is over the limit, or
+	// unknown since no run recorded it yet.
+	if !known || state.Size > maxSize {
+		state.Size, run.Evicted, err = evictCache(c.dir, maxSize)
+		if err != nil {
+			return err
+		}
+	}
+
+	run.Finished = time.Now()
+	state.LastRun = run
+
+	return writeCacheState(c.dir, state)
+}
+
+// evictCache removes the least recently used entries of dir when they take
+// more than maxSize, down to 90% of it so the next runs do not evict again
+// right away. It returns the size left and the number of entries removed.
+func evictCache(dir string, maxSize int64) (int64, int, error) {
+	entries, stale, err := scanCache(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	removeCacheFiles(stale)
+
+	var size int64
+	for _, entry := range entries {
+		size += entry.size
+	}
+
+	if size <= maxSize {
+		return size, 0, nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].used.Before(entries[j].used)
+	})
+
+	target := maxSize / 10 * 9
+	evicted := 0
+
+	for _, entry := range entries {
+		if size <= target {
+			break
+		}
+
+		removeCacheFiles(entry.paths)
+		size -= entry.size
+		evicted++
+	}
+
+	return size, evicted, nil
+}
+
+// scanCache returns the entries of dir and the temporary files crashed runs
+// left behind. Only the layout of the local backend is looked at, so files
+// that are not the cache's are never taken for entries.
+func scanCache(dir string) ([]*cacheEntry, []string, error) {
+	byKey := make(map[string]*cacheEntry, 256)
+
+	var stale []string
+
+	for _, root := range []string{dir, filepath.Join(dir, "facts")} {
+		shards, err := os.ReadDir(root)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		ext := ".bin"
+		if root != dir {
+			ext = ".json"
+		}
+
+		for _, shard := range shards {
+			if !shard.IsDir() || !isCacheShard(shard.Name()) {
+				continue
+			}
+
+			files, err := os.ReadDir(filepath.Join(root, shard.Name()))
+			if err != nil {
+				// Removed by another run meanwhile.
+				continue
+			}
+
+			for _, file := range files {
+				name := file.Name()
+				path := filepath.Join(root, shard.Name(), name)
+
+				if file.IsDir() {
+					continue
+				}
+
+				info, err := file.Info()
+				if err != nil {
+					continue
+				}
+
+				if strings.HasSuffix(name, ".tmp") {
+					if time.Since(info.ModTime()) > staleCacheTemp {
+						stale = append(stale, path)
+					}
+
+					continue
+				}
+
+				key, ok := strings.CutSuffix(name, ext)
+				if !ok || !isCacheKey(key) || key[:2] != shard.Name() {
+					continue
+				}
+
+				entry := byKey[key]
+				if entry == nil {
+					entry = &cacheEntry{}
+					byKey[key] = entry
+				}
+
+				entry.paths = append(entry.paths, path)
+				entry.size += info.Size()
+
+				if info.ModTime().After(entry.used) {
+					entry.used = info.ModTime()
+				}
+			}
+		}
+	}
+
+	entries := make([]*cacheEntry, 0, len(byKey))
+	for _, entry := range byKey {
+		entries = append(entries, entry)
+	}
+
+	return entries, stale, nil
+}
+
+// isCacheShard reports whether name is a directory the local backend shards
+// entries into: the first byte of their key in hex.
+func isCacheShard(name string) bool {
+	return len(name) == 2 && isLowerHex(name)
+}
+
+// isCacheKey reports whether name is a hex SHA-256 digest, the key of an
+// entry.
+func isCacheKey(name string) bool {
+	return len(name) == 2*cacheHashSize && isLowerHex(name)
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ensureCacheDir creates dir with the cache marker when it does not exist.
+func ensureCacheDir(dir string) error {
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return err
+	}
+
+	err := os.Mkdir(dir, 0o755)
+	if errors.Is(err, fs.ErrExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, cacheMarkerFile), []byte(cacheMarkerSignature+"\n# This file is a cache directory tag created by serenity.\n"), DEFAULT_FILE_MODE)
+}
+
+// checkCacheMarker refuses dir unless the cache created it.
+func checkCacheMarker(dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, cacheMarkerFile))
+	if err != nil || !strings.HasPrefix(string(data), cacheMarkerSignature) {
+		return fmt.Errorf("%s has no %s and is not a serenity cache; refusing to remove files from it", dir, cacheMarkerFile)
+	}
+
+	return nil
+}
+
+func removeCacheFiles(paths []string) {
+	for _, path := range paths {
+		_ = os.Remove(path)
+	}
+}
+
+// readCacheState returns the state stored in dir, and false when there is
+// none or it cannot be read.
+func readCacheState(dir string) (cacheState, bool) {
+	var state cacheState
+
+	data, err := os.ReadFile(filepath.Join(dir, cacheStateFile))
+	if err != nil {
+		return state, false
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return cacheState{}, false
+	}
+
+	return state, true
+}
+
+func writeCacheState(dir string, state cacheState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return replaceFile(filepath.Join(dir, cacheStateFile), data, DEFAULT_FILE_MODE)
+}
@@ -0,0 +1,320 @@
+package linter
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/serenitysz/serenity/internal/rules"
+)
+
+func writeCacheFile(t *testing.T, path string, size int, used time.Time) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chtimes(path, used, used); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// cacheKeyOf returns an entry key in the shard named prefix.
+func cacheKeyOf(prefix string) string {
+	return prefix + strings.Repeat("0", 2*cacheHashSize-len(prefix))
+}
+
+// newCacheDir returns a cache directory created the way runs create it.
+func newCacheDir(t *testing.T) string {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), cacheSubdir)
+	if err := ensureCacheDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestCacheFinishEvictsLeastRecentlyUsedEntries(t *testing.T) {
+	dir := newCacheDir(t)
+
+	now := time.Now()
+	oldest := filepath.Join(dir, "aa", cacheKeyOf("aa")+".bin")
+	oldestFacts := filepath.Join(dir, "facts", "aa", cacheKeyOf("aa")+".json")
+	older := filepath.Join(dir, "bb", cacheKeyOf("bb")+".bin")
+	recent := filepath.Join(dir, "cc", cacheKeyOf("cc")+".bin")
+
+	writeCacheFile(t, oldest, 100, now.Add(-3*time.Hour))
+	writeCacheFile(t, oldestFacts, 20, now.Add(-3*time.Hour))
+	writeCacheFile(t, older, 100, now.Add(-2*time.Hour))
+	writeCacheFile(t, recent, 100, now)
+
+	store := &cacheStore{enabled: true, dir: dir, maxSize: 250, run: &cacheCounters{}}
+	store.run.hits.Add(3)
+	store.run.misses.Add(1)
+
+	if err := store.finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{oldest, oldestFacts} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be evicted, got %v", path, err)
+		}
+	}
+
+	stats, err := ReadCacheStats(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Entries != 2 || stats.Bytes != 200 {
+		t.Fatalf("expected 2 entries of 200 bytes left, got %d of %d", stats.Entries, stats.Bytes)
+	}
+
+	if run := stats.LastRun; run == nil || run.Evicted != 1 || run.HitRate() != 0.75 {
+		t.Fatalf("unexpected last run: %+v", run)
+	}
+
+	cleanup, err := CleanCache(dir, 90*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cleanup.Entries != 1 || cleanup.Bytes != 100 {
+		t.Fatalf("expected the entry unused for 2h to be cleaned, got %+v", cleanup)
+	}
+
+	if _, err := os.Stat(recent); err != nil {
+		t.Fatalf("expected the recent entry to be kept: %v", err)
+	}
+
+	if cleanup, err = CleanCache(dir, 0); err != nil || cleanup.Entries != 1 {
+		t.Fatalf("expected the last entry to be cleaned, got %+v, %v", cleanup, err)
+	}
+}
+
+func TestCleanCacheOnlyRemovesEntries(t *testing.T) {
+	dir := newCacheDir(t)
+	old := time.Now().Add(-time.Hour)
+
+	entry := filepath.Join(dir, "ab", cacheKeyOf("ab")+".bin")
+	others := []string{
+		filepath.Join(dir, "serenity.json"),
+		filepath.Join(dir, "web", "package.json"),
+		filepath.Join(dir, "ab", "notes.bin"),
+		filepath.Join(dir, "facts", "web", cacheKeyOf("cd")+".json"),
+	}
+
+	writeCacheFile(t, entry, 10, old)
+	for _, path := range others {
+		writeCacheFile(t, path, 10, old)
+	}
+
+	cleanup, err := CleanCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cleanup.Entries != 1 {
+		t.Fatalf("expected only the cache entry to be cleaned, got %+v", cleanup)
+	}
+
+	for _, path := range others {
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("expected %s to be kept: %v", path, err)
+		}
+	}
+}
+
+func TestCleanCacheRefusesDirectoriesWithoutMarker(t *testing.T) {
+	dir := t.TempDir()
+	entry := filepath.Join(dir, "ab", cacheKeyOf("ab")+".bin")
+
+	writeCacheFile(t, entry, 10, time.Now())
+
+	if _, err := CleanCache(dir, 0); err == nil {
+		t.Fatal("expected a directory without the cache marker to be refused")
+	}
+
+	store := &cacheStore{enabled: true, dir: dir, maxSize: 1, run: &cacheCounters{}}
+	store.run.writes.Add(1)
+
+	if err := store.finish(); err == nil {
+		t.Fatal("expected eviction to refuse a directory without the cache marker")
+	}
+
+	if _, err := os.Stat(entry); err != nil {
+		t.Fatalf("expected the file to be kept: %v", err)
+	}
+}
+
+func TestRemoteCacheSharesEntriesBetweenCheckouts(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		entries = make(map[string][]byte)
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		key := strings.TrimPrefix(r.URL.Path, "/")
+
+		switch r.Method {
+		case http.MethodGet:
+			data, ok := entries[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			_, _ = w.Write(data)
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			entries[key] = data
+		}
+	}))
+
+	checkout := func() (*cacheStore, []packageInput) {
+		root := t.TempDir()
+		path := filepath.Join(root, "pkg", "sample.go")
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte("package pkg\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		inputs, err := probePackageInputs([]string{path})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dir := t.TempDir()
+		store := &cacheStore{
+			enabled:    true,
+			dir:        dir,
+			configHash: "config",
+			local:      NewLocalCacheBackend(dir),
+			remote:     NewHTTPCacheBackend(server.URL, "secret"),
+			run:        &cacheCounters{},
+		}
+
+		return store.withModule(&rules.Module{Path: "example.com/m", Dir: root}), inputs
+	}
+
+	first, inputs := checkout()
+
+	if _, ok := first.load(inputs, 0); ok {
+		t.Fatal("expected a miss on an empty cache")
+	}
+
+	issue := rules.Issue{Path: inputs[0].Path, Line: 1, Column: 1, ID: rules.AlwaysPreferConstID}
+	if err := first.save(inputs, []rules.Issue{issue}); err != nil {
+		t.Fatal(err)
+	}
+
+	second, inputs := checkout()
+
+	issues, ok := second.load(inputs, 0)
+	if !ok || len(issues) != 1 {
+		t.Fatalf("expected the entry of the first checkout, got %v, %v", issues, ok)
+	}
+
+	if issues[0].Path != inputs[0].Path {
+		t.Fatalf("expected the issue in %s, got %s", inputs[0].Path, issues[0].Path)
+	}
+
+	// The entry is now local, so the next run needs neither the server nor
+	// the sources.
+	server.Close()
+
+	inputs, err := probePackageInputs([]string{inputs[0].Path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := second.load(inputs, 0); !ok || inputs[0].Src != nil {
+		t.Fatalf("expected a local hit on the fast path, got %v", ok)
+	}
+}
+
+func TestCacheLoadExplainsMisses(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "pkg", "sample.go")
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte("package pkg\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	store := (&cacheStore{
+		enabled:    true,
+		dir:        dir,
+		configHash: "config",
+		local:      NewLocalCacheBackend(dir),
+		run:        &cacheCounters{},
+	}).withModule(&rules.Module{Path: "example.com/m", Dir: root})
+
+	inputs, err := probePackageInputs([]string{path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := store.loadValidated(inputs); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("expected no entry, got %v", err)
+	}
+
+	if err := loadPackageSources(inputs); err != nil {
+		t.Fatal(err)
+	}
+
+	issue := rules.Issue{Path: path, Line: 1, Column: 1, ID: rules.AlwaysPreferConstID, Flags: rules.IssueFixableFlag}
+	if err := store.save(inputs, []rules.Issue{issue}); err != nil {
+		t.Fatal(err)
+	}
+
+	mutating := *store
+	mutating.mutating = true
+
+	if _, _, err := mutating.loadValidated(inputs); !errors.Is(err, errFixesPending) {
+		t.Fatalf("expected the fixable entry to be skipped when fixing, got %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("package pkg // changed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if inputs, err = probePackageInputs([]string{path}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := store.loadValidated(inputs); err == nil || !strings.Contains(err.Error(), "stale cache content of pkg/sample.go") {
+		t.Fatalf("expected the changed file to be named, got %v", err)
+	}
+}
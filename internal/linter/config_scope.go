@@ -0,0 +1,295 @@
+package linter
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/serenitysz/serenity/internal/render"
+	"github.com/serenitysz/serenity/internal/rules"
+	"github.com/serenitysz/serenity/internal/utils"
+)
+
+// configScope is the configuration a package is linted with: the run's own,
+// or the one merged from the config files along its directory.
+type configScope struct {
+	config        *rules.LinterOptions
+	rules         *ActiveRules
+	generated     *ActiveRules // Replaces rules for generated files in lint-light mode
+	generatedMode string
+	suppressions  *rules.SuppressionParser
+	parseMode     parser.Mode
+	autofix       bool
+	mutating      bool
+	overrides     *fileOverrides
+	baseDir       string   // Directory files globs are relative to
+	exclude       []string // go.exclude globs of the files the walk skips
+
+	// hash keys the cache on the effective config. It is empty for the run's
+	// own config, which the cache is already keyed on.
+	hash string
+}
+
+func (l *Linter) rootScope() *configScope {
+	baseDir := l.configRoot()
+
+	scope := &configScope{
+		config:        l.Config,
+		rules:         l.ActiveRules,
+		generated:     l.GeneratedRules,
+		generatedMode: l.Generated,
+		suppressions:  l.Suppressions,
+		parseMode:     l.ParseMode,
+		autofix:       l.Write || l.Config.ShouldAutofix(),
+		overrides:     l.newFileOverrides(l.Config, baseDir, l.patterns),
+		baseDir:       baseDir,
+		exclude:       excludeGlobs(l.Config),
+	}
+	scope.mutating = (l.ActiveRules.HasAutofixRules && scope.autofix) ||
+		(l.ActiveRules.HasUnsafeAutofixRules && l.Write && l.Unsafe)
+	scope.applyOverrideFlags(l)
+
+	return scope
+}
+
+// scopeFor returns the configuration of dir. Without a config tree every
+// directory uses the run's own. A nested config that does not load fails the
+// run, as linting its directory with the root config would be wrong.
+func (l *Linter) scopeFor(dir string) (*configScope, error) {
+	root := l.root
+	if root == nil {
+		root = l.rootScope()
+	}
+
+	if l.Configs == nil {
+		return root, nil
+	}
+
+	cfg, err := l.Configs.ForDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg == l.Config {
+		return root, nil
+	}
+
+	l.scopesMu.Lock()
+	defer l.scopesMu.Unlock()
+
+	if scope, ok := l.scopes[cfg]; ok {
+		return scope, nil
+	}
+
+	files, _ := l.Configs.Files(dir)
+	render.Debugf("%s  using nested config %s", dir, strings.Join(files, ", "))
+
+	// Plugins and analyzers are started once per run, from the root config.
+	changed := !reflect.DeepEqual(cfg.Linter.Analyzers, l.Config.Linter.Analyzers) || !reflect.DeepEqual(cfg.Plugins, l.Config.Plugins)
+	if changed && len(files) > 0 {
+		render.Warnf("%s  linter.analyzers and plugins are only read from the root config; ignoring those set by %s", dir, files[0])
+	}
+
+	scope := l.newScope(cfg, l.Configs.BaseDir(dir))
+	if l.scopes == nil {
+		l.scopes = make(map[*rules.LinterOptions]*configScope, 4)
+	}
+	l.scopes[cfg] = scope
+
+	return scope, nil
+}
+
+// newScope builds the rules of a nested config. Plugins and go/analysis
+// analyzers are started once per run, so every scope shares the root's.
+func (l *Linter) newScope(cfg *rules.LinterOptions, baseDir string) *configScope {
+	compiled := compilePatterns(cfg.Linter.Patterns)
+	active := l.buildRules(cfg, false, compiled)
+
+	autofix := l.Write || cfg.ShouldAutofix()
+	scope := &configScope{
+		config:        cfg,
+		rules:         active,
+		generatedMode: generatedModeOf(cfg),
+		suppressions:  rules.NewSuppressionParser(cfg.Linter.Suppressions, time.Now()),
+		parseMode:     parser.ParseComments | parser.SkipObjectResolution,
+		autofix:       autofix,
+		mutating:      (active.HasAutofixRules && autofix) || (active.HasUnsafeAutofixRules && l.Write && l.Unsafe),
+		hash:          cacheConfigHash(cfg),
+		overrides:     l.newFileOverrides(cfg, baseDir, compiled),
+		baseDir:       baseDir,
+		exclude:       excludeGlobs(cfg),
+	}
+
+	if active.NeedsConstAnalysis {
+		scope.parseMode = parser.ParseComments
+	}
+
+	scope.applyOverrideFlags(l)
+
+	if scope.generatedMode == rules.GeneratedLintLight {
+		scope.generated = l.buildRules(cfg, true, nil)
+	}
+
+	return scope
+}
+
+func excludeGlobs(cfg *rules.LinterOptions) []string {
+	if cfg.File == nil || cfg.File.Exclude == nil {
+		return nil
+	}
+
+	return *cfg.File.Exclude
+}
+
+// excluded reports whether a go.exclude glob matches path.
+func (s *configScope) excluded(path string) bool {
+	if len(s.exclude) == 0 {
+		return false
+	}
+
+	rel := relativeTo(s.baseDir, path)
+
+	for _, glob := range s.exclude {
+		if utils.MatchGlob(glob, rel) {
+			render.Debugf("%s  skipped, excluded by %q", path, glob)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *configScope) skipGenerated(path string, file *ast.File) bool {
+	if s.generatedMode != rules.GeneratedSkip || !ast.IsGenerated(file) {
+		return false
+	}
+
+	render.Debugf("%s  skipped, generated file", path)
+
+	return true
+}
+
+// applyOverrideFlags accounts for rules only enabled by some overrides.
+func (s *configScope) applyOverrideFlags(l *Linter) {
+	o := s.overrides
+	if o == nil {
+		return
+	}
+
+	if o.needsConst {
+		s.parseMode = parser.ParseComments
+	}
+
+	if (o.autofix && s.autofix) || (o.unsafeFixes && l.Write && l.Unsafe) {
+		s.mutating = true
+	}
+}
+
+func (s *configScope) needsConstAnalysis() bool {
+	return (s.rules != nil && s.rules.NeedsConstAnalysis) || (s.overrides != nil && s.overrides.needsConst)
+}
+
+// factVersions lists the fact rules any file of s may run, its overrides
+// included.
+func (s *configScope) factVersions() []string {
+	versions := slices.Clone(s.rules.factVersions)
+	if s.overrides != nil {
+		versions = append(versions, s.overrides.factVersions...)
+	}
+
+	return versions
+}
+
+// rulesFor returns the rules for the file at path: those of the overrides
+// matching it, or the scope's own.
+func (s *configScope) rulesFor(path string, file *ast.File) *ActiveRules {
+	generated := s.generated != nil && ast.IsGenerated(file)
+
+	if active := s.overrides.rulesFor(path, generated); active != nil {
+		return active
+	}
+
+	if generated {
+		return s.generated
+	}
+
+	return s.rules
+}
+
+// ConfigFor returns the config the file at path is linted with, the
+// overrides matching it merged in, and the indexes of those overrides.
+func (l *Linter) ConfigFor(path string) (*rules.LinterOptions, []int, error) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	scope, err := l.scopeFor(filepath.Dir(path))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	matched := scope.overrides.match(path)
+	if len(matched) == 0 {
+		return scope.config, nil, nil
+	}
+
+	return scope.overrides.merged(matched), matched, nil
+}
+
+// ActiveRulesFor returns the rules that run on the file at path, given its
+// config, module and whether it is generated. Generated files that are
+// skipped get none.
+func (l *Linter) ActiveRulesFor(path string) (*ActiveRules, error) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		file = &ast.File{}
+	}
+
+	dir := filepath.Dir(path)
+	scope, err := l.scopeFor(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if scope.skipGenerated(path, file) {
+		return &ActiveRules{}, nil
+	}
+
+	return scope.rulesFor(path, file).forModule(l.Modules.lookup(dir)), nil
+}
+
+// ActiveRulesForDir returns the rules that run on the files of dir that no
+// override matches, given its config and module.
+func (l *Linter) ActiveRulesForDir(dir string) (*ActiveRules, error) {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	scope, err := l.scopeFor(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return scope.rules.forModule(l.Modules.lookup(dir)), nil
+}
+
+// cacheFor returns the cache store for packages linted with s.
+func (s *configScope) cacheFor(cache *cacheStore) *cacheStore {
+	if s.hash == "" || !cache.enabledForRun() {
+		return cache
+	}
+
+	derived := cache.derive("config", s.hash)
+	derived.mutating = s.mutating
+
+	return derived
+}
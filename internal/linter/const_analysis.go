@@ -7,7 +7,7 @@ import (
 
 //nolint:staticcheck // Deliberately uses parser-resolved ast.Object links to avoid go/types in the lint hot path.
 func (l *Linter) buildConstCandidates(params AnalysisParams) map[*ast.Ident]struct{} {
-	if params.rules == nil || !params.rules.NeedsConstAnalysis || len(params.pkgFiles) == 0 {
+	if params.config == nil || !params.config.needsConstAnalysis() || len(params.pkgFiles) == 0 {
 		return nil
 	}
 
@@ -0,0 +1,267 @@
+package linter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/serenitysz/serenity/internal/rules"
+)
+
+// factStore holds the facts each package of a run exported, and lets a
+// package wait until the packages it imports have been analyzed.
+type factStore struct {
+	mu       sync.Mutex
+	packages map[string]*packageFacts
+}
+
+type packageFacts struct {
+	done  chan struct{}
+	facts rules.PackageFacts
+}
+
+func newFactStore() *factStore {
+	return &factStore{packages: make(map[string]*packageFacts, 64)}
+}
+
+func (s *factStore) entry(pkg string) *packageFacts {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.packages[pkg]
+	if !ok {
+		entry = &packageFacts{done: make(chan struct{})}
+		s.packages[pkg] = entry
+	}
+
+	return entry
+}
+
+// wait blocks until every package in deps has published its facts.
+func (s *factStore) wait(deps []string) {
+	for _, dep := range deps {
+		<-s.entry(dep).done
+	}
+}
+
+// publish makes facts visible to dependents. It must be called exactly once
+// for every scheduled package, even when its analysis fails, so dependents
+// never wait forever.
+func (s *factStore) publish(pkg string, facts rules.PackageFacts) {
+	if pkg == "" {
+		return
+	}
+
+	entry := s.entry(pkg)
+
+	select {
+	case <-entry.done:
+		// Already analyzed under an earlier root of the same run.
+	default:
+		entry.facts = facts
+		close(entry.done)
+	}
+}
+
+func (s *factStore) lookup(pkg string) rules.PackageFacts {
+	s.mu.Lock()
+	entry, ok := s.packages[pkg]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	select {
+	case <-entry.done:
+		return entry.facts
+	default:
+		return nil
+	}
+}
+
+// digest summarizes the facts of deps for the cache key of a dependent.
+func (s *factStore) digest(deps []string) string {
+	if len(deps) == 0 {
+		return ""
+	}
+
+	sorted := append([]string(nil), deps...)
+	sort.Strings(sorted)
+
+	key := sha256.New()
+	for _, dep := range sorted {
+		data, _ := json.Marshal(s.lookup(dep))
+
+		_, _ = key.Write([]byte(dep))
+		_, _ = key.Write([]byte{0})
+		_, _ = key.Write(data)
+		_, _ = key.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(key.Sum(nil))
+}
+
+// prepareFacts creates the fact store the first time a run has fact rules
+// or analyzers active, and keys the cache on the fact encodings they use.
+// Those rules may only be enabled by an override or a nested config, so every
+// scope of the run is looked at, not just the root one.
+func (l *Linter) prepareFacts(scopes []*configScope) {
+	if l.Facts != nil {
+		return
+	}
+
+	versions := l.root.factVersions()
+	for _, scope := range scopes {
+		versions = append(versions, scope.factVersions()...)
+	}
+
+	if l.Analyzers != nil {
+		versions = append(versions, l.Analyzers.Stamp())
+	}
+
+	if len(versions) == 0 {
+		return
+	}
+
+	sort.Strings(versions)
+
+	l.Facts = newFactStore()
+	l.Cache.extendConfigHash("facts:" + strings.Join(slices.Compact(versions), ","))
+}
+
+// scopesUnder returns the scopes of roots and of every directory below them
+// with a config file of its own.
+func (l *Linter) scopesUnder(roots []string) ([]*configScope, error) {
+	dirs := roots
+	if l.Configs != nil {
+		dirs = append(slices.Clone(roots), l.Configs.ConfigDirs(roots)...)
+	}
+
+	scopes := make([]*configScope, 0, len(dirs))
+	for _, dir := range dirs {
+		scope, err := l.scopeFor(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		scopes = append(scopes, scope)
+	}
+
+	return scopes, nil
+}
+
+func (l *Linter) newFacts(pkg string) *rules.Facts {
+	if l.Facts == nil {
+		return nil
+	}
+
+	return rules.NewFacts(pkg, l.Facts.lookup)
+}
+
+// walkPackagesInImportOrder collects every package under root and enqueues
+// them so that each package comes after the module packages it imports.
+func (l *Linter) walkPackagesInImportOrder(roots []string, done <-chan struct{}, enqueue func(PackageJob) bool) error {
+	var jobs []PackageJob
+
+	err := l.walkPackages(roots, done, func(job PackageJob) bool {
+		jobs = append(jobs, job)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, job := range orderByImports(jobs) {
+		if !enqueue(job) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func orderByImports(jobs []PackageJob) []PackageJob {
+	index := make(map[string]int, len(jobs))
+	for i := range jobs {
+		jobs[i].pkgPath = packageImportPath(jobs[i].module, jobs[i].dirPath)
+		if strings.HasSuffix(jobs[i].pkgName, "_test") {
+			jobs[i].pkgPath += "_test"
+		}
+		index[jobs[i].pkgPath] = i
+	}
+
+	imports := make([][]string, len(jobs))
+	for i := range jobs {
+		for _, path := range fileImports(jobs[i].files) {
+			if dep, ok := index[path]; ok && dep != i {
+				imports[i] = append(imports[i], path)
+			}
+		}
+	}
+
+	ordered := make([]PackageJob, 0, len(jobs))
+	state := make([]uint8, len(jobs)) // 0 unvisited, 1 visiting, 2 scheduled
+
+	var visit func(int)
+	visit = func(i int) {
+		if state[i] != 0 {
+			return
+		}
+
+		state[i] = 1
+		for _, path := range imports[i] {
+			visit(index[path])
+		}
+		state[i] = 2
+
+		// A dependency still being visited is part of an import cycle; the
+		// package cannot wait for it, so it is analyzed without its facts.
+		for _, path := range imports[i] {
+			if state[index[path]] == 2 {
+				jobs[i].deps = append(jobs[i].deps, path)
+			}
+		}
+
+		ordered = append(ordered, jobs[i])
+	}
+
+	for i := range jobs {
+		visit(i)
+	}
+
+	return ordered
+}
+
+func fileImports(paths []string) []string {
+	seen := make(map[string]struct{}, 8)
+	imports := make([]string, 0, 8)
+
+	for _, path := range paths {
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+
+		for _, spec := range file.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+
+			if _, ok := seen[importPath]; !ok {
+				seen[importPath] = struct{}{}
+				imports = append(imports, importPath)
+			}
+		}
+	}
+
+	return imports
+}
@@ -0,0 +1,284 @@
+package linter
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/serenitysz/serenity/internal/config"
+	"github.com/serenitysz/serenity/internal/exception"
+)
+
+const (
+	journalManifest = "manifest.jsonl"
+
+	// journalAside holds the previous journal while a new one is swapped in.
+	journalAside = ".prev"
+)
+
+var ErrNoJournal = errors.New("no backup journal found")
+
+// Journal keeps a copy of every file before --write replaces it. Entries are
+// appended and synced one by one, so a run that crashes halfway still leaves
+// a journal that can restore every file it touched. The journal of the
+// previous run is only replaced once this one backed up a file, so a run
+// writing nothing keeps it.
+type Journal struct {
+	dir      string
+	mu       sync.Mutex
+	manifest *os.File
+	recorded map[string]struct{}
+}
+
+type journalEntry struct {
+	Path   string      `json:"path"`
+	Backup string      `json:"backup"`
+	Mode   os.FileMode `json:"mode"`
+}
+
+// JournalRoot returns the directory a run from the working directory keeps
+// its journal for: that of the outermost config file the working directory
+// inherits, else the root of its Go module, else the working directory. Runs
+// and undos from anywhere in the project then share one journal.
+func JournalRoot(tree *config.Tree) (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	if tree != nil {
+		if files, err := tree.Files(wd); err == nil && len(files) > 0 {
+			return filepath.Abs(filepath.Dir(files[len(files)-1]))
+		}
+	}
+
+	if mod := newModuleResolver().lookup(wd); mod != nil {
+		return mod.Dir, nil
+	}
+
+	return wd, nil
+}
+
+func ResolveJournalDir(root string) (string, error) {
+	if dir := os.Getenv("SERENITY_BACKUP_DIR"); dir != "" {
+		return dir, nil
+	}
+
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(root))
+
+	return filepath.Join(base, "serenity", "journal", hex.EncodeToString(sum[:8])), nil
+}
+
+func OpenJournal(dir string) (*Journal, error) {
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return nil, exception.InternalError("could not create backup journal %q: %w", dir, err)
+	}
+
+	return &Journal{
+		dir:      dir,
+		recorded: make(map[string]struct{}, 16),
+	}, nil
+}
+
+func (j *Journal) Record(path string) error {
+	if j == nil {
+		return nil
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, ok := j.recorded[abs]; ok {
+		return nil
+	}
+
+	info, err := os.Stat(abs)
+	if err != nil {
+		return err
+	}
+
+	src, err := os.ReadFile(abs)
+	if err != nil {
+		return err
+	}
+
+	entry := journalEntry{
+		Path:   abs,
+		Backup: strconv.Itoa(len(j.recorded)) + ".orig",
+		Mode:   info.Mode().Perm(),
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if j.manifest == nil {
+		err = j.start(entry, src, line)
+	} else {
+		err = j.append(j.dir, entry, src, line)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	j.recorded[abs] = struct{}{}
+
+	return nil
+}
+
+// start writes the first entry to a new journal next to the previous one and
+// only then swaps it in. The previous journal is moved aside rather than
+// removed before the swap, so a crash in between still leaves an undo.
+func (j *Journal) start(entry journalEntry, src, line []byte) error {
+	staging, err := os.MkdirTemp(filepath.Dir(j.dir), filepath.Base(j.dir)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if err := j.append(staging, entry, src, line); err != nil {
+		j.closeManifest()
+		_ = os.RemoveAll(staging)
+		return err
+	}
+
+	// Renaming a directory with open files fails on Windows.
+	j.closeManifest()
+
+	aside := j.dir + journalAside
+
+	if err := os.RemoveAll(aside); err != nil {
+		_ = os.RemoveAll(staging)
+		return err
+	}
+
+	if err := os.Rename(j.dir, aside); err != nil && !os.IsNotExist(err) {
+		_ = os.RemoveAll(staging)
+		return err
+	}
+
+	if err := os.Rename(staging, j.dir); err != nil {
+		_ = os.Rename(aside, j.dir)
+		_ = os.RemoveAll(staging)
+		return err
+	}
+
+	_ = os.RemoveAll(aside)
+
+	manifest, err := os.OpenFile(filepath.Join(j.dir, journalManifest), os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+
+	j.manifest = manifest
+
+	return nil
+}
+
+// append backs up src in dir and adds entry to its manifest. The backup keeps
+// the mode of the file, so the journal does not expose private files.
+func (j *Journal) append(dir string, entry journalEntry, src, line []byte) error {
+	if err := replaceFile(filepath.Join(dir, entry.Backup), src, entry.Mode); err != nil {
+		return err
+	}
+
+	if j.manifest == nil {
+		manifest, err := os.OpenFile(filepath.Join(dir, journalManifest), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return err
+		}
+
+		j.manifest = manifest
+	}
+
+	if _, err := j.manifest.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	return j.manifest.Sync()
+}
+
+func (j *Journal) closeManifest() {
+	if j.manifest != nil {
+		_ = j.manifest.Close()
+		j.manifest = nil
+	}
+}
+
+func (j *Journal) Close() error {
+	if j == nil || j.manifest == nil {
+		return nil
+	}
+
+	return j.manifest.Close()
+}
+
+// RestoreJournal puts back every file recorded in dir and removes the journal
+// once all of them are restored. When a run crashed while swapping in its
+// journal, that is the previous one it had moved aside.
+func RestoreJournal(dir string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(dir, journalManifest))
+	if os.IsNotExist(err) {
+		dir += journalAside
+		data, err = os.ReadFile(filepath.Join(dir, journalManifest))
+	}
+
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, ErrNoJournal
+		}
+
+		return 0, exception.InternalError("could not read backup journal %q: %w", dir, err)
+	}
+
+	restored := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry journalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			// A crash while appending leaves at most one truncated trailing line.
+			break
+		}
+
+		src, err := os.ReadFile(filepath.Join(dir, entry.Backup))
+		if err != nil {
+			return restored, exception.InternalError("could not read backup of %q: %w", entry.Path, err)
+		}
+
+		if err := replaceFile(entry.Path, src, entry.Mode); err != nil {
+			return restored, exception.InternalError("could not restore %q: %w", entry.Path, err)
+		}
+
+		restored++
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return restored, exception.InternalError("could not remove backup journal %q: %w", dir, err)
+	}
+
+	return restored, nil
+}
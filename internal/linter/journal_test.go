@@ -0,0 +1,203 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/serenitysz/serenity/internal/config"
+)
+
+func TestJournalKeepsThePreviousOneUntilAFileIsBackedUp(t *testing.T) {
+	dir := t.TempDir()
+	journalDir := filepath.Join(t.TempDir(), "journal")
+	first := filepath.Join(dir, "first.go")
+	second := filepath.Join(dir, "second.go")
+
+	if err := os.WriteFile(first, []byte("package first\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(second, []byte("package second\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	journal, err := OpenJournal(journalDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := journal.Record(first); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(filepath.Join(journalDir, "0.orig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := info.Mode().Perm(); got != 0o600 {
+		t.Fatalf("expected the backup to keep mode 0600, got %v", got)
+	}
+
+	// A run that writes nothing keeps the only undo.
+	journal, err = OpenJournal(journalDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(journalDir, "0.orig")); err != nil {
+		t.Fatalf("expected the previous journal to be kept: %v", err)
+	}
+
+	journal, err = OpenJournal(journalDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := journal.Record(second); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := journal.Record(first); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(second, []byte("package changed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := RestoreJournal(journalDir)
+	if err != nil || restored != 2 {
+		t.Fatalf("expected the new journal to restore 2 files, got %d, %v", restored, err)
+	}
+
+	if data, _ := os.ReadFile(second); string(data) != "package second\n" {
+		t.Fatalf("expected the second file to be restored, got %q", data)
+	}
+}
+
+func TestJournalRootIsSharedByTheProjectDirectories(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"serenity.json":           `{"linter": {"use": true}}`,
+		"go.mod":                  "module example.com/app\n\ngo 1.22\n",
+		"tools/go.mod":            "module example.com/tools\n\ngo 1.22\n",
+		"tools/serenity.json":     `{"linter": {"use": true}}`,
+		"internal/store/store.go": "package store\n",
+		"tools/cmd/gen/main.go":   "package main\n",
+	})
+
+	want, err := filepath.Abs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, sub := range []string{".", "internal/store", "tools/cmd/gen"} {
+		t.Chdir(filepath.Join(dir, sub))
+
+		tree, err := config.LoadTree("")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, err := JournalRoot(tree); err != nil || got != want {
+			t.Fatalf("JournalRoot from %s = %q, %v; want %q", sub, got, err, want)
+		}
+	}
+
+	outside := t.TempDir()
+	writeFiles(t, outside, map[string]string{
+		"go.mod":           "module example.com/lib\n\ngo 1.22\n",
+		"pkg/util/util.go": "package util\n",
+	})
+	t.Chdir(filepath.Join(outside, "pkg", "util"))
+
+	want, err = filepath.Abs(outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := JournalRoot(nil); err != nil || got != want {
+		t.Fatalf("JournalRoot without a config = %q, %v; want the module root %q", got, err, want)
+	}
+}
+
+func TestRestoreJournalFindsTheJournalSetAsideByACrash(t *testing.T) {
+	dir := t.TempDir()
+	journalDir := filepath.Join(t.TempDir(), "journal")
+	file := filepath.Join(dir, "file.go")
+
+	if err := os.WriteFile(file, []byte("package file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	journal, err := OpenJournal(journalDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := journal.Record(file); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The state a run that crashed between moving the journal aside and
+	// swapping its own in leaves behind.
+	if err := os.Rename(journalDir, journalDir+journalAside); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(file, []byte("package changed\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if restored, err := RestoreJournal(journalDir); err != nil || restored != 1 {
+		t.Fatalf("expected the journal set aside to restore 1 file, got %d, %v", restored, err)
+	}
+
+	if data, _ := os.ReadFile(file); string(data) != "package file\n" {
+		t.Fatalf("expected the file to be restored, got %q", data)
+	}
+}
+
+func TestReplaceFileFollowsSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.go")
+	link := filepath.Join(dir, "link.go")
+
+	if err := os.WriteFile(target, []byte("package old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	if err := writeFileAtomic(link, []byte("package new\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected the link to be kept, got %v, %v", info, err)
+	}
+
+	if data, _ := os.ReadFile(target); string(data) != "package new\n" {
+		t.Fatalf("expected the target to be rewritten, got %q", data)
+	}
+}
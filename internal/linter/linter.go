@@ -3,8 +3,17 @@ package linter
 import (
 	"go/parser"
 	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/serenitysz/serenity/internal/analyzers"
+	"github.com/serenitysz/serenity/internal/config"
+	"github.com/serenitysz/serenity/internal/plugin"
+	"github.com/serenitysz/serenity/internal/render"
 	"github.com/serenitysz/serenity/internal/rules"
+	"github.com/serenitysz/serenity/internal/rules/patterns"
 )
 
 type Linter struct {
@@ -17,11 +26,53 @@ type Linter struct {
 	ParseMode   parser.Mode
 	ActiveRules *ActiveRules
 	Cache       *cacheStore
+	Journal     *Journal
+	Stats       *RunStats // nil unless the run is timed
+	Generated   string
+	Targets     []buildTarget
+
+	// GeneratedRules replaces ActiveRules for generated files in lint-light mode.
+	GeneratedRules *ActiveRules
+
+	Suppressions *rules.SuppressionParser
+	Plugins      *plugin.Set
+	Analyzers    *analyzers.Driver
+
+	// Facts is set on the first run that has fact rules active.
+	Facts *factStore
+
+	Modules *moduleResolver
+
+	// workspace is the go.work of the directory being processed, if any.
+	workspace *workspace
+
+	// Configs resolves nested config files. Without it every package uses
+	// Config.
+	Configs *config.Tree
+
+	// dirLocks serializes the jobs of a directory with several build targets
+	// when fixes are applied.
+	dirLocks sync.Map
+
+	// patterns are the pattern rules compiled from Config, shared by the
+	// rules of its overrides.
+	patterns []*patterns.PatternRule
+
+	root     *configScope
+	scopesMu sync.Mutex
+	scopes   map[*rules.LinterOptions]*configScope
 }
 
 func New(write, unsafe bool, config *rules.LinterOptions, maxIssues int, maxFileSize int64) *Linter {
 	workers := runtime.GOMAXPROCS(0)
-	activeRules := BuildActiveRules(config)
+	compiled := compilePatterns(config.Linter.Patterns)
+	activeRules := buildActiveRules(config, false, compiled)
+	analyzerDriver := analyzers.NewDriver(config.Linter.Analyzers)
+
+	if analyzerDriver.CanFix() {
+		activeRules.HasAutofixRules = true
+	}
+
 	autofix := write || config.ShouldAutofix()
 	mutating := (activeRules.HasAutofixRules && autofix) || (activeRules.HasUnsafeAutofixRules && write && unsafe)
 	effectiveMaxIssues := maxIssues
@@ -45,6 +96,16 @@ func New(write, unsafe bool, config *rules.LinterOptions, maxIssues int, maxFile
 		workers = 1
 	}
 
+	cache := newCacheStore(config, mutating, unsafe)
+	cache.extendConfigHash(analyzerDriver.Stamp())
+
+	var generatedRules *ActiveRules
+
+	generated := generatedModeOf(config)
+	if generated == rules.GeneratedLintLight {
+		generatedRules = BuildGeneratedActiveRules(config)
+	}
+
 	return &Linter{
 		Write:       write,
 		Unsafe:      unsafe,
@@ -54,6 +115,77 @@ func New(write, unsafe bool, config *rules.LinterOptions, maxIssues int, maxFile
 		Workers:     workers,
 		ParseMode:   parseMode,
 		ActiveRules: activeRules,
-		Cache:       newCacheStore(config, mutating, unsafe),
+		Generated:   generated,
+		Targets:     newBuildTargets(config.BuildTargets()),
+		Cache:       cache,
+
+		GeneratedRules: generatedRules,
+		Suppressions:   rules.NewSuppressionParser(config.Linter.Suppressions, time.Now()),
+		Analyzers:      analyzerDriver,
+		Modules:        newModuleResolver(),
+
+		patterns: compiled,
 	}
 }
+
+// generatedModeOf returns the go.generated mode of cfg. Config files are
+// validated when read, but a config built in code may hold anything, and an
+// unknown mode falls back to the default rather than to linting.
+func generatedModeOf(cfg *rules.LinterOptions) string {
+	mode := cfg.GeneratedMode()
+	if !slices.Contains(rules.GeneratedModes, mode) {
+		render.Warnf("go.generated: invalid mode %q; expected one of %s, using %q", mode, strings.Join(rules.GeneratedModes, ", "), rules.GeneratedSkip)
+		return rules.GeneratedSkip
+	}
+
+	return mode
+}
+
+// buildRules builds the rules of cfg for a scope or an override set: the
+// built-in ones, the patterns compiled for the scope and the plugins', marked
+// as fixing when the analyzers can.
+func (l *Linter) buildRules(cfg *rules.LinterOptions, generated bool, compiled []*patterns.PatternRule) *ActiveRules {
+	active := buildActiveRules(cfg, generated, compiled)
+
+	if l.Plugins != nil {
+		for _, rule := range l.Plugins.Rules {
+			active.Add(rule)
+		}
+	}
+
+	if l.Analyzers.CanFix() {
+		active.HasAutofixRules = true
+	}
+
+	return active
+}
+
+// LoadPlugins starts the plugins configured under `plugins` and adds their
+// rules to the active set. Callers must Close the linter afterwards.
+func (l *Linter) LoadPlugins() error {
+	set, err := plugin.Load(l.Config.Plugins)
+	if err != nil {
+		return err
+	}
+
+	l.Plugins = set
+	for _, rule := range set.Rules {
+		l.ActiveRules.Add(rule)
+
+		if l.GeneratedRules != nil {
+			l.GeneratedRules.Add(rule)
+		}
+	}
+	l.Cache.extendConfigHash(set.Stamp())
+
+	return nil
+}
+
+// Close records the run in the cache, trimming it to its max size, and
+// stops the plugins.
+func (l *Linter) Close() error {
+	// The cache is best effort: failing to maintain it does not fail the run.
+	_ = l.Cache.finish()
+
+	return l.Plugins.Close()
+}
@@ -1,214 +1,91 @@
 package linter
 
 import (
+	"fmt"
+
+	"github.com/serenitysz/serenity/internal/render"
 	"github.com/serenitysz/serenity/internal/rules"
-	"github.com/serenitysz/serenity/internal/rules/bestpractices"
-	"github.com/serenitysz/serenity/internal/rules/complexity"
-	"github.com/serenitysz/serenity/internal/rules/correctness"
-	"github.com/serenitysz/serenity/internal/rules/errs"
-	"github.com/serenitysz/serenity/internal/rules/imports"
-	"github.com/serenitysz/serenity/internal/rules/naming"
-	"github.com/serenitysz/serenity/internal/rules/style"
+	_ "github.com/serenitysz/serenity/internal/rules/all"
+	"github.com/serenitysz/serenity/internal/rules/patterns"
 )
 
-func BuildActiveRules(cfg *rules.LinterOptions) *ActiveRules {
-	active := &ActiveRules{}
-
-	r := cfg.Linter.Rules
-
-	if imp := r.Imports; imp != nil && imp.Use {
-		if imp.NoDotImports != nil {
-			active.ImportSpec = append(active.ImportSpec, &imports.NoDotImportsRule{
-				Severity: rules.ParseSeverity(imp.NoDotImports.Severity),
-			})
-		}
-
-		if imp.DisallowedPackages != nil {
-			packages := make(map[string]struct{}, len(imp.DisallowedPackages.Packages))
-			for _, pkg := range imp.DisallowedPackages.Packages {
-				packages[pkg] = struct{}{}
-			}
-
-			active.ImportSpec = append(active.ImportSpec, &imports.DisallowedPackagesRule{
-				Severity: rules.ParseSeverity(imp.DisallowedPackages.Severity),
-				Packages: packages,
-			})
-		}
-
-		if imp.RedundantImportAlias != nil {
-			active.ImportSpec = append(active.ImportSpec, &imports.RedundantImportAliasRule{
-				Severity: rules.ParseSeverity(imp.RedundantImportAlias.Severity),
-			})
-			active.HasAutofixRules = true
-		}
+// compilePatterns compiles the pattern rules of a config. The linter and
+// each nested scope compile theirs once and share them with the rules of
+// their overrides and the ones built for generated files.
+func compilePatterns(opts []rules.PatternRuleOptions) []*patterns.PatternRule {
+	if len(opts) == 0 {
+		return nil
 	}
 
-	if errCfg := r.Errors; errCfg != nil && errCfg.Use {
-		if errCfg.ErrorStringFormat != nil {
-			active.ReturnStmt = append(active.ReturnStmt, &errs.ErrorStringFormatRule{
-				Severity: rules.ParseSeverity(errCfg.ErrorStringFormat.Severity),
-			})
-			active.HasAutofixRules = true
+	compiled := make([]*patterns.PatternRule, 0, len(opts))
+	for i, opt := range opts {
+		// Unnamed patterns get one from their place in the list, so each
+		// can still be suppressed on its own.
+		if opt.Name == "" {
+			opt.Name = fmt.Sprintf("pattern-%d", i+1)
 		}
 
-		if errCfg.ErrorNotWrapped != nil {
-			active.ReturnStmt = append(active.ReturnStmt, &errs.ErrorNotWrappedRule{
-				Severity: rules.ParseSeverity(errCfg.ErrorNotWrapped.Severity),
-			})
-			active.HasAutofixRules = true
+		rule, err := patterns.Compile(opt)
+		if err != nil {
+			render.Warnf("skipping pattern rule %q: %v", opt.Name, err)
+			continue
 		}
-	}
-
-	if bp := r.BestPractices; bp != nil && bp.Use {
-		if bp.MaxParams != nil {
-			limit := uint16(5)
-			if bp.MaxParams.Max != nil {
-				limit = *bp.MaxParams.Max
-			}
 
-			active.FuncDecl = append(active.FuncDecl, &bestpractices.MaxParamsRule{
-				Limit:    limit,
-				Severity: rules.ParseSeverity(bp.MaxParams.Severity),
-			})
-		}
-
-		if bp.UseContextInFirstParam != nil {
-			active.FuncDecl = append(active.FuncDecl, &bestpractices.ContextFirstRule{
-				Severity: rules.ParseSeverity(bp.UseContextInFirstParam.Severity),
-			})
-			active.HasUnsafeAutofixRules = true
-		}
+		compiled = append(compiled, rule)
+	}
 
-		if bp.AvoidEmptyStructs != nil {
-			active.TypeSpec = append(active.TypeSpec, &bestpractices.AvoidEmptyStructsRule{
-				Severity: rules.ParseSeverity(bp.AvoidEmptyStructs.Severity),
-			})
-		}
+	return compiled
+}
 
-		if bp.NoMagicNumbers != nil {
-			active.BasicLit = append(active.BasicLit, &bestpractices.NoMagicNumbersRule{
-				Severity: rules.ParseSeverity(bp.NoMagicNumbers.Severity),
-			})
-		}
+func BuildActiveRules(cfg *rules.LinterOptions) *ActiveRules {
+	return buildActiveRules(cfg, false, compilePatterns(cfg.Linter.Patterns))
+}
 
-		if bp.AlwaysPreferConst != nil {
-			active.ValueSpec = append(active.ValueSpec, &bestpractices.AlwaysPreferConstRule{
-				Severity: rules.ParseSeverity(bp.AlwaysPreferConst.Severity),
-			})
-			active.NeedsConstAnalysis = true
-		}
+func BuildGeneratedActiveRules(cfg *rules.LinterOptions) *ActiveRules {
+	return buildActiveRules(cfg, true, nil)
+}
 
-		if bp.NoDeferInLoop != nil {
-			active.DeferStmt = append(active.DeferStmt, &bestpractices.NoDeferInLoopRule{
-				Severity: rules.ParseSeverity(bp.NoDeferInLoop.Severity),
-			})
-		}
+// buildActiveRules builds the built-in rules of cfg and adds compiled, the
+// pattern rules already compiled from it. Patterns never run on generated
+// files.
+func buildActiveRules(cfg *rules.LinterOptions, generated bool, compiled []*patterns.PatternRule) *ActiveRules {
+	active := &ActiveRules{}
 
-		if bp.UseSliceCapacity != nil {
-			active.CallExpr = append(active.CallExpr, &bestpractices.UseSliceCapacityRule{
-				Severity: rules.ParseSeverity(bp.UseSliceCapacity.Severity),
-			})
-			active.HasAutofixRules = true
+	// In lint-light mode generated files only get the rules flagged for
+	// them: broken imports, error handling and correctness bugs rather than
+	// style, size or naming the generator controls.
+	for _, desc := range rules.Descriptors() {
+		if generated && !desc.RunsOnGenerated {
+			continue
 		}
 
-		if bp.NoBareReturns != nil {
-			active.ReturnStmt = append(active.ReturnStmt, &bestpractices.NoBareReturnsRule{
-				Severity: rules.ParseSeverity(bp.NoBareReturns.Severity),
-			})
+		rule := desc.Build(&cfg.Linter.Rules)
+		if rule == nil {
+			continue
 		}
 
-		if bp.GetMustReturnValue != nil {
-			active.FuncDecl = append(active.FuncDecl, &bestpractices.GetMustReturnValueRule{
-				Severity: rules.ParseSeverity(bp.GetMustReturnValue.Severity),
-			})
-		}
-	}
+		active.AddSince(rule, desc.MinGoVersion)
 
-	if stl := r.Style; stl != nil && stl.Use {
-		if stl.PreferIncDec != nil {
-			active.AssignStmt = append(active.AssignStmt, &style.PreferIncDecRule{
-				Severity: rules.ParseSeverity(stl.PreferIncDec.Severity),
-			})
+		if desc.Fixable {
 			active.HasAutofixRules = true
 		}
-	}
-
-	if cp := r.Complexity; cp != nil && cp.Use {
-		if cp.MaxFuncLines != nil {
-			limit := int16(20)
-			if cp.MaxFuncLines.Max != nil {
-				limit = int16(*cp.MaxFuncLines.Max)
-			}
 
-			active.FuncDecl = append(active.FuncDecl, &complexity.CheckMaxFuncLinesRule{
-				Limit:    limit,
-				Severity: rules.ParseSeverity(cp.MaxFuncLines.Severity),
-			})
+		if desc.UnsafeFix {
+			active.HasUnsafeAutofixRules = true
 		}
 
-		if cp.MaxLineLength != nil {
-			limit := 80
-			if cp.MaxLineLength.Max != nil {
-				limit = int(*cp.MaxLineLength.Max)
-			}
-
-			active.File = append(active.File, &complexity.CheckMaxLineLengthRule{
-				Limit:    limit,
-				Severity: rules.ParseSeverity(cp.MaxLineLength.Severity),
-			})
+		if desc.NeedsConstAnalysis {
+			active.NeedsConstAnalysis = true
 		}
 	}
 
-	if crr := r.Correctness; crr != nil && crr.Use {
-		if crr.EmptyBlock != nil {
-			active.BlockStmt = append(active.BlockStmt, &correctness.EmptyBlockRule{
-				Severity: rules.ParseSeverity(crr.EmptyBlock.Severity),
-			})
-		}
-
-		if crr.AmbiguousReturns != nil {
-			maxAllowed := 1
-			if crr.AmbiguousReturns.MaxUnnamedSameType != nil {
-				maxAllowed = *crr.AmbiguousReturns.MaxUnnamedSameType
-			}
-
-			active.FuncDecl = append(active.FuncDecl, &correctness.AmbiguousReturnRule{
-				Severity:   rules.ParseSeverity(crr.AmbiguousReturns.Severity),
-				MaxAllowed: maxAllowed,
-			})
-		}
-
-		if crr.BoolLiteralExpressions != nil {
-			active.BinaryExpr = append(active.BinaryExpr, &correctness.BooleanLiteralExpressionsRule{
-				Severity: rules.ParseSeverity(crr.BoolLiteralExpressions.Severity),
-			})
-			active.HasAutofixRules = true
-		}
-	}
+	if !generated {
+		for _, rule := range compiled {
+			active.Add(rule)
 
-	if n := r.Naming; n != nil && n.Use {
-		if n.ReceiverNames != nil {
-			maxSize := 1
-			if n.ReceiverNames.MaxSize != nil {
-				maxSize = *n.ReceiverNames.MaxSize
+			if rule.HasReplacement() {
+				active.HasAutofixRules = true
 			}
-
-			active.FuncDecl = append(active.FuncDecl, &naming.ReceiverNamesRule{
-				Severity: rules.ParseSeverity(n.ReceiverNames.Severity),
-				MaxSize:  maxSize,
-			})
-		}
-
-		if n.ImportedIdentifiers != nil {
-			active.ImportSpec = append(active.ImportSpec, naming.NewImportedIdentifiersRule(n.ImportedIdentifiers))
-		}
-
-		if n.ExportedIdentifiers != nil {
-			rule := naming.NewExportedIdentifiersRule(n.ExportedIdentifiers)
-			active.FuncDecl = append(active.FuncDecl, rule)
-			active.TypeSpec = append(active.TypeSpec, rule)
-			active.ValueSpec = append(active.ValueSpec, rule)
-			active.Field = append(active.Field, rule)
 		}
 	}
 
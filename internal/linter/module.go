@@ -0,0 +1,218 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/serenitysz/serenity/internal/rules"
+	"golang.org/x/mod/modfile"
+)
+
+// moduleResolver finds the module of a directory by looking for the closest
+// go.mod above it, remembering the answer for every directory it visits.
+type moduleResolver struct {
+	mu    sync.Mutex
+	byDir map[string]*rules.Module
+}
+
+func newModuleResolver() *moduleResolver {
+	return &moduleResolver{byDir: make(map[string]*rules.Module, 64)}
+}
+
+// lookup returns the module containing dir, or nil when there is none.
+func (r *moduleResolver) lookup(dir string) *rules.Module {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.lookupLocked(abs)
+}
+
+func (r *moduleResolver) lookupLocked(dir string) *rules.Module {
+	if mod, ok := r.byDir[dir]; ok {
+		return mod
+	}
+
+	mod := readModule(dir)
+	if mod == nil {
+		if parent := filepath.Dir(dir); parent != dir {
+			mod = r.lookupLocked(parent)
+		}
+	}
+
+	r.byDir[dir] = mod
+
+	return mod
+}
+
+func readModule(dir string) *rules.Module {
+	path := filepath.Join(dir, "go.mod")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	file, err := modfile.ParseLax(path, data, nil)
+	if err != nil || file.Module == nil {
+		return &rules.Module{Path: modfile.ModulePath(data), Dir: dir}
+	}
+
+	mod := &rules.Module{Path: file.Module.Mod.Path, Dir: dir}
+	if file.Go != nil {
+		mod.GoVersion = file.Go.Version
+	}
+
+	return mod
+}
+
+// workspace is a go.work file and the modules it uses.
+type workspace struct {
+	Dir     string
+	Modules []*rules.Module
+}
+
+// findWorkspace reads the go.work file dir is in. Like the go command it
+// looks in dir and then its parents, unless GOWORK names the file or turns
+// workspaces off.
+func (l *Linter) findWorkspace(dir string) (*workspace, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	path := workFile(abs)
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := modfile.ParseWork(path, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	abs = filepath.Dir(path)
+	ws := &workspace{Dir: abs, Modules: make([]*rules.Module, 0, len(file.Use))}
+	seen := make(map[string]struct{}, len(file.Use))
+
+	for _, use := range file.Use {
+		modDir := filepath.FromSlash(use.Path)
+		if !filepath.IsAbs(modDir) {
+			modDir = filepath.Join(abs, modDir)
+		}
+		modDir = filepath.Clean(modDir)
+
+		if _, ok := seen[modDir]; ok {
+			continue
+		}
+		seen[modDir] = struct{}{}
+
+		if mod := l.Modules.lookup(modDir); mod != nil && mod.Dir == modDir {
+			ws.Modules = append(ws.Modules, mod)
+		}
+	}
+
+	return ws, nil
+}
+
+// workFile returns the go.work file used from dir, or "" when there is none.
+func workFile(dir string) string {
+	switch gowork := os.Getenv("GOWORK"); gowork {
+	case "off":
+		return ""
+	case "", "auto":
+	default:
+		return gowork
+	}
+
+	for {
+		path := filepath.Join(dir, "go.work")
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
+
+// within returns the modules of the workspace inside dir.
+func (w *workspace) within(dir string) []*rules.Module {
+	if dir == w.Dir {
+		return w.Modules
+	}
+
+	var mods []*rules.Module
+
+	for _, mod := range w.Modules {
+		if mod.Dir == dir || strings.HasPrefix(mod.Dir, dir+string(filepath.Separator)) {
+			mods = append(mods, mod)
+		}
+	}
+
+	return mods
+}
+
+// isModuleRoot reports whether dir has a go.mod of its own. In a workspace
+// such a directory is either another workspace module, walked from its own
+// root, or a module the workspace does not use.
+func (w *workspace) isModuleRoot(r *moduleResolver, dir string) bool {
+	if w == nil {
+		return false
+	}
+
+	mod := r.lookup(dir)
+
+	return mod != nil && mod.Dir == dir
+}
+
+// moduleOf returns the workspace module containing path, preferring the
+// innermost one when modules nest.
+func (w *workspace) moduleOf(path string) *rules.Module {
+	var found *rules.Module
+
+	for _, mod := range w.Modules {
+		if path != mod.Dir && !strings.HasPrefix(path, mod.Dir+string(filepath.Separator)) {
+			continue
+		}
+
+		if found == nil || len(mod.Dir) > len(found.Dir) {
+			found = mod
+		}
+	}
+
+	return found
+}
+
+func packageImportPath(mod *rules.Module, dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.ToSlash(dir)
+	}
+
+	if mod == nil || mod.Path == "" {
+		return filepath.ToSlash(abs)
+	}
+
+	rel, err := filepath.Rel(mod.Dir, abs)
+	if err != nil || rel == "." {
+		return mod.Path
+	}
+
+	return mod.Path + "/" + filepath.ToSlash(rel)
+}
@@ -0,0 +1,195 @@
+package linter
+
+import "go/ast"
+
+// nodeKind indexes the dispatch table in ActiveRules. Every concrete go/ast
+// node type has its own kind so any of them can be a rule target.
+type nodeKind uint8
+
+const (
+	kindUnknown nodeKind = iota
+
+	kindComment
+	kindCommentGroup
+	kindField
+	kindFieldList
+
+	kindBadExpr
+	kindIdent
+	kindEllipsis
+	kindBasicLit
+	kindFuncLit
+	kindCompositeLit
+	kindParenExpr
+	kindSelectorExpr
+	kindIndexExpr
+	kindIndexListExpr
+	kindSliceExpr
+	kindTypeAssertExpr
+	kindCallExpr
+	kindStarExpr
+	kindUnaryExpr
+	kindBinaryExpr
+	kindKeyValueExpr
+
+	kindArrayType
+	kindStructType
+	kindFuncType
+	kindInterfaceType
+	kindMapType
+	kindChanType
+
+	kindBadStmt
+	kindDeclStmt
+	kindEmptyStmt
+	kindLabeledStmt
+	kindExprStmt
+	kindSendStmt
+	kindIncDecStmt
+	kindAssignStmt
+	kindGoStmt
+	kindDeferStmt
+	kindReturnStmt
+	kindBranchStmt
+	kindBlockStmt
+	kindIfStmt
+	kindCaseClause
+	kindSwitchStmt
+	kindTypeSwitchStmt
+	kindCommClause
+	kindSelectStmt
+	kindForStmt
+	kindRangeStmt
+
+	kindImportSpec
+	kindValueSpec
+	kindTypeSpec
+
+	kindBadDecl
+	kindGenDecl
+	kindFuncDecl
+
+	kindFile
+	kindPackage
+
+	nodeKindCount
+)
+
+func kindOf(node ast.Node) nodeKind {
+	switch node.(type) {
+	case *ast.Comment:
+		return kindComment
+	case *ast.CommentGroup:
+		return kindCommentGroup
+	case *ast.Field:
+		return kindField
+	case *ast.FieldList:
+		return kindFieldList
+	case *ast.BadExpr:
+		return kindBadExpr
+	case *ast.Ident:
+		return kindIdent
+	case *ast.Ellipsis:
+		return kindEllipsis
+	case *ast.BasicLit:
+		return kindBasicLit
+	case *ast.FuncLit:
+		return kindFuncLit
+	case *ast.CompositeLit:
+		return kindCompositeLit
+	case *ast.ParenExpr:
+		return kindParenExpr
+	case *ast.SelectorExpr:
+		return kindSelectorExpr
+	case *ast.IndexExpr:
+		return kindIndexExpr
+	case *ast.IndexListExpr:
+		return kindIndexListExpr
+	case *ast.SliceExpr:
+		return kindSliceExpr
+	case *ast.TypeAssertExpr:
+		return kindTypeAssertExpr
+	case *ast.CallExpr:
+		return kindCallExpr
+	case *ast.StarExpr:
+		return kindStarExpr
+	case *ast.UnaryExpr:
+		return kindUnaryExpr
+	case *ast.BinaryExpr:
+		return kindBinaryExpr
+	case *ast.KeyValueExpr:
+		return kindKeyValueExpr
+	case *ast.ArrayType:
+		return kindArrayType
+	case *ast.StructType:
+		return kindStructType
+	case *ast.FuncType:
+		return kindFuncType
+	case *ast.InterfaceType:
+		return kindInterfaceType
+	case *ast.MapType:
+		return kindMapType
+	case *ast.ChanType:
+		return kindChanType
+	case *ast.BadStmt:
+		return kindBadStmt
+	case *ast.DeclStmt:
+		return kindDeclStmt
+	case *ast.EmptyStmt:
+		return kindEmptyStmt
+	case *ast.LabeledStmt:
+		return kindLabeledStmt
+	case *ast.ExprStmt:
+		return kindExprStmt
+	case *ast.SendStmt:
+		return kindSendStmt
+	case *ast.IncDecStmt:
+		return kindIncDecStmt
+	case *ast.AssignStmt:
+		return kindAssignStmt
+	case *ast.GoStmt:
+		return kindGoStmt
+	case *ast.DeferStmt:
+		return kindDeferStmt
+	case *ast.ReturnStmt:
+		return kindReturnStmt
+	case *ast.BranchStmt:
+		return kindBranchStmt
+	case *ast.BlockStmt:
+		return kindBlockStmt
+	case *ast.IfStmt:
+		return kindIfStmt
+	case *ast.CaseClause:
+		return kindCaseClause
+	case *ast.SwitchStmt:
+		return kindSwitchStmt
+	case *ast.TypeSwitchStmt:
+		return kindTypeSwitchStmt
+	case *ast.CommClause:
+		return kindCommClause
+	case *ast.SelectStmt:
+		return kindSelectStmt
+	case *ast.ForStmt:
+		return kindForStmt
+	case *ast.RangeStmt:
+		return kindRangeStmt
+	case *ast.ImportSpec:
+		return kindImportSpec
+	case *ast.ValueSpec:
+		return kindValueSpec
+	case *ast.TypeSpec:
+		return kindTypeSpec
+	case *ast.BadDecl:
+		return kindBadDecl
+	case *ast.GenDecl:
+		return kindGenDecl
+	case *ast.FuncDecl:
+		return kindFuncDecl
+	case *ast.File:
+		return kindFile
+	case *ast.Package:
+		return kindPackage
+	default:
+		return kindUnknown
+	}
+}
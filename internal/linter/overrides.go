@@ -0,0 +1,167 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/serenitysz/serenity/internal/render"
+	"github.com/serenitysz/serenity/internal/rules"
+	"github.com/serenitysz/serenity/internal/rules/patterns"
+	"github.com/serenitysz/serenity/internal/utils"
+)
+
+// fileOverrides resolves the overrides of a config to the rules of each file.
+// Files matching the same overrides share one ActiveRules, so a file is still
+// walked once whatever overrides apply to it.
+type fileOverrides struct {
+	config  *rules.LinterOptions
+	baseDir string // Directory the files globs are relative to
+	build   func(cfg *rules.LinterOptions, generated bool) *ActiveRules
+
+	// Union of the flags of every override, for the parse mode and cache.
+	needsConst   bool
+	autofix      bool
+	unsafeFixes  bool
+	factVersions []string
+
+	mu   sync.Mutex
+	sets map[string]*ActiveRules
+}
+
+// newFileOverrides resolves the overrides of cfg, whose patterns are already
+// compiled: overrides only change rules, so every set shares them.
+func (l *Linter) newFileOverrides(cfg *rules.LinterOptions, baseDir string, compiled []*patterns.PatternRule) *fileOverrides {
+	if len(cfg.Overrides) == 0 {
+		return nil
+	}
+
+	o := &fileOverrides{
+		config:  cfg,
+		baseDir: baseDir,
+		sets:    make(map[string]*ActiveRules, len(cfg.Overrides)),
+		build: func(cfg *rules.LinterOptions, generated bool) *ActiveRules {
+			return l.buildRules(cfg, generated, compiled)
+		},
+	}
+
+	for i, override := range cfg.Overrides {
+		for _, glob := range override.Files {
+			if !utils.ValidGlob(glob) {
+				render.Warnf("overrides[%d]: invalid files glob %q", i, glob)
+			}
+		}
+
+		active := o.rules([]int{i}, false)
+		o.needsConst = o.needsConst || active.NeedsConstAnalysis
+		o.autofix = o.autofix || active.HasAutofixRules
+		o.unsafeFixes = o.unsafeFixes || active.HasUnsafeAutofixRules
+		o.factVersions = append(o.factVersions, active.factVersions...)
+	}
+
+	return o
+}
+
+// rulesFor returns the rules of the file at path, or nil when no override
+// applies to it.
+func (o *fileOverrides) rulesFor(path string, generated bool) *ActiveRules {
+	matched := o.match(path)
+	if len(matched) == 0 {
+		return nil
+	}
+
+	return o.rules(matched, generated)
+}
+
+// match returns the indexes of the overrides applying to the file at path.
+func (o *fileOverrides) match(path string) []int {
+	if o == nil {
+		return nil
+	}
+
+	name := relativeTo(o.baseDir, path)
+
+	var matched []int
+	for i, override := range o.config.Overrides {
+		for _, glob := range override.Files {
+			if utils.MatchGlob(glob, name) {
+				matched = append(matched, i)
+				break
+			}
+		}
+	}
+
+	return matched
+}
+
+func (o *fileOverrides) rules(matched []int, generated bool) *ActiveRules {
+	var key strings.Builder
+	if generated {
+		key.WriteByte('g')
+	}
+	for _, i := range matched {
+		key.WriteByte(',')
+		key.WriteString(strconv.Itoa(i))
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if active, ok := o.sets[key.String()]; ok {
+		return active
+	}
+
+	active := o.build(o.merged(matched), generated)
+	o.sets[key.String()] = active
+
+	return active
+}
+
+// merged returns the config with the matched overrides applied in order.
+func (o *fileOverrides) merged(matched []int) *rules.LinterOptions {
+	cfg := *o.config
+	cfg.Overrides = nil
+	for _, i := range matched {
+		cfg.Linter.Rules = rules.MergeRules(cfg.Linter.Rules, o.config.Overrides[i])
+	}
+
+	return &cfg
+}
+
+// relativeTo returns path relative to baseDir with forward slashes, the form
+// files globs are matched against. Paths given relative to the working
+// directory, as in `serenity check ./pkg`, are made absolute first.
+func relativeTo(baseDir, path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	if baseDir != "" {
+		if rel, err := filepath.Rel(baseDir, path); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+
+	return filepath.ToSlash(path)
+}
+
+// configRoot returns the directory of the root config, which the globs of its
+// overrides are relative to.
+func (l *Linter) configRoot() string {
+	if l.Configs != nil {
+		return l.Configs.RootDir()
+	}
+
+	return workingDir()
+}
+
+func workingDir() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	return wd
+}
@@ -6,26 +6,124 @@ import (
 	"go/token"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/serenitysz/serenity/internal/exception"
 	"github.com/serenitysz/serenity/internal/render"
 	"github.com/serenitysz/serenity/internal/rules"
 )
 
+// ModuleIssues are the issues found in one module.
+type ModuleIssues struct {
+	Module *rules.Module // nil outside any module
+	Issues []rules.Issue
+}
+
 func (l *Linter) ProcessPath(root string) ([]rules.Issue, error) {
+	results, err := l.ProcessModules(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) == 1 {
+		return results[0].Issues, nil
+	}
+
+	var issues []rules.Issue
+	for _, result := range results {
+		issues = append(issues, result.Issues...)
+	}
+
+	return issues, nil
+}
+
+// ProcessModules lints root and returns its issues by module. A directory in
+// a go.work workspace has every module it uses under root linted in the same
+// run, with one result per module in the order of its use directives;
+// anything else gives a single result.
+func (l *Linter) ProcessModules(root string) ([]ModuleIssues, error) {
 	info, err := os.Stat(root)
 	if err != nil {
 		return nil, exception.InternalError("could not access %q: %w", root, err)
 	}
 
+	l.root = l.rootScope()
+
 	if !info.IsDir() {
-		return l.processFile(root, info.Size())
+		scope, err := l.scopeFor(filepath.Dir(root))
+		if err != nil {
+			return nil, err
+		}
+
+		l.prepareFacts([]*configScope{scope})
+
+		issues, err := l.processFile(root, info.Size())
+		if err != nil {
+			return nil, err
+		}
+
+		return []ModuleIssues{{Module: l.Modules.lookup(filepath.Dir(root)), Issues: issues}}, nil
+	}
+
+	ws, err := l.findWorkspace(root)
+	if err != nil {
+		return nil, exception.InternalError("could not read the go.work file of %q: %w", root, err)
+	}
+
+	// Linting part of a workspace covers the modules under root alone.
+	if ws != nil {
+		if abs, err := filepath.Abs(root); err == nil {
+			ws.Modules = ws.within(abs)
+		}
+	}
+
+	if ws == nil || len(ws.Modules) == 0 {
+		issues, err := l.processDirs([]string{root})
+		if err != nil {
+			return nil, err
+		}
+
+		return []ModuleIssues{{Module: l.Modules.lookup(root), Issues: issues}}, nil
 	}
 
+	l.workspace = ws
+	defer func() { l.workspace = nil }()
+
+	roots := make([]string, len(ws.Modules))
+	results := make([]ModuleIssues, len(ws.Modules))
+	index := make(map[*rules.Module]int, len(ws.Modules))
+
+	for i, mod := range ws.Modules {
+		roots[i] = mod.Dir
+		results[i].Module = mod
+		index[mod] = i
+	}
+
+	issues, err := l.processDirs(roots)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, issue := range issues {
+		if i, ok := index[ws.moduleOf(normalizeIssuePath(issue.Path))]; ok {
+			results[i].Issues = append(results[i].Issues, issue)
+		}
+	}
+
+	return results, nil
+}
+
+func (l *Linter) processDirs(roots []string) ([]rules.Issue, error) {
+	scopes, err := l.scopesUnder(roots)
+	if err != nil {
+		return nil, err
+	}
+
+	l.prepareFacts(scopes)
+
 	workers := l.Workers
 	if workers < 1 {
 		workers = 1
@@ -87,9 +185,24 @@ func (l *Linter) ProcessPath(root string) ([]rules.Issue, error) {
 		}()
 	}
 
+	walk := l.walkPackages
+	if l.Facts != nil {
+		walk = l.walkPackagesInImportOrder
+	}
+
+	walkErr := make(chan error, 1)
+
 	go func() {
 		defer close(pkgJobs)
-		_ = l.walkPackages(root, done, func(job PackageJob) bool {
+
+		// The walk waits on the workers to take its jobs, which is not part
+		// of its own time.
+		start := time.Now()
+		var waited time.Duration
+
+		err := walk(roots, done, func(job PackageJob) bool {
+			defer func(sent time.Time) { waited += time.Since(sent) }(time.Now())
+
 			select {
 			case pkgJobs <- job:
 				return true
@@ -97,6 +210,9 @@ func (l *Linter) ProcessPath(root string) ([]rules.Issue, error) {
 				return false
 			}
 		})
+
+		l.Stats.add(PhaseWalk, time.Since(start)-waited)
+		walkErr <- err
 	}()
 
 	go func() {
@@ -139,6 +255,10 @@ func (l *Linter) ProcessPath(root string) ([]rules.Issue, error) {
 		final = append(final, batch.issues...)
 	}
 
+	if err := <-walkErr; err != nil {
+		return nil, err
+	}
+
 	if l.MaxIssues == 0 {
 		if len(batches) == 1 {
 			if batches[0].cached != nil {
@@ -170,21 +290,42 @@ func (l *Linter) ProcessPath(root string) ([]rules.Issue, error) {
 		final = final[:offset]
 	}
 
+	if len(l.Targets) > 1 {
+		final = dedupeIssues(final)
+	}
+
 	return final, nil
 }
 
 func (l *Linter) processFile(path string, size int64) ([]rules.Issue, error) {
-	if l.MaxFileSize > 0 && size > l.MaxFileSize {
+	if l.tooLarge(path, size) {
 		return nil, nil
 	}
 
+	render.Debugf("%s  linting file", path)
+
+	config, err := l.scopeFor(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	scope := packageScope{
+		facts:  l.newFacts(""),
+		module: l.Modules.lookup(filepath.Dir(path)),
+		config: config,
+	}
+	cfg := scope.config
+	l.Stats.countPackage(1)
+
 	if l.Cache.enabledForRun() {
 		inputs, err := probePackageInputs([]string{path})
 		if err != nil {
 			return nil, exception.InternalError("could not inspect %q: %w", path, err)
 		}
 
-		if cached, ok := l.Cache.load(inputs, l.MaxIssues); ok {
+		cache := cfg.cacheFor(l.Cache).withModule(scope.module).withExpiry(inputs, today())
+
+		if cached, ok := cache.load(inputs, l.MaxIssues); ok {
 			if l.MaxIssues > 0 {
 				return truncateIssues(cached, l.MaxIssues), nil
 			}
@@ -192,24 +333,35 @@ func (l *Linter) processFile(path string, size int64) ([]rules.Issue, error) {
 			return cached, nil
 		}
 
+		start := time.Now()
 		if err := loadPackageSources(inputs); err != nil {
 			return nil, exception.InternalError("could not read %q: %w", path, err)
 		}
+		l.Stats.since(PhaseRead, start)
 
+		start = time.Now()
 		fset := token.NewFileSet()
-		file, err := parser.ParseFile(fset, path, inputs[0].Src, l.ParseMode)
+		file, err := parser.ParseFile(fset, path, inputs[0].Src, cfg.parseMode)
 		if err != nil {
 			return nil, exception.InternalError("could not parse Go file %q: %w", path, err)
 		}
+		l.Stats.since(PhaseParse, start)
 
-		issues, err := l.analyzePackage([]*ast.File{file}, []string{path}, fset, map[string][]rules.Suppression{
-			path: rules.ProcessSuppressions(file.Comments, fset, file.Decls, file.Package),
-		}, 0, nil)
+		if cfg.skipGenerated(path, file) {
+			return nil, nil
+		}
+
+		suppressions := map[string][]rules.Suppression{
+			path: rules.ProcessSuppressions(file.Comments, fset, file.Decls, file.Package, cfg.suppressions),
+		}
+
+		scope.sources = sourcesOf(inputs)
+		issues, err := l.analyzePackage([]*ast.File{file}, []string{path}, fset, suppressions, 0, nil, scope)
 		if err != nil {
 			return nil, err
 		}
 
-		issues, err = l.refreshCacheForInputs(inputs, issues)
+		issues, err = l.refreshCacheForInputs(cache.untilNext(inputs, nextExpiry(suppressions)), inputs, issues, scope)
 		if err != nil {
 			return nil, err
 		}
@@ -221,40 +373,76 @@ func (l *Linter) processFile(path string, size int64) ([]rules.Issue, error) {
 		return issues, nil
 	}
 
+	start := time.Now()
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, exception.InternalError("could not read %q: %w", path, err)
+	}
+	l.Stats.since(PhaseRead, start)
+
+	start = time.Now()
 	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, path, nil, l.ParseMode)
+	file, err := parser.ParseFile(fset, path, src, cfg.parseMode)
 	if err != nil {
 		return nil, exception.InternalError("could not parse Go file %q: %w", path, err)
 	}
+	l.Stats.since(PhaseParse, start)
 
+	if cfg.skipGenerated(path, file) {
+		return nil, nil
+	}
+
+	scope.sources = map[string][]byte{path: src}
 	return l.analyzePackage([]*ast.File{file}, []string{path}, fset, map[string][]rules.Suppression{
-		path: rules.ProcessSuppressions(file.Comments, fset, file.Decls, file.Package),
+		path: rules.ProcessSuppressions(file.Comments, fset, file.Decls, file.Package, cfg.suppressions),
 	}, l.MaxIssues, func(current int) bool {
 		return l.MaxIssues > 0 && current >= l.MaxIssues
-	})
+	}, scope)
 }
 
 func (l *Linter) processPackageJob(job PackageJob, totalIssues *int64) (issueBatch, error) {
+	scope := packageScope{facts: l.newFacts(job.pkgPath), module: job.module, config: job.config}
+	if scope.config == nil {
+		config, err := l.scopeFor(job.dirPath)
+		if err != nil {
+			return issueBatch{}, err
+		}
+
+		scope.config = config
+	}
+	if scope.facts != nil {
+		l.Facts.wait(job.deps)
+		defer func() { l.Facts.publish(job.pkgPath, scope.facts.Exported()) }()
+	}
+
+	if len(l.Targets) > 1 && scope.config.mutating {
+		defer l.lockDir(job.dirPath)()
+	}
+
 	if l.MaxIssues > 0 && atomic.LoadInt64(totalIssues) >= int64(l.MaxIssues) {
 		return issueBatch{}, nil
 	}
 
+	l.Stats.countPackage(len(job.files))
+
 	if l.Cache.enabledForRun() {
-		return l.processCachedPackageJob(job, totalIssues)
+		return l.processCachedPackageJob(job, scope, totalIssues)
 	}
 
-	pkgFiles, pkgPaths, fset, suppressions := l.parsePackage(job.files)
+	pkgFiles, pkgPaths, fset, suppressions, sources := scope.config.parsePackage(job.files, l.Stats)
 	if len(pkgFiles) == 0 {
 		return issueBatch{}, nil
 	}
 
+	scope.sources = sources
+
 	issues, err := l.analyzePackage(pkgFiles, pkgPaths, fset, suppressions, l.issueBudgetFromTotal(totalIssues), func(current int) bool {
 		if l.MaxIssues <= 0 {
 			return false
 		}
 
 		return int(atomic.LoadInt64(totalIssues))+current >= l.MaxIssues
-	})
+	}, scope)
 	if err != nil {
 		return issueBatch{}, err
 	}
@@ -262,7 +450,7 @@ func (l *Linter) processPackageJob(job PackageJob, totalIssues *int64) (issueBat
 	return issueBatch{issues: issues}, nil
 }
 
-func (l *Linter) processCachedPackageJob(job PackageJob, totalIssues *int64) (issueBatch, error) {
+func (l *Linter) processCachedPackageJob(job PackageJob, scope packageScope, totalIssues *int64) (issueBatch, error) {
 	inputs := job.inputs
 	if len(inputs) == 0 {
 		var err error
@@ -272,30 +460,42 @@ func (l *Linter) processCachedPackageJob(job PackageJob, totalIssues *int64) (is
 		}
 	}
 
+	cache := scope.config.cacheFor(l.Cache).withModule(scope.module)
+	if scope.facts != nil {
+		cache = cache.withFacts(l.Facts.digest(job.deps))
+	}
+	cache = cache.withExpiry(inputs, today())
+
 	if l.MaxIssues <= 0 {
-		if cached, ok := l.Cache.loadRaw(inputs); ok {
+		if cached, ok := cache.loadRaw(inputs); ok && restoreFacts(cache, inputs, scope.facts) {
 			return issueBatch{cached: cached}, nil
 		}
-	} else if cached, ok := l.Cache.load(inputs, l.issueBudgetFromTotal(totalIssues)); ok {
+	} else if cached, ok := cache.load(inputs, l.issueBudgetFromTotal(totalIssues)); ok && restoreFacts(cache, inputs, scope.facts) {
 		return issueBatch{issues: l.limitIssuesByTotal(cached, totalIssues)}, nil
 	}
 
+	start := time.Now()
 	if err := loadPackageSources(inputs); err != nil {
 		return issueBatch{}, exception.InternalError("could not read package sources in %q: %w", job.dirPath, err)
 	}
+	l.Stats.since(PhaseRead, start)
 
-	pkgFiles, pkgPaths, fset, suppressions, complete := l.parsePackageInputs(inputs)
+	start = time.Now()
+	pkgFiles, pkgPaths, fset, suppressions, complete := scope.config.parsePackageInputs(inputs)
+	l.Stats.since(PhaseParse, start)
 	if len(pkgFiles) == 0 {
 		return issueBatch{}, nil
 	}
 
-	issues, err := l.analyzePackage(pkgFiles, pkgPaths, fset, suppressions, 0, nil)
+	scope.sources = sourcesOf(inputs)
+
+	issues, err := l.analyzePackage(pkgFiles, pkgPaths, fset, suppressions, 0, nil, scope)
 	if err != nil {
 		return issueBatch{}, err
 	}
 
 	if complete {
-		issues, err = l.refreshCacheForInputs(inputs, issues)
+		issues, err = l.refreshCacheForInputs(cache.untilNext(inputs, nextExpiry(suppressions)), inputs, issues, scope)
 		if err != nil {
 			return issueBatch{}, err
 		}
@@ -311,16 +511,20 @@ func (l *Linter) analyzePackage(
 	suppressions map[string][]rules.Suppression,
 	maxIssues int,
 	shouldStop func(int) bool,
+	scope packageScope,
 ) ([]rules.Issue, error) {
 	return l.Analyze(AnalysisParams{
 		pkgFiles:     pkgFiles,
 		pkgPaths:     pkgPaths,
 		fset:         fset,
 		maxIssues:    maxIssues,
-		autofix:      l.Write || l.Config.ShouldAutofix(),
-		rules:        l.ActiveRules,
+		autofix:      scope.config.autofix,
+		config:       scope.config,
 		suppressions: suppressions,
 		shouldStop:   shouldStop,
+		facts:        scope.facts,
+		module:       scope.module,
+		sources:      scope.sources,
 	})
 }
 
@@ -329,6 +533,7 @@ func (l *Linter) analyzePackageReadonly(
 	pkgPaths []string,
 	fset *token.FileSet,
 	suppressions map[string][]rules.Suppression,
+	scope packageScope,
 ) ([]rules.Issue, error) {
 	return l.Analyze(AnalysisParams{
 		pkgFiles:     pkgFiles,
@@ -336,18 +541,21 @@ func (l *Linter) analyzePackageReadonly(
 		fset:         fset,
 		maxIssues:    0,
 		autofix:      false,
-		rules:        l.ActiveRules,
+		config:       scope.config,
 		suppressions: suppressions,
+		facts:        scope.facts,
+		module:       scope.module,
+		sources:      scope.sources,
 	})
 }
 
-func (l *Linter) refreshCacheForInputs(inputs []packageInput, issues []rules.Issue) ([]rules.Issue, error) {
-	if !l.Cache.enabledForRun() || len(inputs) == 0 {
+func (l *Linter) refreshCacheForInputs(cache *cacheStore, inputs []packageInput, issues []rules.Issue, scope packageScope) ([]rules.Issue, error) {
+	if !cache.enabledForRun() || len(inputs) == 0 {
 		return issues, nil
 	}
 
-	if !l.Cache.mutating {
-		_ = l.Cache.save(inputs, issues)
+	if !cache.mutating {
+		saveCacheEntry(cache, inputs, issues, scope.facts)
 		return issues, nil
 	}
 
@@ -357,47 +565,105 @@ func (l *Linter) refreshCacheForInputs(inputs []packageInput, issues []rules.Iss
 	}
 
 	if !changed {
-		_ = l.Cache.save(refreshedInputs, issues)
+		saveCacheEntry(cache, refreshedInputs, issues, scope.facts)
 		return issues, nil
 	}
 
-	pkgFiles, pkgPaths, fset, suppressions, err := l.parsePackageInputsStrict(refreshedInputs)
+	start := time.Now()
+	pkgFiles, pkgPaths, fset, suppressions, err := scope.config.parsePackageInputsStrict(refreshedInputs)
 	if err != nil {
 		return issues, err
 	}
+	l.Stats.since(PhaseParse, start)
 
-	finalIssues, err := l.analyzePackageReadonly(pkgFiles, pkgPaths, fset, suppressions)
+	scope.sources = sourcesOf(refreshedInputs)
+	finalIssues, err := l.analyzePackageReadonly(pkgFiles, pkgPaths, fset, suppressions, scope)
 	if err != nil {
 		return issues, err
 	}
 
-	_ = l.Cache.save(refreshedInputs, finalIssues)
+	saveCacheEntry(cache, refreshedInputs, finalIssues, scope.facts)
 
 	return issues, nil
 }
 
-func (l *Linter) parsePackage(paths []string) ([]*ast.File, []string, *token.FileSet, map[string][]rules.Suppression) {
+// saveCacheEntry stores issues and, when fact rules are active, the facts the
+// package exported. Facts go first so a readable entry always has them.
+func saveCacheEntry(cache *cacheStore, inputs []packageInput, issues []rules.Issue, facts *rules.Facts) {
+	if facts != nil {
+		if err := cache.saveFacts(inputs, facts.Exported()); err != nil {
+			return
+		}
+	}
+
+	_ = cache.save(inputs, issues)
+}
+
+// restoreFacts loads the facts saved with a cache entry. Without them the
+// entry cannot be reused, since dependents need the facts.
+func restoreFacts(cache *cacheStore, inputs []packageInput, facts *rules.Facts) bool {
+	if facts == nil {
+		return true
+	}
+
+	saved, ok := cache.loadFacts(inputs)
+	if !ok {
+		return false
+	}
+
+	facts.Load(saved)
+
+	return true
+}
+
+func (s *configScope) parsePackage(paths []string, stats *RunStats) ([]*ast.File, []string, *token.FileSet, map[string][]rules.Suppression, map[string][]byte) {
 	fset := token.NewFileSet()
 	pkgFiles := make([]*ast.File, 0, len(paths))
 	pkgPaths := make([]string, 0, len(paths))
 	suppressions := make(map[string][]rules.Suppression, len(paths))
+	sources := make(map[string][]byte, len(paths))
 
 	for _, path := range paths {
-		file, err := parser.ParseFile(fset, path, nil, l.ParseMode)
+		start := time.Now()
+		src, err := os.ReadFile(path)
+		if err != nil {
+			render.Warnf("%s  could not read Go file: %v", path, err)
+			continue
+		}
+		stats.since(PhaseRead, start)
+
+		start = time.Now()
+		file, err := parser.ParseFile(fset, path, src, s.parseMode)
+		stats.since(PhaseParse, start)
 		if err != nil {
 			render.Warnf("%s  could not parse Go file: %v", path, err)
 			continue
 		}
 
-		suppressions[path] = rules.ProcessSuppressions(file.Comments, fset, file.Decls, file.Package)
+		if s.skipGenerated(path, file) {
+			continue
+		}
+
+		suppressions[path] = rules.ProcessSuppressions(file.Comments, fset, file.Decls, file.Package, s.suppressions)
+		sources[path] = src
 		pkgFiles = append(pkgFiles, file)
 		pkgPaths = append(pkgPaths, path)
 	}
 
-	return pkgFiles, pkgPaths, fset, suppressions
+	return pkgFiles, pkgPaths, fset, suppressions, sources
 }
 
-func (l *Linter) parsePackageInputs(inputs []packageInput) ([]*ast.File, []string, *token.FileSet, map[string][]rules.Suppression, bool) {
+// sourcesOf indexes the contents of loaded inputs by path.
+func sourcesOf(inputs []packageInput) map[string][]byte {
+	sources := make(map[string][]byte, len(inputs))
+	for _, input := range inputs {
+		sources[input.Path] = input.Src
+	}
+
+	return sources
+}
+
+func (s *configScope) parsePackageInputs(inputs []packageInput) ([]*ast.File, []string, *token.FileSet, map[string][]rules.Suppression, bool) {
 	fset := token.NewFileSet()
 	pkgFiles := make([]*ast.File, 0, len(inputs))
 	pkgPaths := make([]string, 0, len(inputs))
@@ -405,14 +671,18 @@ func (l *Linter) parsePackageInputs(inputs []packageInput) ([]*ast.File, []strin
 	complete := true
 
 	for _, input := range inputs {
-		file, err := parser.ParseFile(fset, input.Path, input.Src, l.ParseMode)
+		file, err := parser.ParseFile(fset, input.Path, input.Src, s.parseMode)
 		if err != nil {
 			complete = false
 			render.Warnf("%s  could not parse Go file: %v", input.Path, err)
 			continue
 		}
 
-		suppressions[input.Path] = rules.ProcessSuppressions(file.Comments, fset, file.Decls, file.Package)
+		if s.skipGenerated(input.Path, file) {
+			continue
+		}
+
+		suppressions[input.Path] = rules.ProcessSuppressions(file.Comments, fset, file.Decls, file.Package, s.suppressions)
 		pkgFiles = append(pkgFiles, file)
 		pkgPaths = append(pkgPaths, input.Path)
 	}
@@ -420,19 +690,23 @@ func (l *Linter) parsePackageInputs(inputs []packageInput) ([]*ast.File, []strin
 	return pkgFiles, pkgPaths, fset, suppressions, complete
 }
 
-func (l *Linter) parsePackageInputsStrict(inputs []packageInput) ([]*ast.File, []string, *token.FileSet, map[string][]rules.Suppression, error) {
+func (s *configScope) parsePackageInputsStrict(inputs []packageInput) ([]*ast.File, []string, *token.FileSet, map[string][]rules.Suppression, error) {
 	fset := token.NewFileSet()
 	pkgFiles := make([]*ast.File, 0, len(inputs))
 	pkgPaths := make([]string, 0, len(inputs))
 	suppressions := make(map[string][]rules.Suppression, len(inputs))
 
 	for _, input := range inputs {
-		file, err := parser.ParseFile(fset, input.Path, input.Src, l.ParseMode)
+		file, err := parser.ParseFile(fset, input.Path, input.Src, s.parseMode)
 		if err != nil {
 			return nil, nil, nil, nil, exception.InternalError("applied fixes left %q invalid: %w", input.Path, err)
 		}
 
-		suppressions[input.Path] = rules.ProcessSuppressions(file.Comments, fset, file.Decls, file.Package)
+		if s.skipGenerated(input.Path, file) {
+			continue
+		}
+
+		suppressions[input.Path] = rules.ProcessSuppressions(file.Comments, fset, file.Decls, file.Package, s.suppressions)
 		pkgFiles = append(pkgFiles, file)
 		pkgPaths = append(pkgPaths, input.Path)
 	}
@@ -440,6 +714,30 @@ func (l *Linter) parsePackageInputsStrict(inputs []packageInput) ([]*ast.File, [
 	return pkgFiles, pkgPaths, fset, suppressions, nil
 }
 
+// nextExpiry returns the earliest until= date of the suppressions still in
+// force, after which results change, or "" when there is none.
+func nextExpiry(suppressions map[string][]rules.Suppression) string {
+	var next string
+
+	for _, sups := range suppressions {
+		for _, sup := range sups {
+			if sup.Until == "" || sup.IsExpired || sup.IsInvalid {
+				continue
+			}
+
+			if next == "" || sup.Until < next {
+				next = sup.Until
+			}
+		}
+	}
+
+	return next
+}
+
+func today() string {
+	return time.Now().Format(time.DateOnly)
+}
+
 func (l *Linter) limitIssuesByTotal(issues []rules.Issue, totalIssues *int64) []rules.Issue {
 	if l.MaxIssues <= 0 {
 		return issues
@@ -461,7 +759,45 @@ func (l *Linter) issueBudgetFromTotal(totalIssues *int64) int {
 	return remaining
 }
 
-func (l *Linter) walkPackages(root string, done <-chan struct{}, enqueue func(PackageJob) bool) error {
+// WalkFiles calls fn with every Go file a run over roots lints, in walk
+// order, leaving out what go.exclude and the build targets skip. A root
+// naming a file is passed as is.
+func (l *Linter) WalkFiles(roots []string, fn func(path string) error) error {
+	dirs := make([]string, 0, len(roots))
+
+	for _, root := range roots {
+		info, err := os.Stat(root)
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			dirs = append(dirs, root)
+		} else if err := fn(root); err != nil {
+			return err
+		}
+	}
+
+	var fnErr error
+
+	err := l.walkPackages(dirs, nil, func(job PackageJob) bool {
+		for _, path := range job.files {
+			if fnErr = fn(path); fnErr != nil {
+				return false
+			}
+		}
+
+		return true
+	})
+
+	if fnErr != nil {
+		return fnErr
+	}
+
+	return err
+}
+
+func (l *Linter) walkPackages(roots []string, done <-chan struct{}, enqueue func(PackageJob) bool) error {
 	var walk func(string) error
 
 	walk = func(dir string) error {
@@ -491,7 +827,7 @@ func (l *Linter) walkPackages(root string, done <-chan struct{}, enqueue func(Pa
 			path := filepath.Join(dir, name)
 
 			if entry.IsDir() {
-				if name == "vendor" || name == ".git" {
+				if name == "vendor" || name == ".git" || l.workspace.isModuleRoot(l.Modules, path) {
 					continue
 				}
 
@@ -499,7 +835,8 @@ func (l *Linter) walkPackages(root string, done <-chan struct{}, enqueue func(Pa
 				continue
 			}
 
-			if !strings.HasSuffix(name, ".go") {
+			// Like the go command, ignore files starting with "_" or ".".
+			if !strings.HasSuffix(name, ".go") || name[0] == '_' || name[0] == '.' {
 				continue
 			}
 
@@ -508,7 +845,7 @@ func (l *Linter) walkPackages(root string, done <-chan struct{}, enqueue func(Pa
 				if err != nil {
 					continue
 				}
-				if l.MaxFileSize > 0 && info.Size() > l.MaxFileSize {
+				if l.tooLarge(path, info.Size()) {
 					continue
 				}
 
@@ -519,7 +856,7 @@ func (l *Linter) walkPackages(root string, done <-chan struct{}, enqueue func(Pa
 
 			if l.MaxFileSize > 0 {
 				info, err := entry.Info()
-				if err == nil && info.Size() > l.MaxFileSize {
+				if err == nil && l.tooLarge(path, info.Size()) {
 					continue
 				}
 			}
@@ -527,15 +864,25 @@ func (l *Linter) walkPackages(root string, done <-chan struct{}, enqueue func(Pa
 			files = append(files, path)
 		}
 
-		if len(inputs) > 1 {
-			sort.Slice(inputs, func(i, j int) bool {
-				return inputs[i].NormalizedPath < inputs[j].NormalizedPath
-			})
+		var config *configScope
+		if len(files) > 0 {
+			if config, err = l.scopeFor(dir); err != nil {
+				return err
+			}
+
+			files, inputs = config.filterExcluded(files, inputs)
 		}
 
 		if len(files) > 0 {
-			if !enqueue(PackageJob{dirPath: dir, files: files, inputs: inputs}) {
-				return nil
+			module := l.Modules.lookup(dir)
+
+			for _, job := range l.splitPackages(dir, files, inputs) {
+				job.module = module
+				job.config = config
+				render.Debugf("%s  walked package %s (%d Go files)", job.dirPath, job.pkgName, len(job.files))
+				if !enqueue(job) {
+					return nil
+				}
 			}
 		}
 
@@ -548,7 +895,48 @@ func (l *Linter) walkPackages(root string, done <-chan struct{}, enqueue func(Pa
 		return nil
 	}
 
-	return walk(root)
+	for _, root := range roots {
+		if err := walk(root); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// filterExcluded drops the files go.exclude skips, along with their inputs
+// when the cache probed them.
+func (s *configScope) filterExcluded(files []string, inputs []packageInput) ([]string, []packageInput) {
+	if len(s.exclude) == 0 {
+		return files, inputs
+	}
+
+	kept := files[:0]
+	keptInputs := inputs[:0]
+
+	for i, path := range files {
+		if s.excluded(path) {
+			continue
+		}
+
+		kept = append(kept, path)
+		if len(inputs) > 0 {
+			keptInputs = append(keptInputs, inputs[i])
+		}
+	}
+
+	return kept, keptInputs
+}
+
+// tooLarge reports whether the file at path is skipped for MaxFileSize.
+func (l *Linter) tooLarge(path string, size int64) bool {
+	if l.MaxFileSize <= 0 || size <= l.MaxFileSize {
+		return false
+	}
+
+	render.Debugf("%s  skipped, %d bytes is over the max file size of %d", path, size, l.MaxFileSize)
+
+	return true
 }
 
 func reloadPackageInputs(inputs []packageInput) ([]packageInput, bool, error) {
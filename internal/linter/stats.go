@@ -0,0 +1,171 @@
+package linter
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Phase is a part of a run timed by RunStats.
+type Phase int
+
+const (
+	PhaseWalk Phase = iota
+	PhaseRead
+	PhaseParse
+	PhaseConstAnalysis
+	PhaseRules
+	PhaseAnalyzers
+	PhaseCacheLoad
+	PhaseCacheSave
+	phaseCount
+)
+
+var phaseNames = [phaseCount]string{
+	PhaseWalk:          "walk",
+	PhaseRead:          "read",
+	PhaseParse:         "parse",
+	PhaseConstAnalysis: "const analysis",
+	PhaseRules:         "rules",
+	PhaseAnalyzers:     "analyzers",
+	PhaseCacheLoad:     "cache load",
+	PhaseCacheSave:     "cache save",
+}
+
+func (p Phase) String() string {
+	return phaseNames[p]
+}
+
+// RunStats times the phases of a run and each rule when set as
+// Linter.Stats. Times are summed over the workers, so with more than one
+// they add up to more than the run took. A nil *RunStats records nothing.
+type RunStats struct {
+	phases   [phaseCount]atomic.Int64
+	files    atomic.Int64
+	packages atomic.Int64
+
+	mu    sync.Mutex
+	rules map[string]*RuleTiming
+}
+
+type RuleTiming struct {
+	Name  string
+	Time  time.Duration
+	Calls int64
+}
+
+type PhaseTiming struct {
+	Phase Phase
+	Time  time.Duration
+}
+
+// StatsReport is what RunStats recorded, with the rules slowest first.
+type StatsReport struct {
+	Phases   []PhaseTiming
+	Rules    []RuleTiming
+	Files    int64
+	Packages int64
+	Cache    *CacheRun // nil when the cache is disabled
+}
+
+// ruleTimings collects the time of each rule over one file, to be merged
+// into the run's at once.
+type ruleTimings map[string]*RuleTiming
+
+func NewRunStats() *RunStats {
+	return &RunStats{rules: make(map[string]*RuleTiming, 64)}
+}
+
+// since adds the time elapsed from start to phase.
+func (s *RunStats) since(phase Phase, start time.Time) {
+	if s != nil {
+		s.phases[phase].Add(int64(time.Since(start)))
+	}
+}
+
+func (s *RunStats) add(phase Phase, d time.Duration) {
+	if s != nil {
+		s.phases[phase].Add(int64(d))
+	}
+}
+
+func (s *RunStats) countPackage(files int) {
+	if s != nil {
+		s.packages.Add(1)
+		s.files.Add(int64(files))
+	}
+}
+
+func (s *RunStats) addRules(timings ruleTimings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, timing := range timings {
+		total := s.rules[name]
+		if total == nil {
+			total = &RuleTiming{Name: name}
+			s.rules[name] = total
+		}
+
+		total.Time += timing.Time
+		total.Calls += timing.Calls
+	}
+}
+
+func (t ruleTimings) add(name string, d time.Duration) {
+	timing := t[name]
+	if timing == nil {
+		timing = &RuleTiming{Name: name}
+		t[name] = timing
+	}
+
+	timing.Time += d
+	timing.Calls++
+}
+
+// StatsReport returns what l.Stats recorded so far, or nil without it.
+func (l *Linter) StatsReport() *StatsReport {
+	s := l.Stats
+	if s == nil {
+		return nil
+	}
+
+	report := &StatsReport{
+		Phases:   make([]PhaseTiming, 0, phaseCount),
+		Files:    s.files.Load(),
+		Packages: s.packages.Load(),
+	}
+
+	for phase := range phaseCount {
+		report.Phases = append(report.Phases, PhaseTiming{Phase: phase, Time: time.Duration(s.phases[phase].Load())})
+	}
+
+	if c := l.Cache; c.enabledForRun() {
+		report.Phases[PhaseCacheLoad].Time += time.Duration(c.run.loading.Load())
+		report.Phases[PhaseCacheSave].Time += time.Duration(c.run.saving.Load())
+		report.Cache = &CacheRun{
+			Hits:   c.run.hits.Load(),
+			Misses: c.run.misses.Load(),
+			Writes: c.run.writes.Load(),
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report.Rules = make([]RuleTiming, 0, len(s.rules))
+	for _, timing := range s.rules {
+		report.Rules = append(report.Rules, *timing)
+	}
+
+	sort.Slice(report.Rules, func(i, j int) bool {
+		if report.Rules[i].Time != report.Rules[j].Time {
+			return report.Rules[i].Time > report.Rules[j].Time
+		}
+
+		return report.Rules[i].Name < report.Rules[j].Name
+	})
+
+	return report
+}
@@ -4,6 +4,8 @@ import (
 	"go/ast"
 	"go/token"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/serenitysz/serenity/internal/rules"
 )
@@ -15,8 +17,24 @@ const (
 
 type PackageJob struct {
 	dirPath string
+	pkgName string
 	files   []string
 	inputs  []packageInput
+	module  *rules.Module
+	config  *configScope
+
+	// pkgPath and deps are only set when fact rules schedule packages in
+	// import order.
+	pkgPath string
+	deps    []string
+}
+
+// packageScope is what a package's analysis knows beyond its files.
+type packageScope struct {
+	facts   *rules.Facts
+	module  *rules.Module
+	config  *configScope      // nil means the run's own config
+	sources map[string][]byte // Contents of the files as read, by path
 }
 
 type cachedBatch struct {
@@ -24,6 +42,7 @@ type cachedBatch struct {
 	issueCount int
 	issueStart int
 	inputs     []packageInput
+	root       string
 }
 
 type issueBatch struct {
@@ -46,67 +65,143 @@ type AnalysisParams struct {
 	maxIssues    int
 	autofix      bool
 	shouldStop   func(int) bool
-	rules        *ActiveRules
+	config       *configScope
 	suppressions map[string][]rules.Suppression
+	facts        *rules.Facts
+	module       *rules.Module
+	sources      map[string][]byte
 }
 
 type ActiveRules struct {
-	File       []rules.Rule
-	FuncDecl   []rules.Rule
-	FuncLit    []rules.Rule
-	TypeSpec   []rules.Rule
-	ValueSpec  []rules.Rule
-	Field      []rules.Rule
-	ImportSpec []rules.Rule
-	ReturnStmt []rules.Rule
-	BasicLit   []rules.Rule
-	CallExpr   []rules.Rule
-	BlockStmt  []rules.Rule
-	BinaryExpr []rules.Rule
-	ForStmt    []rules.Rule
-	RangeStmt  []rules.Rule
-	AssignStmt []rules.Rule
-	DeferStmt  []rules.Rule
+	byKind [nodeKindCount][]rules.Rule
+
+	// factVersions identifies the active fact rules and their fact encoding.
+	factVersions []string
+
+	// added keeps every rule with the Go release it needs, so the set can be
+	// narrowed for modules declaring an older one.
+	added     []versionedRule
+	versioned bool
+
+	mu        sync.Mutex
+	byVersion map[string]*ActiveRules
 
 	NeedsConstAnalysis    bool
 	HasAutofixRules       bool
 	HasUnsafeAutofixRules bool
 }
 
+type versionedRule struct {
+	rule  rules.Rule
+	minGo string
+}
+
+// Add registers rule for every node type it lists in Targets.
+func (a *ActiveRules) Add(rule rules.Rule) {
+	var minGo string
+	if vr, ok := rule.(rules.VersionedRule); ok {
+		minGo = vr.MinGoVersion()
+	}
+
+	a.AddSince(rule, minGo)
+}
+
+// AddSince registers rule for modules whose go directive is at least minGo.
+func (a *ActiveRules) AddSince(rule rules.Rule, minGo string) {
+	a.added = append(a.added, versionedRule{rule: rule, minGo: minGo})
+	if minGo != "" {
+		a.versioned = true
+	}
+
+	a.register(rule)
+}
+
+func (a *ActiveRules) register(rule rules.Rule) {
+	for _, target := range rule.Targets() {
+		if kind := kindOf(target); kind != kindUnknown {
+			a.byKind[kind] = append(a.byKind[kind], rule)
+		}
+	}
+
+	if fr, ok := rule.(rules.FactRule); ok {
+		a.factVersions = append(a.factVersions, fr.Name()+"@"+fr.FactVersion())
+	}
+}
+
+// forModule returns the rules that apply to mod, dropping the ones that need a
+// newer Go release than it declares.
+func (a *ActiveRules) forModule(mod *rules.Module) *ActiveRules {
+	if a == nil || !a.versioned || mod == nil || mod.GoVersion == "" {
+		return a
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if narrowed, ok := a.byVersion[mod.GoVersion]; ok {
+		return narrowed
+	}
+
+	narrowed := &ActiveRules{
+		NeedsConstAnalysis:    a.NeedsConstAnalysis,
+		HasAutofixRules:       a.HasAutofixRules,
+		HasUnsafeAutofixRules: a.HasUnsafeAutofixRules,
+	}
+
+	for _, added := range a.added {
+		if mod.AtLeast(added.minGo) {
+			narrowed.added = append(narrowed.added, added)
+			narrowed.register(added.rule)
+		}
+	}
+
+	if a.byVersion == nil {
+		a.byVersion = make(map[string]*ActiveRules, 2)
+	}
+	a.byVersion[mod.GoVersion] = narrowed
+
+	return narrowed
+}
+
+// Names returns the names of the rules in the order they were added.
+func (a *ActiveRules) Names() []string {
+	names := make([]string, 0, len(a.added))
+
+	for _, added := range a.added {
+		names = append(names, added.rule.Name())
+	}
+
+	return names
+}
+
+func (a *ActiveRules) count() int {
+	if a == nil {
+		return 0
+	}
+
+	return len(a.added)
+}
+
+func (a *ActiveRules) usesFacts() bool {
+	return len(a.factVersions) > 0
+}
+
 func (a *ActiveRules) Run(runner *rules.Runner, node ast.Node) {
-	switch n := node.(type) {
-	case *ast.File:
-		runRules(a.File, runner, n)
-	case *ast.FuncDecl:
-		runRules(a.FuncDecl, runner, n)
-	case *ast.FuncLit:
-		runRules(a.FuncLit, runner, n)
-	case *ast.TypeSpec:
-		runRules(a.TypeSpec, runner, n)
-	case *ast.ValueSpec:
-		runRules(a.ValueSpec, runner, n)
-	case *ast.Field:
-		runRules(a.Field, runner, n)
-	case *ast.ImportSpec:
-		runRules(a.ImportSpec, runner, n)
-	case *ast.ReturnStmt:
-		runRules(a.ReturnStmt, runner, n)
-	case *ast.BasicLit:
-		runRules(a.BasicLit, runner, n)
-	case *ast.CallExpr:
-		runRules(a.CallExpr, runner, n)
-	case *ast.BlockStmt:
-		runRules(a.BlockStmt, runner, n)
-	case *ast.BinaryExpr:
-		runRules(a.BinaryExpr, runner, n)
-	case *ast.ForStmt:
-		runRules(a.ForStmt, runner, n)
-	case *ast.RangeStmt:
-		runRules(a.RangeStmt, runner, n)
-	case *ast.AssignStmt:
-		runRules(a.AssignStmt, runner, n)
-	case *ast.DeferStmt:
-		runRules(a.DeferStmt, runner, n)
+	if active := a.byKind[kindOf(node)]; len(active) > 0 {
+		runRules(active, runner, node)
+	}
+}
+
+// runTimed is Run adding the time each rule takes to timings.
+func (a *ActiveRules) runTimed(runner *rules.Runner, node ast.Node, timings ruleTimings) {
+	for _, rule := range a.byKind[kindOf(node)] {
+		start := time.Now()
+		rule.Run(runner, node)
+		timings.add(rule.Name(), time.Since(start))
+
+		if runner.ReachedMax() || (runner.ShouldStop != nil && runner.ShouldStop()) {
+			return
+		}
 	}
 }
 
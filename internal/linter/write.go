@@ -0,0 +1,66 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+)
+
+func writeFileAtomic(path string, data []byte) error {
+	mode := DEFAULT_FILE_MODE
+
+	info, err := os.Stat(path)
+	if err == nil {
+		mode = info.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	return replaceFile(path, data, mode)
+}
+
+// replaceFile writes data next to path and renames it into place, so readers
+// observe either the old or the new content and never a partial write. A
+// symlink is followed, so the file it points to is replaced and not the link.
+func replaceFile(path string, data []byte, mode os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	name := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(name)
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(name)
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(name)
+		return err
+	}
+
+	if err := os.Chmod(name, mode); err != nil {
+		_ = os.Remove(name)
+		return err
+	}
+
+	if err := os.Rename(name, path); err != nil {
+		_ = os.Remove(name)
+		return err
+	}
+
+	return nil
+}
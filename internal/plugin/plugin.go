@@ -0,0 +1,214 @@
+package plugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"go/ast"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/rules"
+	"github.com/serenitysz/serenity/pkg/serenity/rule"
+)
+
+// Set holds the rules provided by the configured plugins and the processes
+// backing them.
+type Set struct {
+	Rules []rules.Rule
+
+	clients []*client
+	stamp   []byte
+}
+
+func Load(opts []rules.PluginOptions) (*Set, error) {
+	set := &Set{}
+
+	if len(opts) == 0 {
+		return set, nil
+	}
+
+	compiled := make(map[string]rule.Rule, 8)
+	for _, r := range rule.Registered() {
+		compiled[r.Name()] = r
+	}
+
+	for _, opt := range opts {
+		if opt.Name == "" {
+			return nil, exception.CommandError("every entry under `plugins` needs a name")
+		}
+
+		if opt.Path == "" {
+			r, err := loadCompiled(opt, compiled)
+			if err != nil {
+				return nil, err
+			}
+
+			set.Rules = append(set.Rules, r)
+			set.stamp = append(set.stamp, opt.Name...)
+			continue
+		}
+
+		r, c, err := loadProcess(opt)
+		if err != nil {
+			set.Close()
+			return nil, err
+		}
+
+		set.Rules = append(set.Rules, r)
+		set.clients = append(set.clients, c)
+		set.stamp = append(set.stamp, processStamp(opt.Path)...)
+	}
+
+	return set, nil
+}
+
+// Stamp identifies the loaded plugins for cache keys, so that rebuilding a
+// plugin binary invalidates results it produced.
+func (s *Set) Stamp() string {
+	if s == nil || len(s.stamp) == 0 {
+		return ""
+	}
+
+	sum := sha256.Sum256(s.stamp)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Set) Close() error {
+	if s == nil {
+		return nil
+	}
+
+	var first error
+
+	for _, c := range s.clients {
+		if err := c.close(); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	s.clients = nil
+
+	return first
+}
+
+func loadCompiled(opt rules.PluginOptions, compiled map[string]rule.Rule) (rules.Rule, error) {
+	names := make([]string, 0, len(opt.Rules))
+	for name := range opt.Rules {
+		names = append(names, name)
+	}
+
+	if len(names) == 0 {
+		for name := range compiled {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	selected := make([]rule.Rule, 0, len(names))
+	for _, name := range names {
+		r, ok := compiled[name]
+		if !ok {
+			return nil, exception.CommandError("plugin %q: rule %q is not compiled into this binary; build one with `serenity build --with`", opt.Name, name)
+		}
+
+		selected = append(selected, r)
+	}
+
+	return &compiledRule{
+		name:       opt.Name,
+		rules:      selected,
+		severities: severities(opt.Rules),
+	}, nil
+}
+
+func processStamp(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return path
+	}
+
+	return path + ":" + strconv.FormatInt(info.Size(), 10) + ":" + strconv.FormatInt(info.ModTime().UnixNano(), 10)
+}
+
+func severities(cfg map[string]*rules.LinterBaseRule) map[string]rules.Severity {
+	out := make(map[string]rules.Severity, len(cfg))
+
+	for name, base := range cfg {
+		sev := ""
+		if base != nil {
+			sev = base.Severity
+		}
+
+		out[name] = rules.ParseSeverity(sev)
+	}
+
+	return out
+}
+
+func report(runner *rules.Runner, issue rule.Issue, sev map[string]rules.Severity) {
+	severity, ok := sev[issue.Rule]
+	if !ok {
+		severity = rules.SeverityWarn
+	}
+
+	runner.Report(issuePos(runner, issue), rules.Issue{
+		ID:       rules.PluginIssueID,
+		Severity: severity,
+		ArgStr1:  rules.PackContext2(issue.Rule, issue.Message),
+	})
+}
+
+func issuePos(runner *rules.Runner, issue rule.Issue) token.Pos {
+	file := runner.Fset.File(runner.File.Pos())
+	if file == nil || issue.Line < 1 || issue.Line > file.LineCount() {
+		return runner.File.Package
+	}
+
+	pos := file.LineStart(issue.Line)
+	if issue.Column > 1 && file.Offset(pos)+issue.Column-1 <= file.Size() {
+		pos += token.Pos(issue.Column - 1)
+	}
+
+	return pos
+}
+
+func filePath(runner *rules.Runner) string {
+	if file := runner.Fset.File(runner.File.Pos()); file != nil {
+		return file.Name()
+	}
+
+	return ""
+}
+
+type compiledRule struct {
+	name       string
+	rules      []rule.Rule
+	severities map[string]rules.Severity
+}
+
+func (r *compiledRule) Name() string {
+	return r.name
+}
+
+func (r *compiledRule) Targets() []ast.Node {
+	return []ast.Node{(*ast.File)(nil)}
+}
+
+func (r *compiledRule) Run(runner *rules.Runner, node ast.Node) {
+	if runner.ShouldStop != nil && runner.ShouldStop() {
+		return
+	}
+
+	for _, issue := range rule.Check(runner.Fset, node.(*ast.File), filePath(runner), r.rules) {
+		if runner.ReachedMax() {
+			return
+		}
+
+		report(runner, issue, r.severities)
+	}
+}
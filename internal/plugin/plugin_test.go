@@ -0,0 +1,116 @@
+package plugin
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/serenitysz/serenity/internal/rules"
+	"github.com/serenitysz/serenity/pkg/serenity/rule"
+)
+
+type todoRule struct{}
+
+func (todoRule) Name() string { return "no-todo-funcs" }
+
+func (todoRule) Targets() []ast.Node { return []ast.Node{(*ast.FuncDecl)(nil)} }
+
+func (todoRule) Run(runner *rule.Runner, node ast.Node) {
+	fn := node.(*ast.FuncDecl)
+	if fn.Name.Name == "todo" {
+		runner.Reportf(fn.Name, "function %q is a placeholder", fn.Name.Name)
+	}
+}
+
+func TestProcessRuleValidatesConfiguredRules(t *testing.T) {
+	t.Parallel()
+
+	reqR, reqW := io.Pipe()
+	respR, respW := io.Pipe()
+
+	go func() {
+		_ = rule.ServeIO(reqR, respW, todoRule{})
+		_ = respW.Close()
+	}()
+
+	c := newClient(reqW, respR)
+	defer c.close()
+
+	_, err := newProcessRule(rules.PluginOptions{
+		Name:  "house",
+		Rules: map[string]*rules.LinterBaseRule{"missing": {Severity: "error"}},
+	}, c)
+	if err == nil {
+		t.Fatal("expected an error for a rule the plugin does not provide")
+	}
+
+	r, err := newProcessRule(rules.PluginOptions{
+		Name:  "house",
+		Rules: map[string]*rules.LinterBaseRule{"no-todo-funcs": {Severity: "error"}},
+	}, c)
+	if err != nil {
+		t.Fatalf("newProcessRule failed: %v", err)
+	}
+
+	resp, err := c.call(rule.Request{
+		Method: rule.MethodCheck,
+		Path:   "sample.go",
+		Src:    []byte("package sample\n\nfunc todo() {}\n"),
+		Rules:  r.enabled,
+	})
+	if err != nil {
+		t.Fatalf("check call failed: %v", err)
+	}
+
+	if len(resp.Issues) != 1 || resp.Issues[0].Line != 3 {
+		t.Fatalf("expected one issue on line 3, got %+v", resp.Issues)
+	}
+
+	if r.severities["no-todo-funcs"] != rules.SeverityError {
+		t.Fatalf("expected configured severity to be kept, got %v", r.severities)
+	}
+}
+
+func TestProcessRuleChecksTheSourceInMemory(t *testing.T) {
+	t.Parallel()
+
+	reqR, reqW := io.Pipe()
+	respR, respW := io.Pipe()
+
+	go func() {
+		_ = rule.ServeIO(reqR, respW, todoRule{})
+		_ = respW.Close()
+	}()
+
+	c := newClient(reqW, respR)
+	defer c.close()
+
+	r, err := newProcessRule(rules.PluginOptions{
+		Name:  "house",
+		Rules: map[string]*rules.LinterBaseRule{"no-todo-funcs": {Severity: "error"}},
+	}, c)
+	if err != nil {
+		t.Fatalf("newProcessRule failed: %v", err)
+	}
+
+	// The file is not on disk, so the plugin can only get the source from
+	// the runner.
+	src := []byte("package sample\n\nfunc todo() {}\n")
+	path := filepath.Join(t.TempDir(), "sample.go")
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, 0)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	var issues []rules.Issue
+	r.Run(&rules.Runner{File: file, Src: src, Fset: fset, Issues: &issues}, file)
+
+	if len(issues) != 1 || issues[0].Line != 3 || issues[0].Path != path {
+		t.Fatalf("expected one issue on line 3 of %s, got %+v", path, issues)
+	}
+}
@@ -0,0 +1,205 @@
+package plugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"go/ast"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+
+	"github.com/serenitysz/serenity/internal/exception"
+	"github.com/serenitysz/serenity/internal/render"
+	"github.com/serenitysz/serenity/internal/rules"
+	"github.com/serenitysz/serenity/pkg/serenity/rule"
+)
+
+type client struct {
+	mu     sync.Mutex
+	in     io.WriteCloser
+	out    *bufio.Reader
+	cmd    *exec.Cmd
+	closed bool
+}
+
+func newClient(in io.WriteCloser, out io.Reader) *client {
+	return &client{in: in, out: bufio.NewReader(out)}
+}
+
+func startClient(path string, args []string) (*client, error) {
+	cmd := exec.Command(path, args...)
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	c := newClient(stdin, stdout)
+	c.cmd = cmd
+
+	return c, nil
+}
+
+func (c *client) call(req rule.Request) (rule.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var resp rule.Response
+
+	if c.closed {
+		return resp, errors.New("plugin process is closed")
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if _, err := c.in.Write(append(data, '\n')); err != nil {
+		return resp, err
+	}
+
+	line, err := c.out.ReadBytes('\n')
+	if err != nil {
+		return resp, err
+	}
+
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return resp, err
+	}
+
+	if resp.Error != "" {
+		return resp, errors.New(resp.Error)
+	}
+
+	return resp, nil
+}
+
+func (c *client) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	c.closed = true
+	err := c.in.Close()
+
+	if c.cmd != nil {
+		if waitErr := c.cmd.Wait(); err == nil {
+			err = waitErr
+		}
+	}
+
+	return err
+}
+
+func loadProcess(opt rules.PluginOptions) (rules.Rule, *client, error) {
+	c, err := startClient(opt.Path, opt.Args)
+	if err != nil {
+		return nil, nil, exception.InternalError("could not start plugin %q: %w", opt.Name, err)
+	}
+
+	r, err := newProcessRule(opt, c)
+	if err != nil {
+		_ = c.close()
+		return nil, nil, err
+	}
+
+	return r, c, nil
+}
+
+func newProcessRule(opt rules.PluginOptions, c *client) (*processRule, error) {
+	resp, err := c.call(rule.Request{Method: rule.MethodDescribe})
+	if err != nil {
+		return nil, exception.InternalError("could not describe plugin %q: %w", opt.Name, err)
+	}
+
+	provided := make(map[string]struct{}, len(resp.Rules))
+	for _, name := range resp.Rules {
+		provided[name] = struct{}{}
+	}
+
+	enabled := make([]string, 0, len(opt.Rules))
+	for name := range opt.Rules {
+		if _, ok := provided[name]; !ok {
+			return nil, exception.CommandError("plugin %q does not provide rule %q", opt.Name, name)
+		}
+
+		enabled = append(enabled, name)
+	}
+
+	sort.Strings(enabled)
+
+	return &processRule{
+		name:       opt.Name,
+		client:     c,
+		enabled:    enabled,
+		severities: severities(opt.Rules),
+	}, nil
+}
+
+type processRule struct {
+	name       string
+	client     *client
+	enabled    []string
+	severities map[string]rules.Severity
+}
+
+func (r *processRule) Name() string {
+	return r.name
+}
+
+func (r *processRule) Targets() []ast.Node {
+	return []ast.Node{(*ast.File)(nil)}
+}
+
+func (r *processRule) Run(runner *rules.Runner, node ast.Node) {
+	if runner.ShouldStop != nil && runner.ShouldStop() {
+		return
+	}
+
+	path := filePath(runner)
+
+	src := runner.Src
+	if src == nil {
+		var err error
+		if src, err = os.ReadFile(path); err != nil {
+			render.Warnf("%s  plugin %q could not read the file: %v", path, r.name, err)
+			return
+		}
+	}
+
+	resp, err := r.client.call(rule.Request{
+		Method: rule.MethodCheck,
+		Path:   path,
+		Src:    src,
+		Rules:  r.enabled,
+	})
+	if err != nil {
+		render.Warnf("%s  plugin %q failed: %v", path, r.name, err)
+		return
+	}
+
+	for _, issue := range resp.Issues {
+		if runner.ReachedMax() {
+			return
+		}
+
+		report(runner, issue, r.severities)
+	}
+}
@@ -26,3 +26,18 @@ func TestTagPadsLabelWithoutColor(t *testing.T) {
 		t.Fatalf("unexpected tag: %q", got)
 	}
 }
+
+func TestVerbosityEnablesLevelsUpToIt(t *testing.T) {
+	SetVerbosity(LevelDebug)
+	defer SetVerbosity(LevelQuiet)
+
+	if !Enabled(LevelDebug) || Tracing() {
+		t.Fatal("expected -v to enable debug output only")
+	}
+
+	SetVerbosity(LevelTrace)
+
+	if !Enabled(LevelDebug) || !Tracing() {
+		t.Fatal("expected -vv to enable debug and trace output")
+	}
+}
@@ -0,0 +1,45 @@
+package render
+
+import (
+	"os"
+	"sync/atomic"
+)
+
+// Verbosity levels, set with -v and -vv.
+const (
+	LevelQuiet = iota
+	LevelDebug
+	LevelTrace
+)
+
+var verbosity atomic.Int32
+
+func SetVerbosity(level int) {
+	verbosity.Store(int32(level))
+}
+
+// Enabled reports whether messages of level are printed. Callers check it
+// before building messages that cost something on hot paths.
+func Enabled(level int) bool {
+	return int(verbosity.Load()) >= level
+}
+
+func Tracing() bool {
+	return Enabled(LevelTrace)
+}
+
+// Debugf prints diagnostics asked for with -v, like the packages linted and
+// why the cache missed.
+func Debugf(format string, args ...any) {
+	if Enabled(LevelDebug) {
+		Logf(os.Stderr, "debug", Gray, format, args...)
+	}
+}
+
+// Tracef prints the details asked for with -vv, like what happened to each
+// file.
+func Tracef(format string, args ...any) {
+	if Enabled(LevelTrace) {
+		Logf(os.Stderr, "trace", Gray, format, args...)
+	}
+}
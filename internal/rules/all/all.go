@@ -0,0 +1,13 @@
+// Package all links every built-in rule package so their init functions
+// register them with the rules registry.
+package all
+
+import (
+	_ "github.com/serenitysz/serenity/internal/rules/bestpractices"
+	_ "github.com/serenitysz/serenity/internal/rules/complexity"
+	_ "github.com/serenitysz/serenity/internal/rules/correctness"
+	_ "github.com/serenitysz/serenity/internal/rules/errs"
+	_ "github.com/serenitysz/serenity/internal/rules/imports"
+	_ "github.com/serenitysz/serenity/internal/rules/naming"
+	_ "github.com/serenitysz/serenity/internal/rules/style"
+)
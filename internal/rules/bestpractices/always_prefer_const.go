@@ -10,6 +10,19 @@ type AlwaysPreferConstRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:                 rules.AlwaysPreferConstID,
+		Group:              "bestPractices",
+		Key:                "alwaysPreferConst",
+		Description:        "Reports package variables that are never reassigned and could be constants.",
+		Message:            "replace variable with a constant",
+		NeedsConstAnalysis: true,
+	}, &rules.LinterBaseRule{Severity: "warn"}, func(opts *rules.LinterBaseRule) rules.Rule {
+		return &AlwaysPreferConstRule{Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (a *AlwaysPreferConstRule) Name() string {
 	return "always-prefer-const"
 }
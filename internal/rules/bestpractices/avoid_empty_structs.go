@@ -10,6 +10,18 @@ type AvoidEmptyStructsRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:          rules.AvoidEmptyStructsID,
+		Group:       "bestPractices",
+		Key:         "avoidEmptyStructs",
+		Description: "Reports empty struct type declarations.",
+		Message:     "empty struct declarations are not allowed",
+	}, &rules.LinterBaseRule{Severity: "warn"}, func(opts *rules.LinterBaseRule) rules.Rule {
+		return &AvoidEmptyStructsRule{Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (a *AvoidEmptyStructsRule) Name() string {
 	return "avoid-empty-structs"
 }
@@ -11,6 +11,19 @@ type ContextFirstRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:          rules.UseContextInFirstParamID,
+		Group:       "bestPractices",
+		Key:         "useContextInFirstParam",
+		Description: "Reports functions whose context.Context parameter is not the first one.",
+		Message:     "context.Context should be the first parameter",
+		UnsafeFix:   true,
+	}, &rules.LinterBaseRule{Severity: "warn"}, func(opts *rules.LinterBaseRule) rules.Rule {
+		return &ContextFirstRule{Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (c *ContextFirstRule) Name() string {
 	return "context-first-param"
 }
@@ -11,6 +11,18 @@ type GetMustReturnValueRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:          rules.GetMustReturnValueID,
+		Group:       "bestPractices",
+		Key:         "getMustReturnValue",
+		Description: "Reports Get-prefixed functions that return no value besides an error.",
+		Message:     `functions whose names start with "Get" should return at least one non-error value`,
+	}, &rules.LinterBaseRule{Severity: "warn"}, func(opts *rules.LinterBaseRule) rules.Rule {
+		return &GetMustReturnValueRule{Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (r *GetMustReturnValueRule) Name() string {
 	return "get-must-return-value"
 }
@@ -4,6 +4,7 @@ import (
 	"go/ast"
 
 	"github.com/serenitysz/serenity/internal/rules"
+	"github.com/serenitysz/serenity/internal/utils"
 )
 
 type MaxParamsRule struct {
@@ -11,6 +12,18 @@ type MaxParamsRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:          rules.MaxParamsID,
+		Group:       "bestPractices",
+		Key:         "maxParams",
+		Description: "Reports functions with more parameters than the configured limit.",
+		Message:     "function exceeds the parameter limit",
+	}, &rules.AnyMaxValueBasedRule{Severity: "warn", Max: utils.Ptr[uint16](5)}, func(opts *rules.AnyMaxValueBasedRule) rules.Rule {
+		return &MaxParamsRule{Limit: *opts.Max, Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (r *MaxParamsRule) Name() string {
 	return "max-params"
 }
@@ -10,6 +10,18 @@ type NoBareReturnsRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:          rules.NoBareReturnsID,
+		Group:       "bestPractices",
+		Key:         "noBareReturns",
+		Description: "Reports bare returns in functions with named results.",
+		Message:     "avoid bare returns",
+	}, &rules.LinterBaseRule{Severity: "warn"}, func(opts *rules.LinterBaseRule) rules.Rule {
+		return &NoBareReturnsRule{Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (n *NoBareReturnsRule) Name() string {
 	return "no-bare-returns"
 }
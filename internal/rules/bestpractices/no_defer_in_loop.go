@@ -10,6 +10,18 @@ type NoDeferInLoopRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:          rules.NoDeferInLoopID,
+		Group:       "bestPractices",
+		Key:         "noDeferInLoop",
+		Description: "Reports defer statements inside loops, which only run when the function returns.",
+		Message:     "avoid defer inside loops",
+	}, &rules.LinterBaseRule{Severity: "warn"}, func(opts *rules.LinterBaseRule) rules.Rule {
+		return &NoDeferInLoopRule{Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (d *NoDeferInLoopRule) Name() string {
 	return "no-defer-in-loop"
 }
@@ -11,8 +11,20 @@ type NoMagicNumbersRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:          rules.NoMagicNumbersID,
+		Group:       "bestPractices",
+		Key:         "noMagicNumbers",
+		Description: "Reports numeric literals that should be named constants.",
+		Message:     "extract magic number into a named constant",
+	}, &rules.LinterBaseRule{Severity: "warn"}, func(opts *rules.LinterBaseRule) rules.Rule {
+		return &NoMagicNumbersRule{Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (n *NoMagicNumbersRule) Name() string {
-	return "no-magic-number"
+	return "no-magic-numbers"
 }
 
 func (n *NoMagicNumbersRule) Targets() []ast.Node {
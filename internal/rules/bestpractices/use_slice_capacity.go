@@ -11,6 +11,19 @@ type UseSliceCapacityRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:          rules.UseSliceCapacityID,
+		Group:       "bestPractices",
+		Key:         "useSliceCapacity",
+		Description: "Reports slices built in a loop of known length without a preallocated capacity.",
+		Message:     "provide slice capacity when the length is known upfront",
+		Fixable:     true,
+	}, &rules.LinterBaseRule{Severity: "warn"}, func(opts *rules.LinterBaseRule) rules.Rule {
+		return &UseSliceCapacityRule{Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (u *UseSliceCapacityRule) Name() string {
 	return "use-slice-capacity"
 }
@@ -4,6 +4,7 @@ import (
 	"go/ast"
 
 	"github.com/serenitysz/serenity/internal/rules"
+	"github.com/serenitysz/serenity/internal/utils"
 )
 
 type CheckMaxFuncLinesRule struct {
@@ -11,6 +12,18 @@ type CheckMaxFuncLinesRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:          rules.MaxFuncLinesID,
+		Group:       "complexity",
+		Key:         "maxFuncLines",
+		Description: "Reports functions longer than the configured number of lines.",
+		Message:     "function exceeds the line limit",
+	}, &rules.AnyMaxValueBasedRule{Severity: "warn", Max: utils.Ptr[uint16](20)}, func(opts *rules.AnyMaxValueBasedRule) rules.Rule {
+		return &CheckMaxFuncLinesRule{Limit: int16(*opts.Max), Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (c *CheckMaxFuncLinesRule) Name() string {
 	return "max-func-lines"
 }
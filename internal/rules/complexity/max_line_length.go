@@ -4,6 +4,7 @@ import (
 	"go/ast"
 
 	"github.com/serenitysz/serenity/internal/rules"
+	"github.com/serenitysz/serenity/internal/utils"
 )
 
 type CheckMaxLineLengthRule struct {
@@ -11,6 +12,18 @@ type CheckMaxLineLengthRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:          rules.MaxLineLengthID,
+		Group:       "complexity",
+		Key:         "maxLineLength",
+		Description: "Reports lines longer than the configured number of characters.",
+		Message:     "line has %d characters; limit is %d",
+	}, &rules.AnyMaxValueBasedRule{Severity: "warn", Max: utils.Ptr[uint16](80)}, func(opts *rules.AnyMaxValueBasedRule) rules.Rule {
+		return &CheckMaxLineLengthRule{Limit: int(*opts.Max), Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (c *CheckMaxLineLengthRule) Name() string {
 	return "max-line-length"
 }
@@ -4,6 +4,7 @@ import (
 	"go/ast"
 
 	"github.com/serenitysz/serenity/internal/rules"
+	"github.com/serenitysz/serenity/internal/utils"
 )
 
 type AmbiguousReturnRule struct {
@@ -11,6 +12,19 @@ type AmbiguousReturnRule struct {
 	MaxAllowed int
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:              rules.AmbiguousReturnID,
+		Group:           "correctness",
+		Key:             "ambiguousReturns",
+		Description:     "Reports functions returning several unnamed values of the same type.",
+		Message:         "function returns too many unnamed values of the same type",
+		RunsOnGenerated: true,
+	}, &rules.AmbiguousReturnsRule{Severity: "warn", MaxUnnamedSameType: utils.Ptr(1)}, func(opts *rules.AmbiguousReturnsRule) rules.Rule {
+		return &AmbiguousReturnRule{Severity: rules.ParseSeverity(opts.Severity), MaxAllowed: *opts.MaxUnnamedSameType}
+	})
+}
+
 func (r *AmbiguousReturnRule) Name() string {
 	return "ambiguous-return"
 }
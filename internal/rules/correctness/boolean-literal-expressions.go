@@ -11,6 +11,20 @@ type BooleanLiteralExpressionsRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:              rules.BoolLiteralExpressionsID,
+		Group:           "correctness",
+		Key:             "boolLiteralExpressions",
+		Description:     "Reports comparisons against boolean literals that can be simplified.",
+		Message:         "simplify boolean literal expressions",
+		Fixable:         true,
+		RunsOnGenerated: true,
+	}, &rules.LinterBaseRule{Severity: "warn"}, func(opts *rules.LinterBaseRule) rules.Rule {
+		return &BooleanLiteralExpressionsRule{Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (r *BooleanLiteralExpressionsRule) Name() string {
 	return "boolean-literal-expressions"
 }
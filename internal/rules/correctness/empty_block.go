@@ -10,6 +10,19 @@ type EmptyBlockRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:              rules.EmptyBlockID,
+		Group:           "correctness",
+		Key:             "emptyBlock",
+		Description:     "Reports empty blocks without an explanatory comment.",
+		Message:         "empty block; remove it or add a clarifying comment",
+		RunsOnGenerated: true,
+	}, &rules.LinterBaseRule{Severity: "warn"}, func(opts *rules.LinterBaseRule) rules.Rule {
+		return &EmptyBlockRule{Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (r *EmptyBlockRule) Name() string {
 	return "empty-block"
 }
@@ -5,12 +5,28 @@ import (
 	"go/token"
 
 	"github.com/serenitysz/serenity/internal/rules"
+	"github.com/serenitysz/serenity/internal/rules/flow"
 )
 
 type ErrorNotWrappedRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:              rules.ErrorNotWrappedID,
+		Group:           "errors",
+		Key:             "errorNotWrapped",
+		Description:     "Reports errors returned from a call without being wrapped with context.",
+		Message:         "error should be wrapped before it is returned",
+		Fixable:         true,
+		RunsOnGenerated: true,
+		MinGoVersion:    "1.13", // %w in fmt.Errorf
+	}, &rules.LinterBaseRule{Severity: "warn"}, func(opts *rules.LinterBaseRule) rules.Rule {
+		return &ErrorNotWrappedRule{Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (r *ErrorNotWrappedRule) Name() string {
 	return "error-not-wrapped"
 }
@@ -40,6 +56,10 @@ func (r *ErrorNotWrappedRule) Run(runner *rules.Runner, node ast.Node) {
 		return
 	}
 
+	if !returnsError(runner.CurrentFunc) || !fromCall(runner.Flow(), ret, ident.Name) {
+		return
+	}
+
 	issue := rules.Issue{
 		ArgStr1:  rules.PackContext2(ident.Name, rules.CurrentFunctionName(runner)),
 		ID:       rules.ErrorNotWrappedID,
@@ -81,3 +101,57 @@ func selectorForImport(importName, ident string) ast.Expr {
 		Sel: ast.NewIdent(ident),
 	}
 }
+
+// returnsError reports whether fn has a single result of type error.
+func returnsError(fn *rules.FunctionContext) bool {
+	if fn == nil {
+		return false
+	}
+
+	var ftype *ast.FuncType
+
+	switch n := fn.Node.(type) {
+	case *ast.FuncDecl:
+		ftype = n.Type
+	case *ast.FuncLit:
+		ftype = n.Type
+	}
+
+	if ftype == nil || ftype.Results == nil || ftype.Results.NumFields() != 1 {
+		return false
+	}
+
+	id, ok := ftype.Results.List[0].Type.(*ast.Ident)
+
+	return ok && id.Name == "error"
+}
+
+// fromCall reports whether some definition of name reaching ret takes the
+// error result of a call. Errors built on the spot with errors.New or
+// fmt.Errorf, and parameters passed through, carry no context to add.
+func fromCall(f *flow.Func, ret *ast.ReturnStmt, name string) bool {
+	if f == nil {
+		return false
+	}
+
+	for _, def := range f.Reaching(ret, name) {
+		call, ok := def.Value.(*ast.CallExpr)
+		if !ok || isErrorConstructor(call) {
+			continue
+		}
+
+		if !def.Tuple {
+			return true
+		}
+
+		if assign, ok := def.Node.(*ast.AssignStmt); ok && def.Index == len(assign.Lhs)-1 {
+			return true
+		}
+
+		if spec, ok := def.Node.(*ast.ValueSpec); ok && def.Index == len(spec.Names)-1 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -14,6 +14,20 @@ type ErrorStringFormatRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:              rules.ErrorStringFormatID,
+		Group:           "errors",
+		Key:             "errorStringFormat",
+		Description:     "Reports error strings that are capitalized or end with punctuation.",
+		Message:         "error message should start with a lowercase letter and should not end with punctuation",
+		Fixable:         true,
+		RunsOnGenerated: true,
+	}, &rules.LinterBaseRule{Severity: "warn"}, func(opts *rules.LinterBaseRule) rules.Rule {
+		return &ErrorStringFormatRule{Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (r *ErrorStringFormatRule) Name() string {
 	return "error-string-format"
 }
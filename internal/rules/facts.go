@@ -0,0 +1,163 @@
+package rules
+
+import (
+	"encoding/json"
+	"go/ast"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FactRule is implemented by rules that share facts between packages. While
+// one is active, packages are analyzed in import order so the facts of every
+// dependency inside the module are known before its dependents run.
+type FactRule interface {
+	Rule
+
+	// FactVersion changes whenever the encoding of the rule's facts does, so
+	// facts cached by an older build are not misread.
+	FactVersion() string
+}
+
+// PackageFacts maps a fact key to its JSON encoding.
+type PackageFacts map[string]json.RawMessage
+
+// Facts is the view a rule has of the fact store while one package is
+// analyzed. A nil Facts exports nothing and imports nothing.
+type Facts struct {
+	Package string // Import path of the package under analysis
+
+	imported func(pkg string) PackageFacts
+
+	mu       sync.Mutex
+	exported PackageFacts
+}
+
+func NewFacts(pkg string, imported func(pkg string) PackageFacts) *Facts {
+	return &Facts{
+		Package:  pkg,
+		imported: imported,
+		exported: make(PackageFacts),
+	}
+}
+
+// Export records fact about object, a package-level name such as "Open" or
+// "File.Close", of the package under analysis.
+func (f *Facts) Export(rule, object string, fact any) {
+	if f == nil {
+		return
+	}
+
+	data, err := json.Marshal(fact)
+	if err != nil {
+		return
+	}
+
+	f.mu.Lock()
+	f.exported[factKey(rule, object)] = data
+	f.mu.Unlock()
+}
+
+// Import decodes into fact what rule exported about object in pkg, which may
+// be the package under analysis itself.
+func (f *Facts) Import(pkg, rule, object string, fact any) bool {
+	if f == nil {
+		return false
+	}
+
+	var data json.RawMessage
+
+	if pkg == f.Package {
+		f.mu.Lock()
+		data = f.exported[factKey(rule, object)]
+		f.mu.Unlock()
+	} else if f.imported != nil {
+		data = f.imported(pkg)[factKey(rule, object)]
+	}
+
+	if data == nil {
+		return false
+	}
+
+	return json.Unmarshal(data, fact) == nil
+}
+
+// Objects returns the objects of pkg that rule exported facts about.
+func (f *Facts) Objects(pkg, rule string) []string {
+	if f == nil || f.imported == nil || pkg == f.Package {
+		return nil
+	}
+
+	prefix := factKey(rule, "")
+
+	var objects []string
+	for key := range f.imported(pkg) {
+		if object, ok := strings.CutPrefix(key, prefix); ok {
+			objects = append(objects, object)
+		}
+	}
+
+	sort.Strings(objects)
+
+	return objects
+}
+
+// Load replaces the exported facts with ones saved by an earlier run.
+func (f *Facts) Load(facts PackageFacts) {
+	if f == nil || facts == nil {
+		return
+	}
+
+	f.mu.Lock()
+	f.exported = facts
+	f.mu.Unlock()
+}
+
+// Exported returns the facts recorded for the package under analysis.
+func (f *Facts) Exported() PackageFacts {
+	if f == nil {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.exported
+}
+
+// ImportPath resolves the name a file uses for an imported package, as in
+// the X of a selector X.Sel, to its import path.
+func ImportPath(file *ast.File, name string) (string, bool) {
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		local := defaultImportName(path)
+		if spec.Name != nil {
+			local = spec.Name.Name
+		}
+
+		if local == name {
+			return path, true
+		}
+	}
+
+	return "", false
+}
+
+func defaultImportName(path string) string {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == '/' {
+			return path[i+1:]
+		}
+	}
+
+	return path
+}
+
+func factKey(rule, object string) string {
+	return rule + "\x00" + object
+}
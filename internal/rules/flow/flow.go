@@ -0,0 +1,455 @@
+// Package flow builds the control-flow graph of a function body and answers
+// reaching-definitions and liveness questions on it.
+//
+// Rules run without type information, so variables are identified by name: a
+// declaration shadowing an outer variable in a nested scope is treated as the
+// same variable. Answers are conservative in that direction.
+package flow
+
+import (
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// Def is one definition of a variable.
+type Def struct {
+	Name string
+
+	// Node is the *ast.AssignStmt, *ast.ValueSpec, *ast.IncDecStmt,
+	// *ast.RangeStmt or, for parameters and named results, *ast.Field that
+	// defines the variable.
+	Node ast.Node
+
+	// Value is the expression assigned, if any. When one call yields every
+	// value of a multi-name assignment, Tuple is set and Index is the
+	// position of Name among the results.
+	Value ast.Expr
+	Tuple bool
+	Index int
+}
+
+type Func struct {
+	CFG *cfg.CFG
+
+	ftype *ast.FuncType
+	nodes map[ast.Node]location
+	skip  map[ast.Expr]bool // range keys and values, defined in the loop body
+	steps [][]step          // per block, the effect of each node in order
+	defs  []*Def
+	names map[string]int
+
+	reachIn []bitset // per block, definitions reaching its entry
+	liveOut []bitset // per block, variables live at its exit
+}
+
+type location struct {
+	block int
+	index int
+}
+
+// step is the effect of one CFG node: the variables it reads, then the
+// definitions it makes.
+type step struct {
+	uses []int
+	defs []int
+}
+
+// New builds the flow graph of fn, a *ast.FuncDecl or *ast.FuncLit. It
+// returns nil for declarations without a body.
+func New(fn ast.Node) *Func {
+	var body *ast.BlockStmt
+	var ftype *ast.FuncType
+
+	switch n := fn.(type) {
+	case *ast.FuncDecl:
+		body, ftype = n.Body, n.Type
+	case *ast.FuncLit:
+		body, ftype = n.Body, n.Type
+	}
+
+	if body == nil {
+		return nil
+	}
+
+	f := &Func{
+		CFG:   cfg.New(body, mayReturn),
+		ftype: ftype,
+		nodes: make(map[ast.Node]location, 32),
+		names: make(map[string]int, 16),
+	}
+
+	f.collect()
+
+	return f
+}
+
+// Defs returns every definition in the function, parameters first.
+func (f *Func) Defs() []*Def {
+	return f.defs
+}
+
+// Reaching returns the definitions of name that may reach node, a statement
+// or condition of the function. It returns nil when node is not part of the
+// graph, such as a statement inside a nested function literal.
+func (f *Func) Reaching(node ast.Node, name string) []*Def {
+	loc, ok := f.nodes[node]
+	if !ok {
+		return nil
+	}
+
+	v, ok := f.names[name]
+	if !ok {
+		return nil
+	}
+
+	f.solveReaching()
+
+	reach := f.reachIn[loc.block].clone()
+	for _, st := range f.steps[loc.block][:loc.index] {
+		f.applyDefs(reach, st)
+	}
+
+	var out []*Def
+	for i, def := range f.defs {
+		if reach.has(i) && f.names[def.Name] == v {
+			out = append(out, def)
+		}
+	}
+
+	return out
+}
+
+// LiveAfter reports whether the value name holds right after node may be
+// read on some path before it is redefined. Nodes outside the graph are
+// reported live.
+func (f *Func) LiveAfter(node ast.Node, name string) bool {
+	loc, ok := f.nodes[node]
+	if !ok {
+		return true
+	}
+
+	v, ok := f.names[name]
+	if !ok {
+		return false
+	}
+
+	f.solveLiveness()
+
+	live := f.liveOut[loc.block].clone()
+	steps := f.steps[loc.block]
+
+	for i := len(steps) - 1; i > loc.index; i-- {
+		f.applyLiveness(live, steps[i])
+	}
+
+	return live.has(v)
+}
+
+func (f *Func) collect() {
+	f.steps = make([][]step, len(f.CFG.Blocks))
+	f.skip = make(map[ast.Expr]bool)
+
+	for _, block := range f.CFG.Blocks {
+		if s, ok := block.Stmt.(*ast.RangeStmt); ok && block.Kind == cfg.KindRangeBody {
+			f.skip[s.Key] = true
+			f.skip[s.Value] = true
+		}
+	}
+
+	var entry []int
+	if f.ftype != nil {
+		for _, list := range []*ast.FieldList{f.ftype.Params, f.ftype.Results} {
+			if list == nil {
+				continue
+			}
+
+			for _, field := range list.List {
+				for _, name := range field.Names {
+					entry = append(entry, f.addDef(&Def{Name: name.Name, Node: field}))
+				}
+			}
+		}
+	}
+
+	for _, block := range f.CFG.Blocks {
+		steps := make([]step, 0, len(block.Nodes)+1)
+
+		if block.Index == 0 && len(entry) > 0 {
+			steps = append(steps, step{defs: entry})
+		}
+
+		if block.Kind == cfg.KindRangeBody {
+			steps = append(steps, f.rangeStep(block.Stmt.(*ast.RangeStmt)))
+		}
+
+		for _, node := range block.Nodes {
+			f.nodes[node] = location{block: int(block.Index), index: len(steps)}
+			steps = append(steps, f.nodeStep(node))
+		}
+
+		f.steps[block.Index] = steps
+	}
+}
+
+func (f *Func) rangeStep(s *ast.RangeStmt) step {
+	var st step
+
+	if s.Tok != token.DEFINE && s.Tok != token.ASSIGN {
+		return st
+	}
+
+	for _, expr := range []ast.Expr{s.Key, s.Value} {
+		if id, ok := expr.(*ast.Ident); ok && id.Name != "_" {
+			st.defs = append(st.defs, f.addDef(&Def{Name: id.Name, Node: s, Value: s.X}))
+		}
+	}
+
+	return st
+}
+
+func (f *Func) nodeStep(node ast.Node) step {
+	var st step
+
+	switch n := node.(type) {
+	case *ast.AssignStmt:
+		for _, rhs := range n.Rhs {
+			st.uses = f.appendUses(st.uses, rhs)
+		}
+
+		for i, lhs := range n.Lhs {
+			id, ok := lhs.(*ast.Ident)
+			if !ok {
+				st.uses = f.appendUses(st.uses, lhs)
+				continue
+			}
+
+			if id.Name == "_" {
+				continue
+			}
+
+			if n.Tok != token.DEFINE && n.Tok != token.ASSIGN {
+				st.uses = f.appendUses(st.uses, id)
+			}
+
+			def := &Def{Name: id.Name, Node: n}
+			if len(n.Rhs) == len(n.Lhs) {
+				def.Value = n.Rhs[i]
+			} else if len(n.Rhs) == 1 {
+				def.Value, def.Tuple, def.Index = n.Rhs[0], true, i
+			}
+
+			st.defs = append(st.defs, f.addDef(def))
+		}
+	case *ast.ValueSpec:
+		for _, value := range n.Values {
+			st.uses = f.appendUses(st.uses, value)
+		}
+
+		for i, id := range n.Names {
+			if id.Name == "_" {
+				continue
+			}
+
+			def := &Def{Name: id.Name, Node: n}
+			if len(n.Values) == len(n.Names) {
+				def.Value = n.Values[i]
+			} else if len(n.Values) == 1 {
+				def.Value, def.Tuple, def.Index = n.Values[0], true, i
+			}
+
+			st.defs = append(st.defs, f.addDef(def))
+		}
+	case *ast.IncDecStmt:
+		st.uses = f.appendUses(st.uses, n.X)
+
+		if id, ok := n.X.(*ast.Ident); ok {
+			st.defs = append(st.defs, f.addDef(&Def{Name: id.Name, Node: n}))
+		}
+	case ast.Expr:
+		if !f.skip[n] {
+			st.uses = f.appendUses(st.uses, n)
+		}
+	default:
+		st.uses = f.appendUses(st.uses, node)
+	}
+
+	return st
+}
+
+func (f *Func) appendUses(uses []int, node ast.Node) []int {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			uses = f.appendUses(uses, n.X)
+			return false
+		case *ast.Ident:
+			if n.Name != "_" {
+				uses = append(uses, f.variable(n.Name))
+			}
+		}
+
+		return true
+	})
+
+	return uses
+}
+
+func (f *Func) addDef(def *Def) int {
+	f.variable(def.Name)
+	f.defs = append(f.defs, def)
+
+	return len(f.defs) - 1
+}
+
+func (f *Func) variable(name string) int {
+	if v, ok := f.names[name]; ok {
+		return v
+	}
+
+	v := len(f.names)
+	f.names[name] = v
+
+	return v
+}
+
+func (f *Func) applyDefs(reach bitset, st step) {
+	for _, d := range st.defs {
+		v := f.names[f.defs[d].Name]
+
+		for i, def := range f.defs {
+			if f.names[def.Name] == v {
+				reach.clear(i)
+			}
+		}
+	}
+
+	for _, d := range st.defs {
+		reach.set(d)
+	}
+}
+
+func (f *Func) applyLiveness(live bitset, st step) {
+	for _, d := range st.defs {
+		live.clear(f.names[f.defs[d].Name])
+	}
+
+	for _, v := range st.uses {
+		live.set(v)
+	}
+}
+
+func (f *Func) solveReaching() {
+	if f.reachIn != nil {
+		return
+	}
+
+	blocks := f.CFG.Blocks
+	f.reachIn = make([]bitset, len(blocks))
+	out := make([]bitset, len(blocks))
+
+	for i := range blocks {
+		f.reachIn[i] = newBitset(len(f.defs))
+		out[i] = newBitset(len(f.defs))
+	}
+
+	preds := predecessors(f.CFG)
+
+	for changed := true; changed; {
+		changed = false
+
+		for _, block := range blocks {
+			i := block.Index
+
+			in := newBitset(len(f.defs))
+			for _, pred := range preds[i] {
+				in.union(out[pred])
+			}
+			f.reachIn[i] = in
+
+			next := in.clone()
+			for _, st := range f.steps[i] {
+				f.applyDefs(next, st)
+			}
+
+			if !next.equal(out[i]) {
+				out[i] = next
+				changed = true
+			}
+		}
+	}
+}
+
+func (f *Func) solveLiveness() {
+	if f.liveOut != nil {
+		return
+	}
+
+	blocks := f.CFG.Blocks
+	f.liveOut = make([]bitset, len(blocks))
+	in := make([]bitset, len(blocks))
+
+	for i := range blocks {
+		f.liveOut[i] = newBitset(len(f.names))
+		in[i] = newBitset(len(f.names))
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		for i := len(blocks) - 1; i >= 0; i-- {
+			block := blocks[i]
+
+			out := newBitset(len(f.names))
+			for _, succ := range block.Succs {
+				out.union(in[succ.Index])
+			}
+			f.liveOut[i] = out
+
+			next := out.clone()
+			steps := f.steps[i]
+			for j := len(steps) - 1; j >= 0; j-- {
+				f.applyLiveness(next, steps[j])
+			}
+
+			if !next.equal(in[i]) {
+				in[i] = next
+				changed = true
+			}
+		}
+	}
+}
+
+func predecessors(g *cfg.CFG) [][]int32 {
+	preds := make([][]int32, len(g.Blocks))
+
+	for _, block := range g.Blocks {
+		for _, succ := range block.Succs {
+			preds[succ.Index] = append(preds[succ.Index], block.Index)
+		}
+	}
+
+	return preds
+}
+
+// mayReturn reports whether a call can return normally. Only calls that are
+// known to end the program or unwind the stack are treated as not returning.
+func mayReturn(call *ast.CallExpr) bool {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name != "panic"
+	case *ast.SelectorExpr:
+		pkg, ok := fun.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+
+		switch pkg.Name + "." + fun.Sel.Name {
+		case "os.Exit", "log.Fatal", "log.Fatalf", "log.Fatalln", "log.Panic", "log.Panicf", "log.Panicln":
+			return false
+		}
+	}
+
+	return true
+}
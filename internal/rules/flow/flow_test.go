@@ -0,0 +1,142 @@
+package flow
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func parseFunc(t *testing.T, src string) *ast.FuncDecl {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "sample.go", "package sample\n\n"+src, parser.SkipObjectResolution)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	return file.Decls[0].(*ast.FuncDecl)
+}
+
+func findReturn(fn *ast.FuncDecl) *ast.ReturnStmt {
+	var ret *ast.ReturnStmt
+
+	ast.Inspect(fn, func(n ast.Node) bool {
+		if r, ok := n.(*ast.ReturnStmt); ok && ret == nil {
+			ret = r
+		}
+		return ret == nil
+	})
+
+	return ret
+}
+
+func TestReachingDefinitionsMergeBranches(t *testing.T) {
+	t.Parallel()
+
+	fn := parseFunc(t, `func pick(ok bool) (int, error) {
+	n, err := load()
+	if ok {
+		err = nil
+	} else {
+		n = 2
+	}
+	return n, err
+}`)
+
+	f := New(fn)
+	ret := findReturn(fn)
+
+	defs := f.Reaching(ret, "err")
+	if len(defs) != 2 {
+		t.Fatalf("expected both definitions of err to reach the return, got %d", len(defs))
+	}
+
+	if !defs[0].Tuple || defs[0].Index != 1 {
+		t.Fatalf("expected err to be the second result of load(), got %+v", defs[0])
+	}
+
+	if got := len(f.Reaching(ret, "ok")); got != 1 {
+		t.Fatalf("expected the parameter to reach the return, got %d definitions", got)
+	}
+}
+
+func TestReachingDefinitionsKilledOnEveryPath(t *testing.T) {
+	t.Parallel()
+
+	fn := parseFunc(t, `func run() error {
+	err := first()
+	err = second()
+	return err
+}`)
+
+	f := New(fn)
+	defs := f.Reaching(findReturn(fn), "err")
+
+	if len(defs) != 1 {
+		t.Fatalf("expected only the last assignment to reach, got %d", len(defs))
+	}
+
+	call := defs[0].Value.(*ast.CallExpr)
+	if call.Fun.(*ast.Ident).Name != "second" {
+		t.Fatalf("expected second() to reach the return")
+	}
+}
+
+func TestLivenessAcrossLoops(t *testing.T) {
+	t.Parallel()
+
+	fn := parseFunc(t, `func sum(items []int) int {
+	total := 0
+	unused := 1
+	for _, item := range items {
+		total += item
+	}
+	unused = 2
+	return total
+}`)
+
+	f := New(fn)
+	body := fn.Body.List
+
+	if !f.LiveAfter(body[0], "total") {
+		t.Fatal("expected total to be live after its definition")
+	}
+
+	if f.LiveAfter(body[1], "unused") {
+		t.Fatal("expected unused to be dead: it is overwritten before any read")
+	}
+
+	loop := body[2].(*ast.RangeStmt)
+	inc := loop.Body.List[0]
+	if !f.LiveAfter(inc, "total") {
+		t.Fatal("expected total to stay live across the loop back-edge")
+	}
+}
+
+func TestNodesOutsideTheGraph(t *testing.T) {
+	t.Parallel()
+
+	fn := parseFunc(t, `func outer() func() int {
+	return func() int {
+		x := 1
+		return x
+	}
+}`)
+
+	f := New(fn)
+	lit := findReturn(fn).Results[0].(*ast.FuncLit)
+	inner := lit.Body.List[1]
+
+	if f.Reaching(inner, "x") != nil {
+		t.Fatal("expected statements of nested literals to be outside the graph")
+	}
+
+	if got := New(lit).Reaching(inner, "x"); len(got) != 1 {
+		t.Fatalf("expected the literal's own graph to resolve x, got %d", len(got))
+	}
+
+	if New(&ast.FuncDecl{Type: &ast.FuncType{}}) != nil {
+		t.Fatal("expected no graph for a declaration without a body")
+	}
+}
@@ -12,6 +12,24 @@ type DisallowedPackagesRule struct {
 	Packages map[string]struct{}
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:              rules.DisallowedPackagesID,
+		Group:           "imports",
+		Key:             "disallowedPackages",
+		Description:     "Reports imports of packages listed in the rule options.",
+		Message:         "package %q is disallowed by configuration",
+		RunsOnGenerated: true,
+	}, &rules.DisallowedPackagesRule{Severity: "warn"}, func(opts *rules.DisallowedPackagesRule) rules.Rule {
+		packages := make(map[string]struct{}, len(opts.Packages))
+		for _, pkg := range opts.Packages {
+			packages[pkg] = struct{}{}
+		}
+
+		return &DisallowedPackagesRule{Severity: rules.ParseSeverity(opts.Severity), Packages: packages}
+	})
+}
+
 func NewDisallowedPackagesRule(cfg *rules.DisallowedPackagesRule) *DisallowedPackagesRule {
 	rule := &DisallowedPackagesRule{
 		Packages: make(map[string]struct{}),
@@ -11,6 +11,19 @@ type NoDotImportsRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:              rules.NoDotImportsID,
+		Group:           "imports",
+		Key:             "noDotImports",
+		Description:     "Reports dot imports, which hide where identifiers come from.",
+		Message:         "dot import is not allowed",
+		RunsOnGenerated: true,
+	}, &rules.LinterBaseRule{Severity: "warn"}, func(opts *rules.LinterBaseRule) rules.Rule {
+		return &NoDotImportsRule{Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (r *NoDotImportsRule) Name() string {
 	return "no-dot-imports"
 }
@@ -11,6 +11,20 @@ type RedundantImportAliasRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:              rules.RedundantImportAliasID,
+		Group:           "imports",
+		Key:             "redundantImportAlias",
+		Description:     "Reports import aliases that repeat the package name.",
+		Message:         "import alias is redundant",
+		Fixable:         true,
+		RunsOnGenerated: true,
+	}, &rules.LinterBaseRule{Severity: "warn"}, func(opts *rules.LinterBaseRule) rules.Rule {
+		return &RedundantImportAliasRule{Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (r *RedundantImportAliasRule) Name() string {
 	return "redundant-import-alias"
 }
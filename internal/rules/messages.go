@@ -9,52 +9,47 @@ type RuleMetadata struct {
 	Fixable  bool
 }
 
+// registry holds the messages of the issues no rule descriptor covers;
+// Register adds the others from their descriptors.
 var registry = map[uint16]RuleMetadata{
 	// --- ERRORS ---
-	NoErrorShadowingID:  {ID: NoErrorShadowingID, Name: "no-error-shadowing", Template: "identifier %q shadows an existing error variable"},
-	ErrorStringFormatID: {ID: ErrorStringFormatID, Name: "error-string-format", Template: "error message should start with a lowercase letter and should not end with punctuation", Fixable: true},
-	ErrorNotWrappedID:   {ID: ErrorNotWrappedID, Name: "error-not-wrapped", Template: "error should be wrapped before it is returned", Fixable: true},
-
-	// --- IMPORTS ---
-	NoDotImportsID:         {ID: NoDotImportsID, Name: "no-dot-imports", Template: "dot import is not allowed"},
-	DisallowedPackagesID:   {ID: DisallowedPackagesID, Name: "disallowed-packages", Template: "package %q is disallowed by configuration"},
-	RedundantImportAliasID: {ID: RedundantImportAliasID, Name: "redundant-import-alias", Template: "import alias is redundant", Fixable: true},
-
-	// --- BEST PRACTICES ---
-	NoDeferInLoopID:          {ID: NoDeferInLoopID, Name: "no-defer-in-loop", Template: "avoid defer inside loops"},
-	UseContextInFirstParamID: {ID: UseContextInFirstParamID, Name: "context-first-param", Template: "context.Context should be the first parameter"},
-	NoBareReturnsID:          {ID: NoBareReturnsID, Name: "no-bare-returns", Template: "avoid bare returns"},
-	NoMagicNumbersID:         {ID: NoMagicNumbersID, Name: "no-magic-numbers", Template: "extract magic number into a named constant"},
-	UseSliceCapacityID:       {ID: UseSliceCapacityID, Name: "use-slice-capacity", Template: "provide slice capacity when the length is known upfront"},
-	MaxParamsID:              {ID: MaxParamsID, Name: "max-params", Template: "function exceeds the parameter limit"},
-	AvoidEmptyStructsID:      {ID: AvoidEmptyStructsID, Name: "avoid-empty-structs", Template: "empty struct declarations are not allowed"},
-	AlwaysPreferConstID:      {ID: AlwaysPreferConstID, Name: "always-prefer-const", Template: "replace variable with a constant"},
-	GetMustReturnValueID:     {ID: GetMustReturnValueID, Name: "get-must-return-value", Template: `functions whose names start with "Get" should return at least one non-error value`},
+	NoErrorShadowingID: {ID: NoErrorShadowingID, Name: "no-error-shadowing", Template: "identifier %q shadows an existing error variable"},
 
 	// --- CORRECTNESS ---
-	UnusedReceiverID:         {ID: UnusedReceiverID, Name: "unused-receiver", Template: "receiver %q is never used"},
-	UnusedParamsID:           {ID: UnusedParamsID, Name: "unused-params", Template: "parameter %q is never used"},
-	EmptyBlockID:             {ID: EmptyBlockID, Name: "empty-block", Template: "empty block; remove it or add a clarifying comment"},
-	AmbiguousReturnID:        {ID: AmbiguousReturnID, Name: "ambiguous-return", Template: "function returns too many unnamed values of the same type"},
-	BoolLiteralExpressionsID: {ID: BoolLiteralExpressionsID, Name: "boolean-literal-expressions", Template: "simplify boolean literal expressions"},
+	UnusedReceiverID: {ID: UnusedReceiverID, Name: "unused-receiver", Template: "receiver %q is never used"},
+	UnusedParamsID:   {ID: UnusedParamsID, Name: "unused-params", Template: "parameter %q is never used"},
 
 	// --- COMPLEXITY ---
-	MaxFuncLinesID:         {ID: MaxFuncLinesID, Name: "max-func-lines", Template: "function exceeds the line limit"},
-	MaxLineLengthID:        {ID: MaxLineLengthID, Name: "max-line-length", Template: "line has %d characters; limit is %d"},
 	MaxNestingDepthID:      {ID: MaxNestingDepthID, Name: "max-nesting-depth", Template: "nesting depth exceeds the limit of %d"},
 	CyclomaticComplexityID: {ID: CyclomaticComplexityID, Name: "cyclomatic-complexity", Template: "cyclomatic complexity is %d; limit is %d"},
 
-	// --- NAMING ---
-	ReceiverNameID:        {ID: ReceiverNameID, Name: "receiver-name", Template: "receiver name does not follow the configured convention"},
-	ExportedIdentifiersID: {ID: ExportedIdentifiersID, Name: "exported-identifiers", Template: "exported identifier should have a doc comment"},
-	ImportedIdentifiersID: {ID: ImportedIdentifiersID, Name: "imported-identifiers", Template: "import alias does not match the configured naming rule"},
+	// ---- SUPPRESSION ----
+	UnusedSuppressionID:          {ID: UnusedSuppressionID, Name: "unused-suppression", Template: "suppression for rule %q does not match any issue"},
+	MisplacedFileWideIgnoreID:    {ID: MisplacedFileWideIgnoreID, Name: "misplaced-file-wide-ignore", Template: "file-wide suppression for rule %q must appear before the package declaration"},
+	UnbalancedSuppressionRangeID: {ID: UnbalancedSuppressionRangeID, Name: "unbalanced-suppression-range", Template: "suppression range for rule %q has no matching start or end"},
+	ExpiredSuppressionID:         {ID: ExpiredSuppressionID, Name: "expired-suppression", Template: "suppression for rule %q expired on %s"},
+	InvalidSuppressionID:         {ID: InvalidSuppressionID, Name: "invalid-suppression", Template: "suppression for rule %q has until=%s, which is not a YYYY-MM-DD date"},
+	MissingSuppressionReasonID:   {ID: MissingSuppressionReasonID, Name: "missing-suppression-reason", Template: "suppression for rule %q must give a reason"},
+
+	// ---- PLUGINS ----
+	PluginIssueID:        {ID: PluginIssueID, Name: "plugin", Template: "%s"},
+	PatternMatchID:       {ID: PatternMatchID, Name: "pattern", Template: "%s"},
+	AnalyzerDiagnosticID: {ID: AnalyzerDiagnosticID, Name: "analyzer", Template: "%s"},
+}
 
-	// ---- STYLE ---
-	PreferIncDecID: {ID: PreferIncDecID, Name: "prefer-inc-dec", Template: "use ++ or -- instead of += 1 or -= 1"},
+var ruleNames = func() map[string]uint16 {
+	names := make(map[string]uint16, len(registry))
 
-	// ---- SUPPRESSION ----
-	UnusedSuppressionID:       {ID: UnusedSuppressionID, Name: "unused-suppression", Template: "suppression for rule %q does not match any issue"},
-	MisplacedFileWideIgnoreID: {ID: MisplacedFileWideIgnoreID, Name: "misplaced-file-wide-ignore", Template: "file-wide suppression for rule %q must appear before the package declaration"},
+	for id, meta := range registry {
+		names[meta.Name] = id
+	}
+
+	return names
+}()
+
+func IsRuleName(name string) bool {
+	_, ok := ruleNames[name]
+	return ok
 }
 
 func GetMetadata(id uint16) (RuleMetadata, bool) {
@@ -62,6 +57,19 @@ func GetMetadata(id uint16) (RuleMetadata, bool) {
 	return m, ok
 }
 
+// IssueRuleName returns the name of the rule that reported issue. Plugin,
+// pattern and analyzer issues share one ID each and carry their rule name
+// alongside the message.
+func IssueRuleName(issue Issue) string {
+	switch issue.ID {
+	case PluginIssueID, PatternMatchID, AnalyzerDiagnosticID:
+		name, _ := SplitContext2(issue.ArgStr1)
+		return name
+	}
+
+	return GetRuleName(issue.ID)
+}
+
 func IsFixable(id uint16) bool {
 	meta, ok := GetMetadata(id)
 	return ok && meta.Fixable
@@ -158,11 +166,51 @@ func FormatMessage(issue Issue) string {
 	case BoolLiteralExpressionsID:
 		return withFunction(issue.ArgStr1, "simplify boolean literal expressions")
 
+	case UnusedSuppressionID:
+		if issue.ArgStr1 == AllRules {
+			return "suppression for all rules does not match any issue"
+		}
+
+		return fmt.Sprintf(meta.Template, issue.ArgStr1)
+
+	case ExpiredSuppressionID:
+		name, until := SplitContext2(issue.ArgStr1)
+		if name == AllRules {
+			return fmt.Sprintf("suppression for all rules expired on %s", until)
+		}
+
+		return fmt.Sprintf(meta.Template, name, until)
+
+	case InvalidSuppressionID:
+		name, until := SplitContext2(issue.ArgStr1)
+		if name == AllRules {
+			return fmt.Sprintf("suppression for all rules has until=%s, which is not a YYYY-MM-DD date", until)
+		}
+
+		return fmt.Sprintf(meta.Template, name, until)
+
+	case MissingSuppressionReasonID:
+		if issue.ArgStr1 == AllRules {
+			return "suppression for all rules must give a reason"
+		}
+
+		return fmt.Sprintf(meta.Template, issue.ArgStr1)
+
+	case PluginIssueID, PatternMatchID, AnalyzerDiagnosticID:
+		_, msg := SplitContext2(issue.ArgStr1)
+		return msg
+
+	case UnbalancedSuppressionRangeID:
+		if issue.ArgStr1 == "" {
+			return "suppression range end has no matching start"
+		}
+
+		return fmt.Sprintf(meta.Template, issue.ArgStr1)
+
 	case NoErrorShadowingID,
 		UnusedReceiverID,
 		UnusedParamsID,
 		DisallowedPackagesID,
-		UnusedSuppressionID,
 		MisplacedFileWideIgnoreID:
 		return fmt.Sprintf(meta.Template, issue.ArgStr1)
 
@@ -0,0 +1,38 @@
+package rules
+
+import "go/version"
+
+// Module is the Go module a package belongs to, as declared by its go.mod.
+type Module struct {
+	Path      string
+	Dir       string
+	GoVersion string // The go directive, e.g. "1.22"; empty when not declared
+}
+
+// VersionedRule is implemented by rules that suggest language features or
+// APIs only available from some Go release on. They are skipped in modules
+// declaring an older go version.
+type VersionedRule interface {
+	Rule
+
+	// MinGoVersion is the oldest release the rule applies to, e.g. "1.21".
+	MinGoVersion() string
+}
+
+// AtLeast reports whether the module may use features of Go release v. A
+// module with an unknown version is assumed to use the latest release.
+func (m *Module) AtLeast(v string) bool {
+	if m == nil || m.GoVersion == "" || v == "" {
+		return true
+	}
+
+	return version.Compare(goVersion(m.GoVersion), goVersion(v)) >= 0
+}
+
+func goVersion(v string) string {
+	if len(v) >= 2 && v[:2] == "go" {
+		return v
+	}
+
+	return "go" + v
+}
@@ -13,6 +13,18 @@ type ExportedIdentifiersRule struct {
 	Re       *regexp.Regexp
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:          rules.ExportedIdentifiersID,
+		Group:       "naming",
+		Key:         "exportedIdentifiers",
+		Description: "Reports exported identifiers without a doc comment, unless they match the pattern.",
+		Message:     "exported identifier should have a doc comment",
+	}, &rules.AnyPatternBasedRule{Severity: "warn"}, func(opts *rules.AnyPatternBasedRule) rules.Rule {
+		return NewExportedIdentifiersRule(opts)
+	})
+}
+
 func NewExportedIdentifiersRule(cfg *rules.AnyPatternBasedRule) *ExportedIdentifiersRule {
 	rule := &ExportedIdentifiersRule{}
 
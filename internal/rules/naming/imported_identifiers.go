@@ -13,6 +13,18 @@ type ImportedIdentifiersRule struct {
 	Re       *regexp.Regexp
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:          rules.ImportedIdentifiersID,
+		Group:       "naming",
+		Key:         "importedIdentifiers",
+		Description: "Reports import aliases that do not match the configured pattern.",
+		Message:     "import alias does not match the configured naming rule",
+	}, &rules.AnyPatternBasedRule{Severity: "warn"}, func(opts *rules.AnyPatternBasedRule) rules.Rule {
+		return NewImportedIdentifiersRule(opts)
+	})
+}
+
 func NewImportedIdentifiersRule(cfg *rules.AnyPatternBasedRule) *ImportedIdentifiersRule {
 	rule := &ImportedIdentifiersRule{}
 
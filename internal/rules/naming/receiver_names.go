@@ -4,6 +4,7 @@ import (
 	"go/ast"
 
 	"github.com/serenitysz/serenity/internal/rules"
+	"github.com/serenitysz/serenity/internal/utils"
 )
 
 type ReceiverNamesRule struct {
@@ -11,8 +12,20 @@ type ReceiverNamesRule struct {
 	MaxSize  int
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:          rules.ReceiverNameID,
+		Group:       "naming",
+		Key:         "receiverNames",
+		Description: "Reports method receiver names longer than the configured size.",
+		Message:     "receiver name does not follow the configured convention",
+	}, &rules.ReceiverNamesRule{Severity: "warn", MaxSize: utils.Ptr(1)}, func(opts *rules.ReceiverNamesRule) rules.Rule {
+		return &ReceiverNamesRule{Severity: rules.ParseSeverity(opts.Severity), MaxSize: *opts.MaxSize}
+	})
+}
+
 func (r *ReceiverNamesRule) Name() string {
-	return "receiver-names"
+	return "receiver-name"
 }
 
 func (r *ReceiverNamesRule) Targets() []ast.Node {
@@ -0,0 +1,133 @@
+package rules
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// SeverityOff turns a rule off, typically for the files of an override.
+const SeverityOff = "off"
+
+// OverrideOptions changes the rules for the files matching any of Files. The
+// rules are merged over the config's: a group set here is enabled, and a rule
+// set here replaces the options it sets.
+type OverrideOptions struct {
+	Files []string         `json:"files" yaml:"files" toml:"files"`
+	Rules LinterRulesGroup `json:"rules" yaml:"rules" toml:"rules"`
+
+	// set holds the rules as decoded from the config file, so an option set
+	// to false or empty is told apart from one left out.
+	set map[string]any
+}
+
+type plainOverrideOptions OverrideOptions
+
+func (o *OverrideOptions) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*plainOverrideOptions)(o)); err != nil {
+		return err
+	}
+
+	var raw struct {
+		Rules map[string]any `json:"rules"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	o.set = raw.Rules
+
+	return nil
+}
+
+// MarshalJSON writes the rules as the config file set them, so encoding and
+// decoding an override keeps what it sets to false.
+func (o OverrideOptions) MarshalJSON() ([]byte, error) {
+	if o.set == nil {
+		return json.Marshal(plainOverrideOptions(o))
+	}
+
+	return json.Marshal(struct {
+		Files []string       `json:"files"`
+		Rules map[string]any `json:"rules"`
+	}{o.Files, o.set})
+}
+
+// Set returns the rule options the override sets by key, as written in the
+// config file. It is nil for an override not decoded from one.
+func (o OverrideOptions) Set() map[string]any {
+	return o.set
+}
+
+// MergeRules returns base with every option set in override applied over it.
+// Neither argument is modified. For an override decoded from a config file,
+// the options it has are applied even when false or empty; otherwise zero
+// values leave base unchanged.
+func MergeRules(base LinterRulesGroup, override OverrideOptions) LinterRulesGroup {
+	merged := base
+	dst := reflect.ValueOf(&merged).Elem()
+
+	if override.set != nil {
+		mergeSet(dst, reflect.ValueOf(override.Rules), override.set)
+	} else {
+		mergeOver(dst, reflect.ValueOf(override.Rules))
+	}
+
+	return merged
+}
+
+func mergeOver(dst, src reflect.Value) {
+	for i := range dst.NumField() {
+		s := src.Field(i)
+		if s.IsZero() {
+			continue
+		}
+
+		d := dst.Field(i)
+
+		if s.Kind() == reflect.Pointer && s.Elem().Kind() == reflect.Struct && !d.IsNil() {
+			copied := reflect.New(d.Type().Elem())
+			copied.Elem().Set(d.Elem())
+			mergeOver(copied.Elem(), s.Elem())
+			d.Set(copied)
+			continue
+		}
+
+		d.Set(s)
+	}
+}
+
+// mergeSet applies the fields of src whose key is in set over dst.
+func mergeSet(dst, src reflect.Value, set map[string]any) {
+	fields := dst.Type()
+
+	for i := range dst.NumField() {
+		value, ok := set[jsonKey(fields.Field(i))]
+		if !ok {
+			continue
+		}
+
+		s, d := src.Field(i), dst.Field(i)
+		nested, isMap := value.(map[string]any)
+
+		if isMap && s.Kind() == reflect.Pointer && !s.IsNil() && s.Elem().Kind() == reflect.Struct && !d.IsNil() {
+			copied := reflect.New(d.Type().Elem())
+			copied.Elem().Set(d.Elem())
+			mergeSet(copied.Elem(), s.Elem(), nested)
+			d.Set(copied)
+			continue
+		}
+
+		d.Set(s)
+	}
+}
+
+func jsonKey(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
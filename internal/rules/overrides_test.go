@@ -0,0 +1,42 @@
+package rules
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMergeRulesAppliesFalseAndEmptyOptionsSet(t *testing.T) {
+	var cfg LinterOptions
+
+	data := `{
+		"linter": {"rules": {"complexity": {"use": true, "maxFuncLines": {"severity": "warn", "max": 20}}}},
+		"overrides": [{"files": ["sub/**"], "rules": {"complexity": {"use": false}}}]
+	}`
+
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	merged := MergeRules(cfg.Linter.Rules, cfg.Overrides[0])
+
+	if merged.Complexity.Use {
+		t.Fatal("expected the override to turn the group off")
+	}
+
+	if merged.Complexity.MaxFuncLines == nil || merged.Complexity.MaxFuncLines.Severity != "warn" {
+		t.Fatalf("expected the options left out to be kept, got %+v", merged.Complexity.MaxFuncLines)
+	}
+
+	if !cfg.Linter.Rules.Complexity.Use {
+		t.Fatal("expected the base rules to be left unchanged")
+	}
+
+	encoded, err := json.Marshal(cfg.Overrides[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(encoded); got != `{"files":["sub/**"],"rules":{"complexity":{"use":false}}}` {
+		t.Fatalf("expected the override to encode as written, got %s", got)
+	}
+}
@@ -0,0 +1,237 @@
+package patterns
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const (
+	metavarPrefix = "__serenity_mv_"
+	wildcard      = "_"
+)
+
+var (
+	metavarRegex = regexp.MustCompile(`\$(\w+)`)
+
+	posType      = reflect.TypeOf(token.NoPos)
+	callExprType = reflect.TypeOf(ast.CallExpr{})
+	objectType   = reflect.TypeOf((*ast.Object)(nil))
+	scopeType    = reflect.TypeOf((*ast.Scope)(nil))
+	commentType  = reflect.TypeOf((*ast.CommentGroup)(nil))
+)
+
+// encodeMetavars rewrites $name into an identifier so the pattern parses as
+// ordinary Go.
+func encodeMetavars(src string) string {
+	return metavarRegex.ReplaceAllString(src, metavarPrefix+"$1")
+}
+
+func metavarName(node ast.Node) (string, bool) {
+	ident, ok := node.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+
+	return strings.CutPrefix(ident.Name, metavarPrefix)
+}
+
+type bindings map[string]ast.Expr
+
+// match compares a pattern against a node structurally, ignoring positions,
+// comments and resolver data. Metavariables match any expression, and a
+// metavariable used twice must match equal expressions both times.
+func match(pattern, node ast.Node, binds bindings) bool {
+	if name, ok := metavarName(pattern); ok {
+		expr, ok := node.(ast.Expr)
+		if !ok {
+			return false
+		}
+
+		if name == wildcard {
+			return true
+		}
+
+		if bound, ok := binds[name]; ok {
+			return match(bound, expr, nil)
+		}
+
+		if binds != nil {
+			binds[name] = expr
+		}
+
+		return true
+	}
+
+	p, n := reflect.ValueOf(pattern), reflect.ValueOf(node)
+
+	if !p.IsValid() || !n.IsValid() {
+		return p.IsValid() == n.IsValid()
+	}
+
+	if p.Type() != n.Type() {
+		return false
+	}
+
+	if p.IsNil() || n.IsNil() {
+		return p.IsNil() == n.IsNil()
+	}
+
+	return matchStruct(p.Elem(), n.Elem(), binds)
+}
+
+func matchStruct(p, n reflect.Value, binds bindings) bool {
+	// Positions are ignored, but the one of a call's ellipsis is what tells
+	// f(xs...) from f(xs).
+	if p.Type() == callExprType {
+		pe, ne := p.FieldByName("Ellipsis").Interface().(token.Pos), n.FieldByName("Ellipsis").Interface().(token.Pos)
+		if pe.IsValid() != ne.IsValid() {
+			return false
+		}
+	}
+
+	for i := range p.NumField() {
+		if !matchValue(p.Field(i), n.Field(i), binds) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchValue(p, n reflect.Value, binds bindings) bool {
+	switch p.Type() {
+	case posType, objectType, scopeType, commentType:
+		return true
+	}
+
+	if !p.CanInterface() {
+		return true
+	}
+
+	switch p.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if p.IsNil() || n.IsNil() {
+			return p.IsNil() == n.IsNil()
+		}
+
+		pn, pok := p.Interface().(ast.Node)
+		nn, nok := n.Interface().(ast.Node)
+		if pok && nok {
+			return match(pn, nn, binds)
+		}
+
+		if p.Kind() == reflect.Pointer {
+			return matchStruct(p.Elem(), n.Elem(), binds)
+		}
+
+		return false
+
+	case reflect.Slice:
+		if p.Len() != n.Len() {
+			return false
+		}
+
+		for i := range p.Len() {
+			if !matchValue(p.Index(i), n.Index(i), binds) {
+				return false
+			}
+		}
+
+		return true
+
+	case reflect.Struct:
+		return matchStruct(p, n, binds)
+
+	default:
+		return p.Interface() == n.Interface()
+	}
+}
+
+// instantiate copies a template, replacing metavariables with their bound
+// expressions and moving every position to pos so the printer keeps the
+// result on the line of the code it replaces.
+func instantiate(template ast.Node, binds bindings, pos token.Pos) ast.Node {
+	if name, ok := metavarName(template); ok {
+		if bound, ok := binds[name]; ok {
+			return bound
+		}
+	}
+
+	v := reflect.ValueOf(template)
+	if !v.IsValid() || v.IsNil() {
+		return template
+	}
+
+	out := reflect.New(v.Elem().Type())
+	copyStruct(out.Elem(), v.Elem(), binds, pos)
+
+	return out.Interface().(ast.Node)
+}
+
+func copyStruct(dst, src reflect.Value, binds bindings, pos token.Pos) {
+	for i := range src.NumField() {
+		if dst.Field(i).CanSet() {
+			dst.Field(i).Set(copyValue(src.Field(i), binds, pos))
+		}
+	}
+}
+
+func copyValue(v reflect.Value, binds bindings, pos token.Pos) reflect.Value {
+	switch v.Type() {
+	case posType:
+		if token.Pos(v.Int()).IsValid() {
+			return reflect.ValueOf(pos)
+		}
+
+		return v
+	case objectType, scopeType, commentType:
+		return reflect.Zero(v.Type())
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+
+		if node, ok := v.Interface().(ast.Node); ok {
+			out := reflect.ValueOf(instantiate(node, binds, pos))
+			if out.Type().AssignableTo(v.Type()) {
+				return out
+			}
+
+			return v
+		}
+
+		if v.Kind() == reflect.Pointer {
+			out := reflect.New(v.Elem().Type())
+			copyStruct(out.Elem(), v.Elem(), binds, pos)
+			return out
+		}
+
+		return v
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(copyValue(v.Index(i), binds, pos))
+		}
+
+		return out
+
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		copyStruct(out, v, binds, pos)
+		return out
+
+	default:
+		return v
+	}
+}
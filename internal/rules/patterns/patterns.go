@@ -0,0 +1,232 @@
+package patterns
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/types"
+	"go/version"
+	"path/filepath"
+	"strings"
+
+	"github.com/serenitysz/serenity/internal/rules"
+	"github.com/serenitysz/serenity/internal/utils"
+)
+
+// Contexts accepted by notInside.
+const (
+	InsideDefer   = "defer"
+	InsideGo      = "go"
+	InsideLoop    = "loop"
+	InsideClosure = "closure"
+)
+
+type PatternRule struct {
+	Severity rules.Severity
+
+	name        string
+	message     string
+	pattern     ast.Expr
+	replacement ast.Expr
+	notInside   []string
+	files       []string
+	minGo       string
+}
+
+func Compile(opts rules.PatternRuleOptions) (*PatternRule, error) {
+	if strings.TrimSpace(opts.Pattern) == "" {
+		return nil, fmt.Errorf("pattern is empty")
+	}
+
+	pattern, err := parser.ParseExpr(encodeMetavars(opts.Pattern))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse pattern %q: %w", opts.Pattern, err)
+	}
+
+	var replacement ast.Expr
+	if opts.Replacement != "" {
+		replacement, err = parser.ParseExpr(encodeMetavars(opts.Replacement))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse replacement %q: %w", opts.Replacement, err)
+		}
+	}
+
+	for _, ctx := range opts.NotInside {
+		switch ctx {
+		case InsideDefer, InsideGo, InsideLoop, InsideClosure:
+		default:
+			return nil, fmt.Errorf("unknown notInside context %q", ctx)
+		}
+	}
+
+	for _, glob := range opts.Files {
+		if !utils.ValidGlob(glob) {
+			return nil, fmt.Errorf("invalid files glob %q", glob)
+		}
+	}
+
+	if opts.MinGoVersion != "" && !version.IsValid("go"+strings.TrimPrefix(opts.MinGoVersion, "go")) {
+		return nil, fmt.Errorf("invalid minGoVersion %q", opts.MinGoVersion)
+	}
+
+	// The name is what suppressions refer to the rule by.
+	name := opts.Name
+	if name == "" {
+		return nil, fmt.Errorf("pattern %q has no name", opts.Pattern)
+	}
+
+	message := opts.Message
+	switch {
+	case message != "":
+	case opts.Replacement != "":
+		message = "replace with " + opts.Replacement
+	default:
+		message = "code matches the " + name + " pattern"
+	}
+
+	return &PatternRule{
+		Severity:    rules.ParseSeverity(opts.Severity),
+		name:        name,
+		message:     message,
+		pattern:     pattern,
+		replacement: replacement,
+		notInside:   opts.NotInside,
+		files:       opts.Files,
+		minGo:       opts.MinGoVersion,
+	}, nil
+}
+
+func (r *PatternRule) Name() string {
+	return r.name
+}
+
+func (r *PatternRule) Targets() []ast.Node {
+	return []ast.Node{r.pattern}
+}
+
+func (r *PatternRule) MinGoVersion() string {
+	return r.minGo
+}
+
+func (r *PatternRule) HasReplacement() bool {
+	return r.replacement != nil
+}
+
+func (r *PatternRule) Run(runner *rules.Runner, node ast.Node) {
+	if runner.ShouldStop != nil && runner.ShouldStop() {
+		return
+	}
+
+	if runner.ReachedMax() {
+		return
+	}
+
+	expr, ok := node.(ast.Expr)
+	if !ok {
+		return
+	}
+
+	binds := make(bindings, 2)
+	if !match(r.pattern, expr, binds) {
+		return
+	}
+
+	if !r.matchesFile(runner) || r.insideExcluded(runner.Ancestors) {
+		return
+	}
+
+	issue := rules.Issue{
+		ArgStr1:  rules.PackContext2(r.name, expandMessage(r.message, binds)),
+		ID:       rules.PatternMatchID,
+		Severity: r.Severity,
+	}
+
+	if r.replacement == nil {
+		runner.Report(expr.Pos(), issue)
+		return
+	}
+
+	if runner.ShouldAutofix() {
+		replacement := instantiate(r.replacement, binds, expr.Pos())
+
+		if rules.ReplaceNode(runner.Parent, expr, replacement) {
+			runner.Modified = true
+			runner.ReportFixed(expr.Pos(), issue)
+			return
+		}
+	}
+
+	runner.ReportFixable(expr.Pos(), issue)
+}
+
+// matchesFile reports whether the file of runner is one the rule applies to.
+// Globs are matched like those of overrides, against the path relative to
+// the config's directory.
+func (r *PatternRule) matchesFile(runner *rules.Runner) bool {
+	if len(r.files) == 0 {
+		return true
+	}
+
+	name := runner.RelPath
+	if name == "" {
+		file := runner.Fset.File(runner.File.Pos())
+		if file == nil {
+			return false
+		}
+
+		name = filepath.ToSlash(file.Name())
+	}
+
+	for _, glob := range r.files {
+		if utils.MatchGlob(glob, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *PatternRule) insideExcluded(ancestors []ast.Node) bool {
+	if len(r.notInside) == 0 {
+		return false
+	}
+
+	for _, ancestor := range ancestors {
+		for _, ctx := range r.notInside {
+			if insideContext(ancestor, ctx) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func insideContext(node ast.Node, ctx string) bool {
+	switch node.(type) {
+	case *ast.DeferStmt:
+		return ctx == InsideDefer
+	case *ast.GoStmt:
+		return ctx == InsideGo
+	case *ast.ForStmt, *ast.RangeStmt:
+		return ctx == InsideLoop
+	case *ast.FuncLit:
+		return ctx == InsideClosure
+	}
+
+	return false
+}
+
+func expandMessage(message string, binds bindings) string {
+	if !strings.Contains(message, "$") {
+		return message
+	}
+
+	return metavarRegex.ReplaceAllStringFunc(message, func(token string) string {
+		if bound, ok := binds[token[1:]]; ok {
+			return types.ExprString(bound)
+		}
+
+		return token
+	})
+}
@@ -1,7 +1,193 @@
 package rules
 
-var GlobalRegistry []Rule
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
 
-func Register(rule Rule) {
-	GlobalRegistry = append(GlobalRegistry, rule)
+// Descriptor describes a built-in rule: where it lives in the config, what
+// options it takes and how to build it from them.
+type Descriptor struct {
+	ID          uint16
+	Name        string // Filled in by Register from the rule's Name
+	Group       string // Config key of the rule group, e.g. "bestPractices"
+	Key         string // Config key of the rule inside its group, e.g. "maxParams"
+	Description string
+	Message     string // Default text of the rule's issues, also its format template
+
+	Fixable            bool // If true, the rule applies safe fixes with --write
+	UnsafeFix          bool // If true, the rule only fixes with --unsafe
+	NeedsConstAnalysis bool // If true, the rule relies on the package-wide const analysis
+	RunsOnGenerated    bool // If true, the rule still runs on generated files in lint-light mode
+
+	// MinGoVersion skips the rule in modules declaring an older go version.
+	MinGoVersion string
+
+	// Defaults holds the options used for any field left unset in the config.
+	// Its type is the rule's config type.
+	Defaults any
+
+	build func(opts any) Rule
+}
+
+var registered = make(map[uint16]*Descriptor, 32)
+
+// Register adds a built-in rule and its message. It is meant to be called
+// from the init function of the file that implements the rule. The rule's ID
+// and options type are not derived: a new rule still needs an ID constant in
+// rules.go and a field of type *T in its group's config struct, which is what
+// the config decoder and the schema read. Register panics when either is
+// missing or they disagree, so a rule cannot be half added.
+func Register[T any](desc Descriptor, defaults *T, build func(opts *T) Rule) {
+	desc.Name = build(defaults).Name()
+
+	if _, ok := registered[desc.ID]; ok {
+		panic(fmt.Sprintf("rules: rule %q registered twice", desc.Name))
+	}
+
+	if meta, ok := registry[desc.ID]; ok {
+		panic(fmt.Sprintf("rules: rule %q shares its ID with %q", desc.Name, meta.Name))
+	}
+
+	if desc.Message == "" {
+		panic(fmt.Sprintf("rules: rule %q has no message", desc.Name))
+	}
+
+	field, ok := configField(desc.Group, desc.Key)
+	if !ok || field.Type != reflect.TypeFor[*T]() {
+		panic(fmt.Sprintf("rules: rule %q has no %s.%s option of type %T", desc.Name, desc.Group, desc.Key, defaults))
+	}
+
+	desc.Defaults = defaults
+	desc.build = func(opts any) Rule {
+		return build(opts.(*T))
+	}
+
+	registered[desc.ID] = &desc
+	registry[desc.ID] = RuleMetadata{ID: desc.ID, Name: desc.Name, Template: desc.Message, Fixable: desc.Fixable}
+	ruleNames[desc.Name] = desc.ID
+}
+
+// Descriptors returns every registered rule ordered by ID.
+func Descriptors() []*Descriptor {
+	out := make([]*Descriptor, 0, len(registered))
+
+	for _, desc := range registered {
+		out = append(out, desc)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].ID < out[j].ID
+	})
+
+	return out
+}
+
+func LookupDescriptor(id uint16) (*Descriptor, bool) {
+	desc, ok := registered[id]
+	return desc, ok
+}
+
+// Options returns the rule's options from cfg merged over its defaults, or
+// nil when the rule or its group is not enabled.
+func (d *Descriptor) Options(cfg *LinterRulesGroup) any {
+	if cfg == nil {
+		return nil
+	}
+
+	group := reflect.ValueOf(cfg).Elem().FieldByIndex(groupIndex(d.Group))
+	if group.IsNil() || !group.Elem().FieldByName("Use").Bool() {
+		return nil
+	}
+
+	rule := group.Elem().FieldByIndex(ruleIndex(group.Type().Elem(), d.Key))
+	if rule.IsNil() {
+		return nil
+	}
+
+	merged := reflect.New(rule.Type().Elem())
+	merged.Elem().Set(rule.Elem())
+	mergeDefaults(merged.Elem(), reflect.ValueOf(d.Defaults).Elem())
+
+	if severity := merged.Elem().FieldByName("Severity"); severity.IsValid() && severity.String() == SeverityOff {
+		return nil
+	}
+
+	return merged.Interface()
+}
+
+// Build returns the rule configured by cfg, or nil when it is not enabled.
+func (d *Descriptor) Build(cfg *LinterRulesGroup) Rule {
+	opts := d.Options(cfg)
+	if opts == nil {
+		return nil
+	}
+
+	return d.build(opts)
+}
+
+// ConfigPath returns the rule's dotted path in the config file.
+func (d *Descriptor) ConfigPath() string {
+	return "linter.rules." + d.Group + "." + d.Key
+}
+
+// mergeDefaults fills the options left unset in dst. Only nil pointers,
+// slices and maps and empty strings count as unset: an explicit 0 or false
+// is a value the config chose, which optional options hold behind a pointer.
+func mergeDefaults(dst, defaults reflect.Value) {
+	for i := range dst.NumField() {
+		field := dst.Field(i)
+
+		switch field.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+			if field.IsNil() {
+				field.Set(defaults.Field(i))
+			}
+		case reflect.String:
+			if field.String() == "" {
+				field.Set(defaults.Field(i))
+			}
+		}
+	}
+}
+
+func configField(group, key string) (reflect.StructField, bool) {
+	groups := reflect.TypeFor[LinterRulesGroup]()
+
+	index := groupIndex(group)
+	if index == nil {
+		return reflect.StructField{}, false
+	}
+
+	groupType := groups.FieldByIndex(index).Type.Elem()
+
+	index = ruleIndex(groupType, key)
+	if index == nil {
+		return reflect.StructField{}, false
+	}
+
+	return groupType.FieldByIndex(index), true
+}
+
+func groupIndex(group string) []int {
+	return fieldIndexByTag(reflect.TypeFor[LinterRulesGroup](), group)
+}
+
+func ruleIndex(groupType reflect.Type, key string) []int {
+	return fieldIndexByTag(groupType, key)
+}
+
+func fieldIndexByTag(t reflect.Type, key string) []int {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		if name == key && field.Type.Kind() == reflect.Pointer {
+			return field.Index
+		}
+	}
+
+	return nil
 }
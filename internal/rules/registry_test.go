@@ -0,0 +1,38 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeDefaultsKeepsExplicitZeros(t *testing.T) {
+	type options struct {
+		Severity string
+		Max      *int
+		Packages []string
+		Strict   bool
+		Limit    int
+	}
+
+	five := 5
+	defaults := options{Severity: "warn", Max: &five, Packages: []string{"unsafe"}, Strict: true, Limit: 3}
+
+	zero := 0
+	got := options{Max: &zero, Packages: []string{}}
+	mergeDefaults(reflect.ValueOf(&got).Elem(), reflect.ValueOf(defaults))
+
+	if got.Severity != "warn" {
+		t.Errorf("Severity = %q, want the default", got.Severity)
+	}
+
+	if *got.Max != 0 || len(got.Packages) != 0 || got.Strict || got.Limit != 0 {
+		t.Errorf("expected the explicit zeros to be kept, got %+v", got)
+	}
+
+	got = options{}
+	mergeDefaults(reflect.ValueOf(&got).Elem(), reflect.ValueOf(defaults))
+
+	if got.Max != &five || !reflect.DeepEqual(got.Packages, defaults.Packages) {
+		t.Errorf("expected unset options to take the defaults, got %+v", got)
+	}
+}
@@ -3,10 +3,14 @@ package rules
 import (
 	"go/ast"
 	"go/token"
+
+	"github.com/serenitysz/serenity/internal/rules/flow"
 )
 
 type Runner struct {
 	File            *ast.File
+	Src             []byte // Contents of File as read, nil when not at hand
+	RelPath         string // Path of File relative to its config's directory, with forward slashes
 	Fset            *token.FileSet
 	Cfg             *LinterOptions
 	Issues          *[]Issue
@@ -20,12 +24,32 @@ type Runner struct {
 	Suppressions    []Suppression
 	CurrentFunc     *FunctionContext
 	Parent          ast.Node
+	Ancestors       []ast.Node
+	Facts           *Facts
+	Module          *Module // nil when the file is outside any module
 	LoopDepth       int
 }
 
 type FunctionContext struct {
 	Name            string
 	HasNamedResults bool
+	Node            ast.Node // The *ast.FuncDecl or *ast.FuncLit being visited
+
+	flow *flow.Func
+}
+
+// Flow returns the control-flow graph of the function being visited, built
+// on first use and shared by every rule. It is nil outside function bodies.
+func (r *Runner) Flow() *flow.Func {
+	if r == nil || r.CurrentFunc == nil || r.CurrentFunc.Node == nil {
+		return nil
+	}
+
+	if r.CurrentFunc.flow == nil {
+		r.CurrentFunc.flow = flow.New(r.CurrentFunc.Node)
+	}
+
+	return r.CurrentFunc.flow
 }
 
 type LinterOptions struct {
@@ -34,8 +58,11 @@ type LinterOptions struct {
 	Git         *GitOptions         `json:"git,omitempty" yaml:"git,omitempty" toml:"git,omitempty"`
 	Schema      string              `json:"$schema" yaml:"$schema,omitempty" toml:"$schema,omitempty"`
 	Extends     *[]string           `json:"extends,omitempty" yaml:"extends,omitempty" toml:"extends,omitempty"`
+	Root        *bool               `json:"root,omitempty" yaml:"root,omitempty" toml:"root,omitempty"`
+	Overrides   []OverrideOptions   `json:"overrides,omitempty" yaml:"overrides,omitempty" toml:"overrides,omitempty"`
 	Assistance  *AssistanceOptions  `json:"assistance,omitempty" yaml:"assistance,omitempty" toml:"assistance,omitempty"`
 	Performance *PerformanceOptions `json:"performance,omitempty" yaml:"performance,omitempty" toml:"performance,omitempty"`
+	Plugins     []PluginOptions     `json:"plugins,omitempty" yaml:"plugins,omitempty" toml:"plugins,omitempty"`
 }
 
 func (l *LinterOptions) GetMaxIssues() uint16 {
@@ -46,6 +73,23 @@ func (l *LinterOptions) GetMaxIssues() uint16 {
 	return 0
 }
 
+func (l *LinterOptions) GeneratedMode() string {
+	if l.File != nil && l.File.Generated != nil && *l.File.Generated != "" {
+		return *l.File.Generated
+	}
+
+	return GeneratedSkip
+}
+
+// BuildTargets returns the configured targets, or the host alone.
+func (l *LinterOptions) BuildTargets() []BuildTarget {
+	if l.File != nil && l.File.Targets != nil && len(*l.File.Targets) > 0 {
+		return *l.File.Targets
+	}
+
+	return []BuildTarget{{}}
+}
+
 func (l *LinterOptions) ShouldAutofix() bool {
 	return l.Assistance != nil &&
 		l.Assistance.Use &&
@@ -165,12 +209,50 @@ type GitOptions struct {
 type GoFileOptions struct {
 	Exclude     *[]string `json:"exclude,omitempty" yaml:"exclude,omitempty" toml:"exclude,omitempty"`
 	MaxFileSize *int64    `json:"maxFileSize,omitempty" yaml:"maxFileSize,omitempty" toml:"maxFileSize,omitempty"`
+	Generated   *string   `json:"generated,omitempty" yaml:"generated,omitempty" toml:"generated,omitempty"`
+
+	// Targets selects files by build constraints. With several targets every
+	// package is analyzed once per distinct set of files and the issues are
+	// merged.
+	Targets *[]BuildTarget `json:"targets,omitempty" yaml:"targets,omitempty" toml:"targets,omitempty"`
+}
+
+// BuildTarget is one GOOS/GOARCH/tags combination, as the go command would
+// see them. Empty fields default to the host.
+type BuildTarget struct {
+	GOOS   string   `json:"goos,omitempty" yaml:"goos,omitempty" toml:"goos,omitempty"`
+	GOARCH string   `json:"goarch,omitempty" yaml:"goarch,omitempty" toml:"goarch,omitempty"`
+	Tags   []string `json:"tags,omitempty" yaml:"tags,omitempty" toml:"tags,omitempty"`
+}
+
+// Modes for files carrying a "Code generated ... DO NOT EDIT." header.
+const (
+	GeneratedSkip      = "skip"
+	GeneratedLint      = "lint"
+	GeneratedLintLight = "lint-light"
+)
+
+// GeneratedModes lists the valid go.generated values.
+var GeneratedModes = []string{GeneratedSkip, GeneratedLint, GeneratedLintLight}
+
+// PluginOptions enables out-of-tree rules. Without a path the rules are the
+// ones compiled into the binary; with a path they come from an executable
+// speaking the stdio protocol of pkg/serenity/rule. An empty rules map enables
+// every rule the plugin provides at warn severity.
+type PluginOptions struct {
+	Name  string                     `json:"name" yaml:"name" toml:"name"`
+	Path  string                     `json:"path,omitempty" yaml:"path,omitempty" toml:"path,omitempty"`
+	Args  []string                   `json:"args,omitempty" yaml:"args,omitempty" toml:"args,omitempty"`
+	Rules map[string]*LinterBaseRule `json:"rules,omitempty" yaml:"rules,omitempty" toml:"rules,omitempty"`
 }
 
 type PerformanceOptions struct {
-	Use     bool  `json:"use,omitempty" yaml:"use,omitempty" toml:"use,omitempty"`
-	Threads *int  `json:"threads,omitempty" yaml:"threads,omitempty" toml:"threads,omitempty"`
-	Caching *bool `json:"caching,omitempty" yaml:"caching,omitempty" toml:"caching,omitempty"`
+	Use          bool   `json:"use,omitempty" yaml:"use,omitempty" toml:"use,omitempty"`
+	Threads      *int   `json:"threads,omitempty" yaml:"threads,omitempty" toml:"threads,omitempty"`
+	Caching      *bool  `json:"caching,omitempty" yaml:"caching,omitempty" toml:"caching,omitempty"`
+	CacheMaxSize *int64 `json:"cacheMaxSize,omitempty" yaml:"cacheMaxSize,omitempty" toml:"cacheMaxSize,omitempty"`
+	CacheDir     string `json:"cacheDir,omitempty" yaml:"cacheDir,omitempty" toml:"cacheDir,omitempty"`
+	RemoteCache  string `json:"remoteCache,omitempty" yaml:"remoteCache,omitempty" toml:"remoteCache,omitempty"`
 }
 
 type AssistanceOptions struct {
@@ -179,9 +261,39 @@ type AssistanceOptions struct {
 }
 
 type LinterRules struct {
-	Use    bool                 `json:"use" yaml:"use" toml:"use"`
-	Rules  LinterRulesGroup     `json:"rules"  yaml:"rules" toml:"rules"`
-	Issues *LinterIssuesOptions `json:"issues,omitempty" yaml:"issues,omitempty" toml:"issues,omitempty"`
+	Use          bool                 `json:"use" yaml:"use" toml:"use"`
+	Rules        LinterRulesGroup     `json:"rules"  yaml:"rules" toml:"rules"`
+	Issues       *LinterIssuesOptions `json:"issues,omitempty" yaml:"issues,omitempty" toml:"issues,omitempty"`
+	Suppressions *SuppressionOptions  `json:"suppressions,omitempty" yaml:"suppressions,omitempty" toml:"suppressions,omitempty"`
+	Patterns     []PatternRuleOptions `json:"patterns,omitempty" yaml:"patterns,omitempty" toml:"patterns,omitempty"`
+
+	// Analyzers enables golang.org/x/tools/go/analysis passes by name.
+	Analyzers map[string]*LinterBaseRule `json:"analyzers,omitempty" yaml:"analyzers,omitempty" toml:"analyzers,omitempty"`
+}
+
+// PatternRuleOptions declares a rule as a Go expression pattern. $name in the
+// pattern matches any expression; the same name used twice must match equal
+// expressions, and $_ matches without binding. Bound names can be reused in
+// the message and replacement.
+type PatternRuleOptions struct {
+	Name        string   `json:"name" yaml:"name" toml:"name"`
+	Pattern     string   `json:"pattern" yaml:"pattern" toml:"pattern"`
+	Message     string   `json:"message,omitempty" yaml:"message,omitempty" toml:"message,omitempty"`
+	Severity    string   `json:"severity" yaml:"severity" toml:"severity"`
+	Replacement string   `json:"replacement,omitempty" yaml:"replacement,omitempty" toml:"replacement,omitempty"`
+	NotInside   []string `json:"notInside,omitempty" yaml:"notInside,omitempty" toml:"notInside,omitempty"`
+	Files       []string `json:"files,omitempty" yaml:"files,omitempty" toml:"files,omitempty"`
+
+	// MinGoVersion limits the rule to modules whose go directive is at least
+	// this release, e.g. "1.21" for a replacement using min or max.
+	MinGoVersion string `json:"minGoVersion,omitempty" yaml:"minGoVersion,omitempty" toml:"minGoVersion,omitempty"`
+}
+
+type SuppressionOptions struct {
+	Use           bool                `json:"use" yaml:"use" toml:"use"`
+	Compat        *bool               `json:"compat,omitempty" yaml:"compat,omitempty" toml:"compat,omitempty"`
+	RequireReason *bool               `json:"requireReason,omitempty" yaml:"requireReason,omitempty" toml:"requireReason,omitempty"`
+	Aliases       map[string][]string `json:"aliases,omitempty" yaml:"aliases,omitempty" toml:"aliases,omitempty"`
 }
 
 type LinterIssuesOptions struct {
@@ -247,7 +359,7 @@ type BestPracticesRulesGroup struct {
 type CorrectnessRulesGroup struct {
 	Use                    bool                  `json:"use" yaml:"use" toml:"use"`
 	UnusedReceiver         *LinterBaseRule       `json:"unusedReceiver,omitempty" yaml:"unusedReceiver,omitempty" toml:"unusedReceiver,omitempty"`
-	UnusedParams           *LinterBaseRule       `json:"ununsedParams,omitempty" yaml:"unusedParams,omitempty" toml:"unusedParams,omitempty"`
+	UnusedParams           *LinterBaseRule       `json:"unusedParams,omitempty" yaml:"unusedParams,omitempty" toml:"unusedParams,omitempty"`
 	EmptyBlock             *LinterBaseRule       `json:"emptyBlock,omitempty" yaml:"emptyBlock,omitempty" toml:"emptyBlock,omitempty"`
 	BoolLiteralExpressions *LinterBaseRule       `json:"boolLiteralExpressions,omitempty" yaml:"boolLiteralExpressions,omitempty" toml:"boolLiteralExpressions,omitempty"`
 	AmbiguousReturns       *AmbiguousReturnsRule `json:"ambiguousReturns,omitempty" yaml:"ambiguousReturns,omitempty" toml:"ambiguousReturns,omitempty"`
@@ -370,4 +482,14 @@ const (
 
 	UnusedSuppressionID
 	MisplacedFileWideIgnoreID
+	UnbalancedSuppressionRangeID
+	ExpiredSuppressionID
+	InvalidSuppressionID
+	MissingSuppressionReasonID
+
+	// PLUGINS
+
+	PluginIssueID
+	PatternMatchID
+	AnalyzerDiagnosticID
 )
@@ -11,6 +11,19 @@ type PreferIncDecRule struct {
 	Severity rules.Severity
 }
 
+func init() {
+	rules.Register(rules.Descriptor{
+		ID:          rules.PreferIncDecID,
+		Group:       "style",
+		Key:         "preferIncDec",
+		Description: "Reports += 1 and -= 1 where ++ or -- reads better.",
+		Message:     "use ++ or -- instead of += 1 or -= 1",
+		Fixable:     true,
+	}, &rules.LinterBaseRule{Severity: "warn"}, func(opts *rules.LinterBaseRule) rules.Rule {
+		return &PreferIncDecRule{Severity: rules.ParseSeverity(opts.Severity)}
+	})
+}
+
 func (r *PreferIncDecRule) Name() string {
 	return "prefer-inc-dec"
 }
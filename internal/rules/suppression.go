@@ -5,85 +5,294 @@ import (
 	"go/token"
 	"regexp"
 	"strings"
+	"time"
 )
 
 type Suppression struct {
-	RuleName    string
-	Reason      string
-	Line        int
-	IsFileWide  bool // If true, applies to all occurrences in the file
-	IsMisplaced bool // If true, file-wide ignore is placed after package declaration
+	RuleName     string
+	Reason       string
+	Line         int
+	EndLine      int    // Last line covered by the suppression
+	Until        string // Date after which the suppression stops applying, as YYYY-MM-DD
+	IsFileWide   bool   // If true, applies to all occurrences in the file
+	IsMisplaced  bool   // If true, file-wide ignore is placed after package declaration
+	IsUnbalanced bool   // If true, a range start or end has no counterpart and suppresses nothing
+	IsExpired    bool   // If true, the until date has passed and the suppression no longer applies
+	IsInvalid    bool   // If true, the until date is malformed and the suppression applies nothing
+	NeedsReason  bool   // If true, a reason is required by config but none was given
 }
 
+// SuppressionParser turns comments into suppressions according to the
+// suppressions config. A nil parser accepts native directives only.
+type SuppressionParser struct {
+	compat        *CompatSuppressions
+	requireReason bool
+	now           time.Time
+}
+
+const suppressionDateLayout = "2006-01-02"
+
 var (
-	inlineRegex   = regexp.MustCompile(`@serenity-ignore\s+([\w-]+)(?::\s*(.+))?`)
-	fileWideRegex = regexp.MustCompile(`@serenity-ignore-all\s+([\w-]+)(?::\s*(.+))?`)
+	inlineRegex     = regexp.MustCompile(`@serenity-ignore\s+([\w-]+(?:\s*,\s*[\w-]+)*)(?:\s*:\s*(.+))?`)
+	fileWideRegex   = regexp.MustCompile(`@serenity-ignore-all\s+([\w-]+(?:\s*,\s*[\w-]+)*)(?:\s*:\s*(.+))?`)
+	rangeStartRegex = regexp.MustCompile(`@serenity-ignore-start\s+([\w-]+(?:\s*,\s*[\w-]+)*)(?:\s*:\s*(.+))?`)
+	rangeEndRegex   = regexp.MustCompile(`@serenity-ignore-end(?:\s+([\w-]+(?:\s*,\s*[\w-]+)*))?`)
+	untilRegex      = regexp.MustCompile(`\s+until=([^\s:]+)`)
+)
+
+type suppressionKind uint8
+
+const (
+	suppressionInline suppressionKind = iota
+	suppressionFileWide
+	suppressionRangeStart
+	suppressionRangeEnd
 )
 
-func ProcessSuppressions(comments []*ast.CommentGroup, fset *token.FileSet, decls []ast.Decl, pkgPos token.Pos) []Suppression {
+func NewSuppressionParser(opts *SuppressionOptions, now time.Time) *SuppressionParser {
+	parser := &SuppressionParser{
+		compat: NewCompatSuppressions(opts),
+		now:    now,
+	}
+
+	if opts != nil && opts.Use && opts.RequireReason != nil {
+		parser.requireReason = *opts.RequireReason
+	}
+
+	return parser
+}
+
+func ProcessSuppressions(comments []*ast.CommentGroup, fset *token.FileSet, decls []ast.Decl, pkgPos token.Pos, parser *SuppressionParser) []Suppression {
 	var suppressions []Suppression
+	var open []Suppression
 
 	pkgLine := fset.Position(pkgPos).Line
+	scopes := funcDocScopes(decls, fset)
 
 	for _, cg := range comments {
 		if cg == nil {
 			continue
 		}
 
+		scopeEnd, inDoc := scopes[cg]
+
 		for _, comment := range cg.List {
 			if comment == nil {
 				continue
 			}
 
 			line := fset.Position(comment.Pos()).Line
+			text, until := splitUntil(comment.Text)
+
+			kind, parsed := parseSuppression(text, line, pkgLine)
+			if parsed == nil {
+				parsed = parser.compatSuppressions().parse(text, line)
+			}
 
-			sup := parseSuppression(comment.Text, line, pkgLine)
-			if sup != nil {
-				suppressions = append(suppressions, *sup)
+			if kind != suppressionRangeEnd {
+				parser.annotate(parsed, until)
+			}
+
+			switch kind {
+			case suppressionRangeStart:
+				open = append(open, parsed...)
+				continue
+			case suppressionRangeEnd:
+				var stray []Suppression
+
+				open, stray = closeRanges(open, parsed, line)
+				suppressions = append(suppressions, stray...)
+				continue
+			}
+
+			for _, sup := range parsed {
+				if inDoc && !sup.IsFileWide && scopeEnd > sup.EndLine {
+					sup.EndLine = scopeEnd
+				}
+
+				suppressions = append(suppressions, sup)
 			}
 		}
 	}
 
+	for _, sup := range open {
+		sup.IsUnbalanced = true
+		suppressions = append(suppressions, sup)
+	}
+
 	return suppressions
 }
 
-func parseSuppression(text string, line int, pkgLine int) *Suppression {
+func (p *SuppressionParser) compatSuppressions() *CompatSuppressions {
+	if p == nil {
+		return nil
+	}
+
+	return p.compat
+}
+
+func (p *SuppressionParser) annotate(sups []Suppression, until string) {
+	now := time.Now()
+	requireReason := false
+
+	if p != nil {
+		now = p.now
+		requireReason = p.requireReason
+	}
+
+	for i := range sups {
+		sups[i].NeedsReason = requireReason && sups[i].Reason == ""
+
+		if until == "" {
+			continue
+		}
+
+		expired, ok := suppressionExpired(until, now)
+
+		sups[i].Until = until
+		sups[i].IsExpired = expired
+		sups[i].IsInvalid = !ok
+	}
+}
+
+// splitUntil removes an until=YYYY-MM-DD attribute from a comment so the
+// remaining text can be parsed as a regular directive.
+func splitUntil(text string) (string, string) {
+	match := untilRegex.FindStringSubmatchIndex(text)
+	if match == nil {
+		return text, ""
+	}
+
+	return text[:match[0]] + text[match[1]:], text[match[2]:match[3]]
+}
+
+// suppressionExpired reports whether now is past the last day covered by
+// until, and whether until is a date at all.
+func suppressionExpired(until string, now time.Time) (bool, bool) {
+	date, err := time.ParseInLocation(suppressionDateLayout, until, now.Location())
+	if err != nil {
+		return false, false
+	}
+
+	return !now.Before(date.AddDate(0, 0, 1)), true
+}
+
+func parseSuppression(text string, line int, pkgLine int) (suppressionKind, []Suppression) {
 	text = strings.TrimSpace(text)
 
 	if match := fileWideRegex.FindStringSubmatch(text); match != nil {
-		misplaced := line >= pkgLine
+		sups := expandRuleNames(match[1], match[2], line, line)
 
-		ruleName := match[1]
-		reason := ""
-		if len(match) > 2 {
-			reason = match[2]
+		for i := range sups {
+			sups[i].IsFileWide = true
+			sups[i].IsMisplaced = line >= pkgLine
 		}
 
-		return &Suppression{
-			RuleName:    ruleName,
-			Reason:      reason,
-			Line:        line,
-			IsFileWide:  true,
-			IsMisplaced: misplaced,
+		return suppressionFileWide, sups
+	}
+
+	if match := rangeStartRegex.FindStringSubmatch(text); match != nil {
+		return suppressionRangeStart, expandRuleNames(match[1], match[2], line, line)
+	}
+
+	if match := rangeEndRegex.FindStringSubmatch(text); match != nil {
+		if match[1] == "" {
+			return suppressionRangeEnd, []Suppression{{Line: line, EndLine: line}}
 		}
+
+		return suppressionRangeEnd, expandRuleNames(match[1], "", line, line)
 	}
 
 	if match := inlineRegex.FindStringSubmatch(text); match != nil {
-		ruleName := match[1]
-		reason := ""
-		if len(match) > 2 {
-			reason = match[2]
+		return suppressionInline, expandRuleNames(match[1], match[2], line, line+1)
+	}
+
+	return suppressionInline, nil
+}
+
+func expandRuleNames(list, reason string, line, endLine int) []Suppression {
+	names := strings.Split(list, ",")
+	sups := make([]Suppression, 0, len(names))
+
+	for _, name := range names {
+		sups = append(sups, Suppression{
+			RuleName: strings.TrimSpace(name),
+			Reason:   strings.TrimSpace(reason),
+			Line:     line,
+			EndLine:  endLine,
+		})
+	}
+
+	return sups
+}
+
+// closeRanges pairs an end marker with the innermost open starts. An end
+// without rule names closes every rule opened by the most recent start.
+func closeRanges(open, ends []Suppression, line int) ([]Suppression, []Suppression) {
+	var closed []Suppression
+	var stray []Suppression
+
+	for _, end := range ends {
+		if end.RuleName == "" {
+			if len(open) == 0 {
+				end.IsUnbalanced = true
+				stray = append(stray, end)
+				continue
+			}
+
+			startLine := open[len(open)-1].Line
+			for len(open) > 0 && open[len(open)-1].Line == startLine {
+				sup := open[len(open)-1]
+				sup.EndLine = line
+				closed = append(closed, sup)
+				open = open[:len(open)-1]
+			}
+
+			continue
 		}
 
-		return &Suppression{
-			RuleName:   ruleName,
-			Reason:     reason,
-			Line:       line,
-			IsFileWide: false,
+		matched := false
+		for i := len(open) - 1; i >= 0; i-- {
+			if open[i].RuleName != end.RuleName {
+				continue
+			}
+
+			sup := open[i]
+			sup.EndLine = line
+			closed = append(closed, sup)
+			open = append(open[:i], open[i+1:]...)
+			matched = true
+			break
+		}
+
+		if !matched {
+			end.IsUnbalanced = true
+			stray = append(stray, end)
 		}
 	}
 
-	return nil
+	return open, append(closed, stray...)
+}
+
+// funcDocScopes maps each function doc comment to the last line of the
+// function it documents, so a suppression written there covers the body.
+func funcDocScopes(decls []ast.Decl, fset *token.FileSet) map[*ast.CommentGroup]int {
+	var scopes map[*ast.CommentGroup]int
+
+	for _, decl := range decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Doc == nil {
+			continue
+		}
+
+		if scopes == nil {
+			scopes = make(map[*ast.CommentGroup]int, 4)
+		}
+
+		scopes[fn.Doc] = fset.Position(fn.End()).Line
+	}
+
+	return scopes
 }
 
 func GetRuleName(id uint16) string {
@@ -99,13 +308,13 @@ func FilterSuppressedIssues(issues []Issue, suppressions []Suppression) []Issue
 	filtered := issues[:0]
 
 	for _, issue := range issues {
-		ruleName := GetRuleName(issue.ID)
+		ruleName := IssueRuleName(issue)
 		if ruleName == "" {
 			filtered = append(filtered, issue)
 			continue
 		}
 
-		if !index.matches(ruleName, issue.LineNumber()) {
+		if !index.matches(ruleName, issue.LineNumber()) && !index.matches(AllRules, issue.LineNumber()) {
 			filtered = append(filtered, issue)
 		}
 	}
@@ -114,7 +323,6 @@ func FilterSuppressedIssues(issues []Issue, suppressions []Suppression) []Issue
 }
 
 func CheckUnusedSuppressions(path string, issues []Issue, suppressions []Suppression) []Issue {
-	issueIndex := buildIssueIndex(issues)
 	warnings := make([]Issue, 0, len(suppressions))
 
 	for _, sup := range suppressions {
@@ -129,9 +337,50 @@ func CheckUnusedSuppressions(path string, issues []Issue, suppressions []Suppres
 			continue
 		}
 
-		used := issueIndex.matches(sup.RuleName, sup.Line)
+		if sup.IsUnbalanced {
+			warnings = append(warnings, Issue{
+				Path:     path,
+				ID:       UnbalancedSuppressionRangeID,
+				Line:     uint32(sup.Line),
+				Severity: SeverityWarn,
+				ArgStr1:  sup.RuleName,
+			})
+			continue
+		}
+
+		if sup.IsExpired {
+			warnings = append(warnings, Issue{
+				Path:     path,
+				ID:       ExpiredSuppressionID,
+				Line:     uint32(sup.Line),
+				Severity: SeverityWarn,
+				ArgStr1:  PackContext2(sup.RuleName, sup.Until),
+			})
+			continue
+		}
+
+		if sup.IsInvalid {
+			warnings = append(warnings, Issue{
+				Path:     path,
+				ID:       InvalidSuppressionID,
+				Line:     uint32(sup.Line),
+				Severity: SeverityWarn,
+				ArgStr1:  PackContext2(sup.RuleName, sup.Until),
+			})
+			continue
+		}
 
-		if !used {
+		if sup.NeedsReason {
+			warnings = append(warnings, Issue{
+				Path:     path,
+				ID:       MissingSuppressionReasonID,
+				Line:     uint32(sup.Line),
+				Severity: SeverityWarn,
+				ArgStr1:  sup.RuleName,
+			})
+		}
+
+		if !sup.matchesAny(issues) {
 			line := sup.Line
 			if sup.IsFileWide {
 				line = 1
@@ -150,52 +399,60 @@ func CheckUnusedSuppressions(path string, issues []Issue, suppressions []Suppres
 	return warnings
 }
 
-type suppressionIndex map[string]suppressionLines
+func (s Suppression) covers(line int) bool {
+	if s.IsUnbalanced || s.IsMisplaced || s.IsExpired || s.IsInvalid {
+		return false
+	}
 
-type suppressionLines struct {
-	fileWide bool
-	inline   map[int]struct{}
+	return s.IsFileWide || (line >= s.Line && line <= s.EndLine)
 }
 
-func buildSuppressionIndex(suppressions []Suppression) suppressionIndex {
-	index := make(suppressionIndex, len(suppressions))
-
-	for _, sup := range suppressions {
-		entry := index[sup.RuleName]
-		if sup.IsFileWide {
-			entry.fileWide = true
-			index[sup.RuleName] = entry
+func (s Suppression) matchesAny(issues []Issue) bool {
+	for _, issue := range issues {
+		if !s.covers(issue.LineNumber()) {
 			continue
 		}
 
-		if entry.inline == nil {
-			entry.inline = make(map[int]struct{}, 2)
+		if s.RuleName == AllRules {
+			if IssueRuleName(issue) != "" {
+				return true
+			}
+
+			continue
 		}
 
-		entry.inline[sup.Line] = struct{}{}
-		entry.inline[sup.Line+1] = struct{}{}
-		index[sup.RuleName] = entry
+		if IssueRuleName(issue) == s.RuleName {
+			return true
+		}
 	}
 
-	return index
+	return false
 }
 
-func buildIssueIndex(issues []Issue) suppressionIndex {
-	index := make(suppressionIndex, len(issues))
+type suppressionIndex map[string]suppressionLines
 
-	for _, issue := range issues {
-		ruleName := GetRuleName(issue.ID)
-		if ruleName == "" {
+type suppressionLines struct {
+	fileWide bool
+	ranges   []Suppression
+}
+
+func buildSuppressionIndex(suppressions []Suppression) suppressionIndex {
+	index := make(suppressionIndex, len(suppressions))
+
+	for _, sup := range suppressions {
+		if sup.IsUnbalanced || sup.IsExpired || sup.IsInvalid {
 			continue
 		}
 
-		entry := index[ruleName]
-		if entry.inline == nil {
-			entry.inline = make(map[int]struct{}, 1)
+		entry := index[sup.RuleName]
+		if sup.IsFileWide {
+			entry.fileWide = true
+			index[sup.RuleName] = entry
+			continue
 		}
 
-		entry.inline[issue.LineNumber()] = struct{}{}
-		index[ruleName] = entry
+		entry.ranges = append(entry.ranges, sup)
+		index[sup.RuleName] = entry
 	}
 
 	return index
@@ -211,7 +468,11 @@ func (s suppressionIndex) matches(ruleName string, line int) bool {
 		return true
 	}
 
-	_, ok = entry.inline[line]
+	for _, sup := range entry.ranges {
+		if sup.covers(line) {
+			return true
+		}
+	}
 
-	return ok
+	return false
 }
@@ -0,0 +1,124 @@
+package rules
+
+import (
+	"regexp"
+	"strings"
+)
+
+// AllRules is the rule name recorded for directives that silence every rule
+// on their lines, such as a bare //nolint.
+const AllRules = "*"
+
+// CompatSuppressions parses suppression directives written for other linters.
+// Foreign linter names are translated through the alias table; names that do
+// not map to a Serenity rule belong to the other linter and are ignored.
+type CompatSuppressions struct {
+	aliases map[string][]string
+}
+
+var (
+	nolintRegex         = regexp.MustCompile(`^//nolint(?::([\w-]+(?:\s*,\s*[\w-]+)*))?(?:\s*//\s*(.*))?\s*$`)
+	lintIgnoreRegex     = regexp.MustCompile(`^//lint:ignore\s+([\w-]+(?:,[\w-]+)*)(?:\s+(.*))?$`)
+	lintFileIgnoreRegex = regexp.MustCompile(`^//lint:file-ignore\s+([\w-]+(?:,[\w-]+)*)(?:\s+(.*))?$`)
+)
+
+var defaultSuppressionAliases = map[string][]string{
+	"lll":       {"max-line-length"},
+	"funlen":    {"max-func-lines"},
+	"gocyclo":   {"cyclomatic-complexity"},
+	"cyclop":    {"cyclomatic-complexity"},
+	"nestif":    {"max-nesting-depth"},
+	"mnd":       {"no-magic-numbers"},
+	"gomnd":     {"no-magic-numbers"},
+	"nakedret":  {"no-bare-returns"},
+	"wrapcheck": {"error-not-wrapped"},
+	"depguard":  {"disallowed-packages"},
+	"unparam":   {"unused-params"},
+	"prealloc":  {"use-slice-capacity"},
+	"ST1001":    {"no-dot-imports"},
+	"ST1005":    {"error-string-format"},
+	"ST1016":    {"receiver-name"},
+}
+
+func NewCompatSuppressions(opts *SuppressionOptions) *CompatSuppressions {
+	if opts == nil || !opts.Use || opts.Compat == nil || !*opts.Compat {
+		return nil
+	}
+
+	aliases := make(map[string][]string, len(defaultSuppressionAliases)+len(opts.Aliases))
+
+	for name, targets := range defaultSuppressionAliases {
+		aliases[name] = targets
+	}
+
+	for name, targets := range opts.Aliases {
+		aliases[name] = targets
+	}
+
+	return &CompatSuppressions{aliases: aliases}
+}
+
+func (c *CompatSuppressions) parse(text string, line int) []Suppression {
+	if c == nil || !strings.HasPrefix(text, "//") {
+		return nil
+	}
+
+	if match := nolintRegex.FindStringSubmatch(text); match != nil {
+		if match[1] == "" {
+			return []Suppression{{RuleName: AllRules, Reason: match[2], Line: line, EndLine: line + 1}}
+		}
+
+		return c.expand(match[1], match[2], line, false)
+	}
+
+	if match := lintIgnoreRegex.FindStringSubmatch(text); match != nil {
+		return c.expand(match[1], match[2], line, false)
+	}
+
+	if match := lintFileIgnoreRegex.FindStringSubmatch(text); match != nil {
+		return c.expand(match[1], match[2], line, true)
+	}
+
+	return nil
+}
+
+func (c *CompatSuppressions) expand(list, reason string, line int, fileWide bool) []Suppression {
+	var suppressions []Suppression
+
+	endLine := line + 1
+	if fileWide {
+		endLine = line
+	}
+
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+
+		for _, ruleName := range c.resolve(name) {
+			suppressions = append(suppressions, Suppression{
+				RuleName:   ruleName,
+				Reason:     strings.TrimSpace(reason),
+				Line:       line,
+				EndLine:    endLine,
+				IsFileWide: fileWide,
+			})
+		}
+	}
+
+	return suppressions
+}
+
+func (c *CompatSuppressions) resolve(name string) []string {
+	if name == "all" {
+		return []string{AllRules}
+	}
+
+	if targets, ok := c.aliases[name]; ok {
+		return targets
+	}
+
+	if IsRuleName(name) {
+		return []string{name}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchGlob reports whether name, a slash-separated path, matches pattern.
+// Besides the syntax of path.Match, a "**" element matches any number of
+// directories. A pattern without a slash is matched against the base name.
+func MatchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(strings.TrimPrefix(name, "./"), "/"))
+}
+
+// ValidGlob reports whether pattern is well formed.
+func ValidGlob(pattern string) bool {
+	for _, elem := range strings.Split(pattern, "/") {
+		if _, err := path.Match(elem, ""); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
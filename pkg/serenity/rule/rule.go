@@ -0,0 +1,127 @@
+// Package rule is the public API for writing Serenity rules outside of this
+// repository.
+//
+// A rule inspects the AST node types it targets and reports issues through a
+// Runner. Rules reach the linter in one of two ways: compiled into a custom
+// binary built with `serenity build --with module@version`, where the module
+// calls Register from an init function, or shipped as a standalone executable
+// whose main function calls Serve. Either way they are enabled under the
+// `plugins` key of the Serenity config.
+package rule
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+type Rule interface {
+	Name() string
+	Targets() []ast.Node
+	Run(runner *Runner, node ast.Node)
+}
+
+type Issue struct {
+	Rule    string `json:"rule"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+type Runner struct {
+	File *ast.File
+	Fset *token.FileSet
+	Path string
+
+	rule   string
+	issues []Issue
+}
+
+func (r *Runner) Report(node ast.Node, message string) {
+	r.ReportAt(node.Pos(), message)
+}
+
+func (r *Runner) Reportf(node ast.Node, format string, args ...any) {
+	r.ReportAt(node.Pos(), fmt.Sprintf(format, args...))
+}
+
+func (r *Runner) ReportAt(pos token.Pos, message string) {
+	issue := Issue{Rule: r.rule, Message: message}
+
+	if r.Fset != nil && pos.IsValid() {
+		p := r.Fset.Position(pos)
+		issue.Line = p.Line
+		issue.Column = p.Column
+	}
+
+	r.issues = append(r.issues, issue)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Rule, 8)
+)
+
+// Register makes a rule available to the binary it is compiled into. It
+// panics when two rules share a name, like database/sql drivers do.
+func Register(r Rule) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	name := r.Name()
+	if _, exists := registry[name]; exists {
+		panic("rule: Register called twice for rule " + name)
+	}
+
+	registry[name] = r
+}
+
+// Registered returns the compiled-in rules sorted by name.
+func Registered() []Rule {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	out := make([]Rule, 0, len(registry))
+	for _, r := range registry {
+		out = append(out, r)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name() < out[j].Name()
+	})
+
+	return out
+}
+
+// Check runs rules over a parsed file and returns the issues they report,
+// in traversal order.
+func Check(fset *token.FileSet, file *ast.File, path string, rules []Rule) []Issue {
+	byType := make(map[reflect.Type][]Rule, len(rules))
+
+	for _, r := range rules {
+		for _, target := range r.Targets() {
+			t := reflect.TypeOf(target)
+			byType[t] = append(byType[t], r)
+		}
+	}
+
+	runner := &Runner{File: file, Fset: fset, Path: path}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		if node == nil {
+			return false
+		}
+
+		for _, r := range byType[reflect.TypeOf(node)] {
+			runner.rule = r.Name()
+			r.Run(runner, node)
+		}
+
+		return true
+	})
+
+	return runner.issues
+}
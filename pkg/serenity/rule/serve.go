@@ -0,0 +1,111 @@
+package rule
+
+import (
+	"bufio"
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+)
+
+// Methods understood by Serve. Each request and response is one JSON object
+// per line.
+const (
+	MethodDescribe = "describe"
+	MethodCheck    = "check"
+)
+
+type Request struct {
+	Method string   `json:"method"`
+	Path   string   `json:"path,omitempty"`
+	Src    []byte   `json:"src,omitempty"`
+	Rules  []string `json:"rules,omitempty"`
+}
+
+type Response struct {
+	Rules  []string `json:"rules,omitempty"`
+	Issues []Issue  `json:"issues,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// Serve answers linter requests on stdin and stdout until stdin is closed.
+// It is meant to be the whole body of a plugin's main function.
+func Serve(rules ...Rule) error {
+	return ServeIO(os.Stdin, os.Stdout, rules...)
+}
+
+func ServeIO(in io.Reader, out io.Writer, rules ...Rule) error {
+	names := make([]string, 0, len(rules))
+	byName := make(map[string]Rule, len(rules))
+
+	for _, r := range rules {
+		names = append(names, r.Name())
+		byName[r.Name()] = r
+	}
+
+	reader := bufio.NewReader(in)
+	writer := bufio.NewWriter(out)
+	enc := json.NewEncoder(writer)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+
+		var req Request
+		var resp Response
+
+		if jsonErr := json.Unmarshal(line, &req); jsonErr != nil {
+			resp.Error = "invalid request: " + jsonErr.Error()
+		} else {
+			resp = handle(req, names, byName)
+		}
+
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+func handle(req Request, names []string, byName map[string]Rule) Response {
+	switch req.Method {
+	case MethodDescribe:
+		return Response{Rules: names}
+
+	case MethodCheck:
+		selected := make([]Rule, 0, len(byName))
+
+		if len(req.Rules) == 0 {
+			for _, name := range names {
+				selected = append(selected, byName[name])
+			}
+		}
+
+		for _, name := range req.Rules {
+			if r, ok := byName[name]; ok {
+				selected = append(selected, r)
+			}
+		}
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, req.Path, req.Src, parser.ParseComments)
+		if err != nil {
+			return Response{Error: err.Error()}
+		}
+
+		return Response{Issues: Check(fset, file, req.Path, selected)}
+
+	default:
+		return Response{Error: "unknown method " + req.Method}
+	}
+}
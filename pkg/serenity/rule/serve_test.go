@@ -0,0 +1,71 @@
+package rule
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"strings"
+	"testing"
+)
+
+type panicCallRule struct{}
+
+func (panicCallRule) Name() string { return "no-panic" }
+
+func (panicCallRule) Targets() []ast.Node { return []ast.Node{(*ast.CallExpr)(nil)} }
+
+func (panicCallRule) Run(runner *Runner, node ast.Node) {
+	call := node.(*ast.CallExpr)
+	if ident, ok := call.Fun.(*ast.Ident); ok && ident.Name == "panic" {
+		runner.Report(call, "do not panic")
+	}
+}
+
+func TestServeIOAnswersDescribeAndCheck(t *testing.T) {
+	t.Parallel()
+
+	src := "package sample\n\nfunc f() {\n\tpanic(1)\n}\n"
+
+	var in bytes.Buffer
+	for _, req := range []Request{
+		{Method: MethodDescribe},
+		{Method: MethodCheck, Path: "sample.go", Src: []byte(src)},
+		{Method: "bogus"},
+	} {
+		data, _ := json.Marshal(req)
+		in.Write(append(data, '\n'))
+	}
+
+	var out bytes.Buffer
+	if err := ServeIO(&in, &out, panicCallRule{}); err != nil {
+		t.Fatalf("ServeIO failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 responses, got %d: %q", len(lines), out.String())
+	}
+
+	var describe, check, bogus Response
+	for i, dst := range []*Response{&describe, &check, &bogus} {
+		if err := json.Unmarshal([]byte(lines[i]), dst); err != nil {
+			t.Fatalf("response %d is not JSON: %v", i, err)
+		}
+	}
+
+	if len(describe.Rules) != 1 || describe.Rules[0] != "no-panic" {
+		t.Fatalf("unexpected describe response: %+v", describe)
+	}
+
+	if len(check.Issues) != 1 {
+		t.Fatalf("expected one issue, got %+v", check)
+	}
+
+	if got := check.Issues[0]; got.Rule != "no-panic" || got.Line != 4 || got.Column != 2 || got.Message != "do not panic" {
+		t.Fatalf("unexpected issue: %+v", got)
+	}
+
+	if bogus.Error == "" {
+		t.Fatal("expected an error for an unknown method")
+	}
+}